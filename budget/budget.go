@@ -0,0 +1,157 @@
+// Package budget enforces the limits a strategy declares in budget.yaml and
+// tracks what a case has consumed in a JSON sidecar.
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// Budget names, as reported in findings. They match the budget.yaml keys.
+const (
+	Workers       = "max_workers"
+	WorkerTimeout = "worker_timeout"
+	Iterations    = "max_iterations"
+	Commands      = "max_commands"
+)
+
+// Usage is what a case has consumed so far.
+type Usage struct {
+	Workers    int        `json:"workers"`
+	Iterations int        `json:"iterations"`
+	Commands   int        `json:"commands"`
+	WorkerMs   int64      `json:"worker_ms"`
+	Exceeded   []Exceeded `json:"exceeded,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Exceeded is a finding recorded when a budget is reached. It doubles as the
+// error returned to the caller that hit it.
+type Exceeded struct {
+	Budget  string    `json:"budget"`
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+}
+
+func (e *Exceeded) Error() string { return e.Message }
+
+// Tracker checks consumption against limits and persists it. Every change is
+// written through to the sidecar, so usage survives across dispatch cycles.
+type Tracker struct {
+	Usage
+	fs     dal.FileSystem
+	limits strategy.BudgetLimits
+	path   string
+}
+
+// Open loads the usage recorded at path, if any. An empty path tracks usage
+// in memory only.
+func Open(fs dal.FileSystem, limits strategy.BudgetLimits, path string) (*Tracker, error) {
+	t := &Tracker{fs: fs, limits: limits, path: path}
+	if path == "" || !fs.Exists(path) {
+		return t, nil
+	}
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read budget: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.Usage); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	return t, nil
+}
+
+// Reserve consumes n units of a counted budget before the work happens. When
+// that would go over the limit nothing is consumed and an *Exceeded is
+// returned.
+func (t *Tracker) Reserve(budget string, n int) error {
+	used, limit, err := t.counter(budget)
+	if err != nil {
+		return err
+	}
+	if limit > 0 && *used+n > limit {
+		return t.exceed(budget, fmt.Sprintf("%s budget exhausted: %d of %d used, %d more requested", budget, *used, limit, n))
+	}
+	*used += n
+	return t.save()
+}
+
+// Release returns n units of a counted budget reserved for work that did not
+// happen.
+func (t *Tracker) Release(budget string, n int) error {
+	used, _, err := t.counter(budget)
+	if err != nil {
+		return err
+	}
+	*used = max(*used-n, 0)
+	return t.save()
+}
+
+// Charge records n units of a counted budget that were already consumed. It
+// returns an *Exceeded when the total is now over the limit.
+func (t *Tracker) Charge(budget string, n int) error {
+	used, limit, err := t.counter(budget)
+	if err != nil {
+		return err
+	}
+	*used += n
+	if limit > 0 && *used > limit {
+		return t.exceed(budget, fmt.Sprintf("%s budget exceeded: %d used, limit %d", budget, *used, limit))
+	}
+	return t.save()
+}
+
+// AddTime records worker wall-clock time.
+func (t *Tracker) AddTime(d time.Duration) error {
+	t.WorkerMs += d.Milliseconds()
+	return t.save()
+}
+
+// TimedOut records that a worker was stopped at the worker_timeout limit.
+func (t *Tracker) TimedOut(worker string) error {
+	return t.exceed(WorkerTimeout, fmt.Sprintf("%s budget exceeded: worker %q ran longer than %s", WorkerTimeout, worker, t.limits.WorkerTimeout))
+}
+
+// counter returns the usage counter and limit of a counted budget.
+func (t *Tracker) counter(budget string) (*int, int, error) {
+	switch budget {
+	case Workers:
+		return &t.Workers, t.limits.MaxWorkers, nil
+	case Iterations:
+		return &t.Iterations, t.limits.MaxIterations, nil
+	case Commands:
+		return &t.Commands, t.limits.MaxCommands, nil
+	}
+	return nil, 0, fmt.Errorf("unknown budget %q", budget)
+}
+
+// exceed records a finding and returns it, or the error saving it.
+func (t *Tracker) exceed(budget, message string) error {
+	ex := &Exceeded{Budget: budget, Message: message, At: time.Now().UTC()}
+	t.Exceeded = append(t.Exceeded, *ex)
+	if err := t.save(); err != nil {
+		return err
+	}
+	return ex
+}
+
+// save writes the usage to the sidecar.
+func (t *Tracker) save() error {
+	t.UpdatedAt = time.Now().UTC()
+	if t.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.Usage, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := t.fs.EnsureDir(filepath.Dir(t.path)); err != nil {
+		return err
+	}
+	return t.fs.WriteFile(t.path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,73 @@
+package budget
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func TestTrackerReserveAndCharge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "case", strategy.DefaultBudgetSidecar)
+	limits := strategy.BudgetLimits{MaxWorkers: 2, MaxCommands: 3}
+
+	tr, err := Open(dal.NewFileSystem(), limits, path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := tr.Reserve(Workers, 2); err != nil {
+		t.Fatalf("Reserve within limit: %v", err)
+	}
+	var exceeded *Exceeded
+	if err := tr.Reserve(Workers, 1); !errors.As(err, &exceeded) || exceeded.Budget != Workers {
+		t.Fatalf("Reserve over limit = %v, want *Exceeded", err)
+	}
+	if tr.Workers != 2 {
+		t.Errorf("refused reservation was consumed: workers = %d", tr.Workers)
+	}
+	if err := tr.Release(Workers, 1); err != nil || tr.Workers != 1 {
+		t.Fatalf("Release = %v, workers = %d", err, tr.Workers)
+	}
+	if err := tr.Reserve(Workers, 1); err != nil {
+		t.Fatalf("Reserve after release: %v", err)
+	}
+
+	// Charge records work already done, even past the limit.
+	if err := tr.Charge(Commands, 4); !errors.As(err, &exceeded) || exceeded.Budget != Commands {
+		t.Fatalf("Charge over limit = %v, want *Exceeded", err)
+	}
+	if err := tr.Charge(Iterations, 5); err != nil {
+		t.Errorf("unlimited budget: %v", err)
+	}
+	if err := tr.AddTime(1500 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	// Usage persists across trackers.
+	again, err := Open(dal.NewFileSystem(), limits, path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if again.Workers != 2 || again.Commands != 4 || again.Iterations != 5 || again.WorkerMs != 1500 || len(again.Exceeded) != 2 {
+		t.Errorf("reopened usage = %+v", again.Usage)
+	}
+
+	if err := tr.Reserve("max_tokens", 1); err == nil || errors.As(err, &exceeded) {
+		t.Errorf("unknown budget error = %v", err)
+	}
+}
+
+func TestTrackerTimedOut(t *testing.T) {
+	tr, err := Open(dal.NewFileSystem(), strategy.BudgetLimits{WorkerTimeout: "10m"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tr.TimedOut("verify")
+	want := `worker_timeout budget exceeded: worker "verify" ran longer than 10m`
+	if err == nil || err.Error() != want {
+		t.Errorf("TimedOut = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/cobrax"
+	"github.com/gh-xj/agentops/resource"
+	caseresource "github.com/gh-xj/agentops/resource/case"
+	"github.com/spf13/cobra"
+)
+
+// addCaseCommands attaches case-specific commands to the generated case noun.
+func addCaseCommands(root *cobra.Command, cases *caseresource.CaseResource, ctx *agentops.AppContext) {
+	caseCmd := findSubcommand(root, "case")
+	if caseCmd == nil {
+		return
+	}
+	caseCmd.AddCommand(newCaseClaimCmd(cases, ctx))
+	caseCmd.AddCommand(newCaseReleaseCmd(cases, ctx))
+	caseCmd.AddCommand(newCaseHistoryCmd(cases, ctx))
+	caseCmd.AddCommand(newCaseAssessCmd(cases, ctx))
+	caseCmd.AddCommand(newCaseClassifyCmd(cases, ctx))
+	caseCmd.AddCommand(newCaseSectionCmd(cases, ctx))
+	caseCmd.AddCommand(newCaseSidecarCmd(cases, ctx))
+	caseCmd.AddCommand(newCaseMigrateLayoutCmd(cases, ctx))
+	caseCmd.AddCommand(newCaseSyncCmd(cases, ctx))
+}
+
+// findSubcommand returns the direct child of parent with the given name.
+func findSubcommand(parent *cobra.Command, name string) *cobra.Command {
+	for _, c := range parent.Commands() {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func newCaseClaimCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "claim <id>",
+		Short: "Claim a case for the current slot",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			setNote(cmd, ctx)
+			record, err := cases.Claim(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return renderCase(cmd, cases, record)
+		},
+	}
+	cmd.Flags().String("note", "", "note recorded in the case history")
+	return cmd
+}
+
+func newCaseReleaseCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release <id>",
+		Short: "Release the current slot's claim on a case",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			setNote(cmd, ctx)
+			record, err := cases.Release(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return renderCase(cmd, cases, record)
+		},
+	}
+	cmd.Flags().String("note", "", "note recorded in the case history")
+	return cmd
+}
+
+func newCaseHistoryCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "history <id>",
+		Short: "Show the event history of a case",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := cases.HistoryRecords(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			mode, fields, jqExpr := cobrax.ResolveOutputMode(cmd)
+			return cobrax.RenderRecords(cmd.OutOrStdout(), records, caseresource.EventSchema, mode, fields, jqExpr)
+		},
+	}
+}
+
+func newCaseAssessCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "assess <id>",
+		Short: "Score a case against risk.yaml and record its risk level",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, record, err := cases.AssessRisk(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			jsonFields, _ := cmd.Flags().GetString("json")
+			jqExpr, _ := cmd.Flags().GetString("jq")
+			w := cmd.OutOrStdout()
+			if jsonFields != "" || jqExpr != "" {
+				out, err := json.MarshalIndent(a, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(w, string(out))
+				return nil
+			}
+			fmt.Fprintf(w, "%s: risk %s (score %d)\n", record.ID, a.Level, a.Score)
+			for _, name := range a.Matched {
+				fmt.Fprintf(w, "  matched %s\n", name)
+			}
+			if a.Transition != "" {
+				fmt.Fprintf(w, "  escalated: %s -> %v\n", a.Transition, record.Fields["status"])
+			}
+			if len(a.Workers) > 0 {
+				fmt.Fprintf(w, "  requires workers: %s\n", strings.Join(a.Workers, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+func newCaseClassifyCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "classify <id>",
+		Short: "Classify a case with routing.yaml cues and explain the match",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			c, record, err := cases.Classify(ctx, args[0], !dryRun)
+			if err != nil {
+				return err
+			}
+			jsonFields, _ := cmd.Flags().GetString("json")
+			jqExpr, _ := cmd.Flags().GetString("jq")
+			w := cmd.OutOrStdout()
+			if jsonFields != "" || jqExpr != "" {
+				out, err := json.MarshalIndent(c, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(w, string(out))
+				return nil
+			}
+			fmt.Fprintf(w, "%s: %s\n", record.ID, c.Explain())
+			for _, m := range c.Matches {
+				if m.Type == c.Type {
+					continue
+				}
+				fmt.Fprintf(w, "  also matched %s: %s %q\n", m.Type, m.Cue, m.Pattern)
+			}
+			if dryRun && record.Fields["type"] != c.Type {
+				fmt.Fprintf(w, "\ndry-run: type stays %v\n", record.Fields["type"])
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("dry-run", false, "explain the classification without changing the case")
+	return cmd
+}
+
+// setNote passes the --note flag to the case resource through ctx.Values.
+func setNote(cmd *cobra.Command, ctx *agentops.AppContext) {
+	if note, _ := cmd.Flags().GetString("note"); note != "" {
+		ctx.Values["note"] = note
+	}
+}
+
+// renderCase renders a single case record honoring --json and --jq.
+func renderCase(cmd *cobra.Command, cases *caseresource.CaseResource, record *resource.Record) error {
+	mode, fields, jqExpr := cobrax.ResolveOutputMode(cmd)
+	return cobrax.RenderRecords(cmd.OutOrStdout(), []resource.Record{*record}, cases.Schema(), mode, fields, jqExpr)
+}
+
+func newCaseMigrateLayoutCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-layout",
+		Short: "Move flat cases/CASE-* directories into cases/<group>/<slot>/",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			confirm, _ := cmd.Flags().GetBool("confirm")
+			moves, err := cases.MigrateLayout(ctx, confirm)
+			if err != nil {
+				return err
+			}
+
+			jsonFields, _ := cmd.Flags().GetString("json")
+			jqExpr, _ := cmd.Flags().GetString("jq")
+			w := cmd.OutOrStdout()
+			if jsonFields != "" || jqExpr != "" {
+				out, err := json.MarshalIndent(moves, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(w, string(out))
+			} else if len(moves) == 0 {
+				fmt.Fprintln(w, "nothing to migrate")
+			} else {
+				for _, m := range moves {
+					switch {
+					case m.Error != "":
+						fmt.Fprintf(w, "skipped %s: %s\n", m.ID, m.Error)
+					case m.Moved:
+						fmt.Fprintf(w, "moved %s -> %s\n", m.ID, m.To)
+					default:
+						fmt.Fprintf(w, "would move %s -> %s\n", m.ID, m.To)
+					}
+				}
+				if !confirm {
+					fmt.Fprintln(w, "\ndry-run: pass --confirm to actually move")
+				}
+			}
+
+			if n := countFailedMoves(moves); n > 0 {
+				return fmt.Errorf("%d case(s) could not be migrated", n)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("confirm", false, "actually move (dry-run by default)")
+	return cmd
+}
+
+func newCaseSyncCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Pull, rebase and push the case repository",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remote, _ := cmd.Flags().GetString("remote")
+			report, err := cases.Sync(ctx, remote)
+			if err != nil {
+				return err
+			}
+
+			jsonFields, _ := cmd.Flags().GetString("json")
+			jqExpr, _ := cmd.Flags().GetString("jq")
+			w := cmd.OutOrStdout()
+			if jsonFields != "" || jqExpr != "" {
+				out, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(w, string(out))
+				return nil
+			}
+			for _, path := range report.Resolved {
+				fmt.Fprintf(w, "merged %s\n", path)
+			}
+			if report.Pushed {
+				fmt.Fprintf(w, "synced %s with %s\n", report.Branch, report.Remote)
+			} else {
+				fmt.Fprintf(w, "updated %s from %s\n", report.Branch, report.Remote)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("remote", "", "remote URL or path (default: remote in storage.yaml)")
+	return cmd
+}
+
+// countFailedMoves returns the number of moves that reported an error.
+func countFailedMoves(moves []caseresource.LayoutMove) int {
+	n := 0
+	for _, m := range moves {
+		if m.Error != "" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/dispatch"
+	"github.com/spf13/cobra"
+)
+
+func newDispatchCmd(engine *dispatch.Engine, ctx *agentops.AppContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "dispatch <case-id|slug>",
+		Short: "Drive a case through the dispatch lifecycle phases",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := engine.Run(ctx, args[0])
+			if report != nil {
+				out, jsonErr := json.MarshalIndent(report, "", "  ")
+				if jsonErr != nil {
+					return jsonErr
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			}
+			return err
+		},
+	}
+}
@@ -1,16 +1,18 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	agentops "github.com/gh-xj/agentops"
 	"github.com/gh-xj/agentops/resource"
+	"github.com/gh-xj/agentops/strategy"
 	"github.com/spf13/cobra"
 )
 
-func newDoctorCmd(reg *resource.Registry, ctx *agentops.AppContext) *cobra.Command {
+func newDoctorCmd(reg *resource.Registry, strat *strategy.Strategy, stratErr error, ctx *agentops.AppContext) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "doctor",
 		Short: "Validate strategy and resource health",
@@ -40,6 +42,21 @@ func newDoctorCmd(reg *resource.Registry, ctx *agentops.AppContext) *cobra.Comma
 				}
 			}
 
+			// Report each problem that kept the strategy from loading.
+			var loadErr *strategy.LoadError
+			if errors.As(stratErr, &loadErr) {
+				report.OK = false
+				report.Findings = append(report.Findings, loadErr.Findings...)
+			}
+
+			// Check the strategy's state machine.
+			if strat != nil {
+				if findings := strat.Validate(); len(findings) > 0 {
+					report.OK = false
+					report.Findings = append(report.Findings, findings...)
+				}
+			}
+
 			// Iterate resources and call Validate on those that support it.
 			for _, res := range reg.All() {
 				v, ok := res.(resource.Validator)
@@ -47,7 +64,10 @@ func newDoctorCmd(reg *resource.Registry, ctx *agentops.AppContext) *cobra.Comma
 					continue
 				}
 				schema := res.Schema()
-				records, err := res.List(ctx, nil)
+				records, err := res.List(ctx, resource.Filter{})
+				if err != nil && stratErr != nil && errors.Is(err, stratErr) {
+					continue // the strategy problem is reported above
+				}
 				if err != nil {
 					report.Findings = append(report.Findings, agentops.DoctorFinding{
 						Code:    "list_error",
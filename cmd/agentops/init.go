@@ -1,9 +1,17 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
+	agentops "github.com/gh-xj/agentops"
 	"github.com/gh-xj/agentops/dal"
+	slotresource "github.com/gh-xj/agentops/resource/slot"
 	"github.com/gh-xj/agentops/strategy"
 	"github.com/spf13/cobra"
 )
@@ -11,18 +19,145 @@ import (
 func newInitCmd(fs dal.FileSystem) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "init",
-		Short: "Bootstrap .agentops/ with default strategy files",
+		Short: "Bootstrap .agentops/ from a strategy preset",
+		Long: "Bootstrap .agentops/ from a strategy preset. Existing files are kept; " +
+			"--backend, --base-branch and --slots only shape the files init creates.",
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			dir, _ := cmd.Flags().GetString("dir")
 			if dir == "" {
 				dir = "."
 			}
-			if err := strategy.Bootstrap(dir); err != nil {
+			opts := strategy.BootstrapOptions{}
+			opts.Preset, _ = cmd.Flags().GetString("preset")
+			opts.Backend, _ = cmd.Flags().GetString("backend")
+			opts.BaseBranch, _ = cmd.Flags().GetString("base-branch")
+			opts.Slots, _ = cmd.Flags().GetStringSlice("slots")
+
+			if prompt, _ := cmd.Flags().GetBool("prompt"); prompt {
+				if !isTerminal(cmd.InOrStdin()) {
+					return agentops.NewCLIError(agentops.ExitUsage, "usage", "--prompt needs an interactive terminal", nil)
+				}
+				if err := promptInit(cmd.InOrStdin(), cmd.OutOrStdout(), &opts); err != nil {
+					return err
+				}
+			}
+
+			for _, slot := range opts.Slots {
+				if !slotresource.ValidName(slot) {
+					return agentops.NewCLIError(agentops.ExitUsage, "usage", fmt.Sprintf("invalid slot name %q: must match ^[a-z][a-z0-9-]*$", slot), nil)
+				}
+			}
+
+			result, err := strategy.BootstrapWith(dir, opts)
+			if err != nil {
+				return agentops.NewCLIError(agentops.ExitUsage, "usage", err.Error(), nil)
+			}
+
+			// Validate what init produced, including any files it kept.
+			var findings []agentops.DoctorFinding
+			strat, err := strategy.Discover(dir)
+			var loadErr *strategy.LoadError
+			switch {
+			case errors.As(err, &loadErr):
+				findings = loadErr.Findings
+			case err != nil:
 				return err
+			default:
+				findings = strat.Validate()
+			}
+
+			jsonFields, _ := cmd.Flags().GetString("json")
+			jqExpr, _ := cmd.Flags().GetString("jq")
+			w := cmd.OutOrStdout()
+			if jsonFields != "" || jqExpr != "" {
+				out, err := json.MarshalIndent(struct {
+					*strategy.BootstrapResult
+					Findings []agentops.DoctorFinding `json:"findings"`
+				}{result, findings}, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(w, string(out))
+			} else {
+				fmt.Fprintf(w, "Initialized .agentops/ in %s (preset %s)\n", dir, result.Preset)
+				for _, name := range result.Created {
+					fmt.Fprintf(w, "  created  %s\n", name)
+				}
+				for _, name := range result.Kept {
+					fmt.Fprintf(w, "  kept     %s\n", name)
+				}
+				for _, f := range findings {
+					fmt.Fprintf(w, "  [%s] %s: %s\n", f.Code, f.Path, f.Message)
+				}
+			}
+			if len(findings) > 0 {
+				return agentops.NewCLIError(agentops.ExitValidationFailed, "strategy_invalid", "the initialized strategy has problems; see `agentops doctor`", nil)
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "Initialized .agentops/ in %s\n", dir)
 			return nil
 		},
 	}
+	cmd.Flags().String("preset", strategy.DefaultPreset, "strategy preset: "+strings.Join(strategy.PresetNames(), ", "))
+	cmd.Flags().String("backend", "", "storage backend: "+strings.Join(strategy.StorageBackends, " or "))
+	cmd.Flags().String("base-branch", "", "base branch slots are created from")
+	cmd.Flags().StringSlice("slots", nil, "pre-declared slot names, comma-separated")
+	cmd.Flags().Bool("prompt", false, "ask for each answer interactively (requires a terminal)")
 	return cmd
 }
+
+// isTerminal reports whether r is an interactive terminal.
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// promptInit asks for each init answer, offering the flag value (or the
+// preset's) as the default. An empty reply, or end of input, keeps it.
+func promptInit(in io.Reader, out io.Writer, opts *strategy.BootstrapOptions) error {
+	r := bufio.NewReader(in)
+	ask := func(question, def string) (string, error) {
+		if def != "" {
+			fmt.Fprintf(out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(out, "%s: ", question)
+		}
+		line, err := r.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err // EOF accepts the remaining defaults
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+		return def, nil
+	}
+
+	fmt.Fprintln(out, "Presets:")
+	for _, p := range strategy.Presets {
+		fmt.Fprintf(out, "  %-20s %s\n", p.Name, p.Description)
+	}
+	var err error
+	if opts.Preset, err = ask("Preset", opts.Preset); err != nil {
+		return err
+	}
+	if opts.Backend, err = ask("Storage backend ("+strings.Join(strategy.StorageBackends, ", ")+"; empty for the preset's)", opts.Backend); err != nil {
+		return err
+	}
+	if opts.BaseBranch, err = ask("Base branch (empty for the preset's)", opts.BaseBranch); err != nil {
+		return err
+	}
+	slots, err := ask("Slot names, comma-separated (empty for the preset's)", strings.Join(opts.Slots, ","))
+	if err != nil {
+		return err
+	}
+	opts.Slots = nil
+	for _, s := range strings.Split(slots, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			opts.Slots = append(opts.Slots, s)
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	harnessloop "github.com/gh-xj/agentops/internal/harnessloop"
 	"github.com/gh-xj/agentops/internal/loopapi"
+	"github.com/gh-xj/agentops/strategy"
 	harness "github.com/gh-xj/agentops/tools/harness"
 	loopcommands "github.com/gh-xj/agentops/tools/harness/commands"
 )
@@ -81,9 +82,13 @@ func runLoopWithOptionalAPI(apiURL, action string, cfg harnessloop.Config) (harn
 			RoleConfig:       cfg.RoleConfigPath,
 			VerboseArtifacts: cfg.VerboseArtifacts,
 			Seed:             cfg.Seed,
-			Budget:           cfg.Budget,
 		})
 	}
+	if strat, err := strategy.Discover(cfg.RepoRoot); err == nil && strat.Budget.Configured() {
+		// Usage is scoped to the run and recorded in its artifact directory.
+		cfg.BudgetLimits = strat.Budget.Limits
+		cfg.BudgetSidecar = strat.Budget.Tracking.SidecarPath()
+	}
 	return harnessloop.RunLoop(cfg)
 }
 
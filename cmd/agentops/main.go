@@ -7,10 +7,12 @@ import (
 	agentops "github.com/gh-xj/agentops"
 	"github.com/gh-xj/agentops/cobrax"
 	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/dispatch"
 	"github.com/gh-xj/agentops/resource"
 	caseresource "github.com/gh-xj/agentops/resource/case"
 	projectresource "github.com/gh-xj/agentops/resource/project"
 	slotresource "github.com/gh-xj/agentops/resource/slot"
+	workerresource "github.com/gh-xj/agentops/resource/worker"
 	"github.com/gh-xj/agentops/strategy"
 )
 
@@ -27,12 +29,21 @@ func main() {
 	exec := dal.NewExecutor()
 
 	// Strategy loading is optional (commands like "new" don't need it).
-	strat, _ := strategy.Discover(".")
+	// Commands that do need it report stratErr.
+	strat, stratErr := strategy.Discover(".")
+
+	cases := caseresource.New(fs, exec, strat)
+	cases.SetLoadError(stratErr)
+	workers := workerresource.New(fs, exec, strat)
+	workers.SetLoadError(stratErr)
+	engine := dispatch.New(fs, exec, strat, cases)
+	engine.SetLoadError(stratErr)
 
 	reg := resource.NewRegistry()
-	reg.Register(caseresource.New(fs, exec, strat))
+	reg.Register(cases)
 	reg.Register(slotresource.New(fs, exec))
 	reg.Register(projectresource.New(fs, exec))
+	reg.Register(workers)
 
 	root := cobrax.BuildRoot(cobrax.RootSpec{
 		Use:   "agentops",
@@ -40,8 +51,12 @@ func main() {
 		Meta:  appMeta,
 	}, reg, ctx)
 
+	addCaseCommands(root, cases, ctx)
 	root.AddCommand(newInitCmd(fs))
-	root.AddCommand(newDoctorCmd(reg, ctx))
+	root.AddCommand(newNextCmd(cases, ctx))
+	root.AddCommand(newDoctorCmd(reg, strat, stratErr, ctx))
+	root.AddCommand(newStrategyCmd(strat, stratErr))
+	root.AddCommand(newDispatchCmd(engine, ctx))
 	root.AddCommand(newNewCmd(reg, ctx))
 	root.AddCommand(newVersionCmd())
 	root.AddCommand(newLoopCmd())
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	agentops "github.com/gh-xj/agentops"
+	caseresource "github.com/gh-xj/agentops/resource/case"
+	slotresource "github.com/gh-xj/agentops/resource/slot"
+	"github.com/spf13/cobra"
+)
+
+func newNextCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "next",
+		Short: "Claim the highest-ranked unclaimed case for the current slot (JSON output)",
+		Long: "Rank the unclaimed cases in the queue statuses by the queue.yaml weights " +
+			"(priority, risk, age) and claim the best one for the current slot. " +
+			"The output is always JSON; case is null when the queue is empty.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if slot, _ := cmd.Flags().GetString("slot"); slot != "" {
+				ctx.Values[slotresource.ContextKey] = slot
+			}
+			setNote(cmd, ctx)
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			result, err := cases.Next(ctx, !dryRun)
+			if err != nil {
+				return err
+			}
+			out, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			return nil
+		},
+	}
+	cmd.Flags().String("slot", "", "slot to claim for (default: the current worktree's slot)")
+	cmd.Flags().String("note", "", "note recorded in the case history")
+	cmd.Flags().Bool("dry-run", false, "rank the queue without claiming")
+	return cmd
+}
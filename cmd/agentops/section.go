@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/resource"
+	caseresource "github.com/gh-xj/agentops/resource/case"
+	"github.com/spf13/cobra"
+)
+
+func newCaseSectionCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "section",
+		Short: "Read and edit the ## sections of a case.md",
+	}
+	cmd.AddCommand(newCaseSectionGetCmd(cases, ctx))
+	cmd.AddCommand(newCaseSectionWriteCmd(cases, ctx, "set", "Replace the text of a case.md section", cases.SetSection))
+	cmd.AddCommand(newCaseSectionWriteCmd(cases, ctx, "append", "Add text to the end of a case.md section", cases.AppendSection))
+	return cmd
+}
+
+func newCaseSectionGetCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <id> <section>",
+		Short: "Print the text of a case.md section",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text, err := cases.GetSection(ctx, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			jsonFields, _ := cmd.Flags().GetString("json")
+			jqExpr, _ := cmd.Flags().GetString("jq")
+			w := cmd.OutOrStdout()
+			if jsonFields != "" || jqExpr != "" {
+				out, err := json.MarshalIndent(map[string]string{"id": args[0], "section": args[1], "text": text}, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(w, string(out))
+				return nil
+			}
+			if text != "" {
+				fmt.Fprintln(w, text)
+			}
+			return nil
+		},
+	}
+}
+
+// newCaseSectionWriteCmd builds `section set` and `section append`, which
+// differ only in the case resource method they call.
+func newCaseSectionWriteCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext, name, short string,
+	write func(*agentops.AppContext, string, string, string) (*resource.Record, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   name + " <id> <section> [text]",
+		Short: short,
+		Long: short + ". The text is read from stdin when omitted or \"-\"; put -- before text that starts with a dash. " +
+			"A missing section is added at the end of case.md.",
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text := "-"
+			if len(args) == 3 {
+				text = args[2]
+			}
+			if text == "-" {
+				data, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("read stdin: %w", err)
+				}
+				text = string(data)
+			}
+			setNote(cmd, ctx)
+			record, err := write(ctx, args[0], args[1], text)
+			if err != nil {
+				return err
+			}
+			return renderCase(cmd, cases, record)
+		},
+	}
+	cmd.Flags().String("note", "", "note recorded in the case history")
+	return cmd
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/cobrax"
+	"github.com/gh-xj/agentops/resource"
+	caseresource "github.com/gh-xj/agentops/resource/case"
+	"github.com/spf13/cobra"
+)
+
+func newCaseSidecarCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sidecar",
+		Short: "List, read and write the sidecar files in a case directory",
+	}
+	cmd.AddCommand(newCaseSidecarListCmd(cases, ctx))
+	cmd.AddCommand(newCaseSidecarReadCmd(cases, ctx))
+	cmd.AddCommand(newCaseSidecarWriteCmd(cases, ctx))
+	return cmd
+}
+
+func newCaseSidecarListCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list <id>",
+		Short: "List a case's sidecars and the worker or component that owns each",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sidecars, err := cases.Sidecars(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			records := make([]resource.Record, len(sidecars))
+			for i, s := range sidecars {
+				records[i] = resource.Record{
+					Kind:   "sidecar",
+					ID:     s.Path,
+					Fields: map[string]any{"path": s.Path, "owner": s.Owner},
+				}
+			}
+			mode, fields, jqExpr := cobrax.ResolveOutputMode(cmd)
+			return cobrax.RenderRecords(cmd.OutOrStdout(), records, caseresource.SidecarSchema, mode, fields, jqExpr)
+		},
+	}
+}
+
+func newCaseSidecarReadCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	return &cobra.Command{
+		Use:   "read <id> <path>",
+		Short: "Print a sidecar",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := cases.ReadSidecar(ctx, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+}
+
+func newCaseSidecarWriteCmd(cases *caseresource.CaseResource, ctx *agentops.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "write <id> <path>",
+		Short: "Write a sidecar from stdin, checking ownership and its worker's schema",
+		Long: "Write a sidecar from stdin. A worker's sidecar may only be written as that worker " +
+			"(--worker, default $AGENTOPS_WORKER), and must parse and match the worker's sidecar-schema.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			worker, _ := cmd.Flags().GetString("worker")
+			if worker == "" {
+				worker = os.Getenv("AGENTOPS_WORKER")
+			}
+			if worker != "" {
+				ctx.Values["worker"] = worker
+			}
+			setNote(cmd, ctx)
+			data, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("read stdin: %w", err)
+			}
+			s, err := cases.WriteSidecar(ctx, args[0], args[1], data)
+			if err != nil {
+				return err
+			}
+			jsonFields, _ := cmd.Flags().GetString("json")
+			jqExpr, _ := cmd.Flags().GetString("jq")
+			w := cmd.OutOrStdout()
+			if jsonFields != "" || jqExpr != "" {
+				out, err := json.MarshalIndent(s, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(w, string(out))
+				return nil
+			}
+			fmt.Fprintf(w, "wrote %s\n", s.Path)
+			return nil
+		},
+	}
+	cmd.Flags().String("worker", "", "worker writing the sidecar (default $AGENTOPS_WORKER)")
+	cmd.Flags().String("note", "", "note recorded in the case history")
+	return cmd
+}
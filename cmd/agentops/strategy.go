@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	agentops "github.com/gh-xj/agentops"
+	slotresource "github.com/gh-xj/agentops/resource/slot"
+	"github.com/gh-xj/agentops/strategy"
+	"github.com/spf13/cobra"
+)
+
+func newStrategyCmd(strat *strategy.Strategy, stratErr error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "strategy",
+		Short: "Inspect the project strategy",
+	}
+	cmd.AddCommand(newStrategyGraphCmd(strat, stratErr))
+	cmd.AddCommand(newStrategyShowCmd(strat, stratErr))
+	cmd.AddCommand(newStrategyUpgradeCmd(strat, stratErr))
+	cmd.AddCommand(newStrategySchemaCmd())
+	return cmd
+}
+
+func newStrategyUpgradeCmd(strat *strategy.Strategy, stratErr error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Migrate .agentops/ to the current schema_version and defaults",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strat == nil {
+				return strategy.Missing(stratErr)
+			}
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			report, err := strategy.Upgrade(strat.Root, dryRun)
+			if err != nil {
+				return agentops.NewCLIError(agentops.ExitValidationFailed, "upgrade_failed", "cannot upgrade strategy", err)
+			}
+
+			jsonFields, _ := cmd.Flags().GetString("json")
+			jqExpr, _ := cmd.Flags().GetString("jq")
+			w := cmd.OutOrStdout()
+			if jsonFields != "" || jqExpr != "" {
+				out, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(w, string(out))
+				return nil
+			}
+
+			suffix := ""
+			if dryRun {
+				suffix = " (dry run; nothing written)"
+			}
+			fmt.Fprintf(w, "schema_version %d -> %d%s\n", report.From, report.To, suffix)
+			for _, f := range report.Files {
+				note := ""
+				if f.Action == strategy.UpgradeConflict {
+					note = fmt.Sprintf(" (kept yours; new default in %s.new)", f.Name)
+				}
+				fmt.Fprintf(w, "  %-10s %s%s\n", f.Action, f.Name, note)
+			}
+			for _, m := range report.Migrations {
+				fmt.Fprintf(w, "migration %s\n", m)
+			}
+			for _, f := range report.Files {
+				if f.Yours != "" {
+					fmt.Fprintf(w, "\n%s", f.Yours)
+				}
+				if f.Defaults != "" {
+					fmt.Fprintf(w, "\n%s", f.Defaults)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("dry-run", false, "report the changes without writing them")
+	return cmd
+}
+
+func newStrategyShowCmd(strat *strategy.Strategy, stratErr error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the strategy layers, or with --resolved the merged strategy",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strat == nil {
+				return strategy.Missing(stratErr)
+			}
+			resolved, _ := cmd.Flags().GetBool("resolved")
+			jsonFields, _ := cmd.Flags().GetString("json")
+			jqExpr, _ := cmd.Flags().GetString("jq")
+			w := cmd.OutOrStdout()
+
+			if jsonFields != "" || jqExpr != "" {
+				r := &strategy.Resolved{Layers: strat.Layers, Slot: strat.Slot}
+				if resolved {
+					var err error
+					if r, err = strat.Resolve(); err != nil {
+						return err
+					}
+				}
+				out, err := json.MarshalIndent(r, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(w, string(out))
+				return nil
+			}
+			if resolved {
+				out, err := strat.RenderResolved()
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(w, out)
+				return nil
+			}
+			fmt.Fprintf(w, "root: %s\n", strat.Root)
+			if strat.Slot != "" {
+				fmt.Fprintf(w, "slot: %s\n", strat.Slot)
+			}
+			fmt.Fprintln(w, "layers (lowest first):")
+			for _, l := range strat.Layers {
+				fmt.Fprintf(w, "  %s\n", l)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("resolved", false, "print the merged strategy with the layer each value came from")
+	return cmd
+}
+
+func newStrategyGraphCmd(strat *strategy.Strategy, stratErr error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Render the case lifecycle as a DOT or Mermaid diagram",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strat == nil {
+				return strategy.Missing(stratErr)
+			}
+			format, _ := cmd.Flags().GetString("format")
+			out, err := strategy.RenderGraph(strat.Transitions, format)
+			if err != nil {
+				return agentops.NewCLIError(agentops.ExitUsage, "usage", err.Error(), nil)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+	cmd.Flags().String("format", strategy.GraphMermaid, "output format: dot or mermaid")
+	return cmd
+}
+
+// schemaTypes are the strategy files with a JSON Schema: the strategy
+// package's own plus slot.yaml, which the slot resource owns.
+func schemaTypes() map[string]any {
+	types := map[string]any{"slot.yaml": slotresource.SlotConfig{}}
+	for file, v := range strategy.SchemaTypes {
+		types[file] = v
+	}
+	return types
+}
+
+func newStrategySchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema [file]",
+		Short: "Print the JSON Schema of a strategy file, or write them all with --out",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			types := schemaTypes()
+			files := make([]string, 0, len(types))
+			for file := range types {
+				files = append(files, file)
+			}
+			sort.Strings(files)
+			w := cmd.OutOrStdout()
+
+			if outDir, _ := cmd.Flags().GetString("out"); outDir != "" {
+				if err := os.MkdirAll(outDir, 0o755); err != nil {
+					return err
+				}
+				for _, file := range files {
+					data, err := strategy.GenerateSchema(file, types[file])
+					if err != nil {
+						return err
+					}
+					path := filepath.Join(outDir, strategy.SchemaFileName(file))
+					if err := os.WriteFile(path, data, 0o644); err != nil {
+						return err
+					}
+					fmt.Fprintf(w, "wrote %s\n", path)
+				}
+				return nil
+			}
+
+			if len(args) == 0 {
+				for _, file := range files {
+					fmt.Fprintf(w, "%-18s %s\n", file, strategy.SchemaFileName(file))
+				}
+				return nil
+			}
+			v, ok := types[args[0]]
+			if !ok {
+				return agentops.NewCLIError(agentops.ExitUsage, "usage", fmt.Sprintf("no schema for %q (have %s)", args[0], strings.Join(files, ", ")), nil)
+			}
+			data, err := strategy.GenerateSchema(args[0], v)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(data)
+			return err
+		},
+	}
+	cmd.Flags().String("out", "", "write every schema into this directory (e.g. schemas/)")
+	return cmd
+}
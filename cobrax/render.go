@@ -215,6 +215,10 @@ func RenderPruneResults(w io.Writer, results []resource.PruneResult, jsonMode bo
 			fmt.Fprintf(w, "removed %s (%s)\n", r.Name, r.Path)
 		case "would_remove":
 			fmt.Fprintf(w, "would remove %s (%s)\n", r.Name, r.Path)
+		case "archived":
+			fmt.Fprintf(w, "archived %s (%s)\n", r.Name, r.Path)
+		case "would_archive":
+			fmt.Fprintf(w, "would archive %s: %s\n", r.Name, r.Reason)
 		case "skipped":
 			fmt.Fprintf(w, "skipped %s: %s\n", r.Name, r.Reason)
 		}
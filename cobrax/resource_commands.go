@@ -66,9 +66,9 @@ func GenerateResourceCommands(reg *resource.Registry, root *cobra.Command, ctx *
 	}
 }
 
-// resolveOutputMode reads --json and --jq flags from the command and returns
+// ResolveOutputMode reads --json and --jq flags from the command and returns
 // the appropriate output mode, field list, and jq expression.
-func resolveOutputMode(cmd *cobra.Command) (OutputMode, []string, string) {
+func ResolveOutputMode(cmd *cobra.Command) (OutputMode, []string, string) {
 	jsonFields, _ := cmd.Flags().GetString("json")
 	jqExpr, _ := cmd.Flags().GetString("jq")
 
@@ -99,48 +99,113 @@ func parseFieldList(s string) []string {
 }
 
 func makeCreateCmd(res resource.Resource, schema resource.ResourceSchema, ctx *agentops.AppContext) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "create <slug>",
 		Short: fmt.Sprintf("Create a new %s", schema.Kind),
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			record, err := res.Create(ctx, args[0], nil)
+			opts := createOpts(cmd, schema)
+			record, err := res.Create(ctx, args[0], opts)
 			if err != nil {
 				return err
 			}
-			mode, fields, jqExpr := resolveOutputMode(cmd)
+			mode, fields, jqExpr := ResolveOutputMode(cmd)
 			records := []resource.Record{*record}
 			return RenderRecords(cmd.OutOrStdout(), records, schema, mode, fields, jqExpr)
 		},
 	}
+	// Optional create arguments become flags, e.g. worker_type -> --worker-type.
+	for _, arg := range schema.CreateArgs {
+		if arg.Required {
+			continue
+		}
+		switch arg.Type {
+		case "bool":
+			cmd.Flags().Bool(argFlag(arg.Name), false, arg.Description)
+		case "list":
+			cmd.Flags().StringArray(argFlag(arg.Name), nil, arg.Description+" (repeatable)")
+		default:
+			cmd.Flags().String(argFlag(arg.Name), "", arg.Description)
+		}
+	}
+	return cmd
+}
+
+// createOpts collects the optional create arguments set on the command line.
+func createOpts(cmd *cobra.Command, schema resource.ResourceSchema) map[string]string {
+	opts := map[string]string{}
+	for _, arg := range schema.CreateArgs {
+		flag := cmd.Flags().Lookup(argFlag(arg.Name))
+		if arg.Required || flag == nil || !flag.Changed {
+			continue
+		}
+		if arg.Type == "list" {
+			values, _ := cmd.Flags().GetStringArray(argFlag(arg.Name))
+			opts[arg.Name] = strings.Join(values, "\n")
+			continue
+		}
+		opts[arg.Name] = flag.Value.String()
+	}
+	return opts
+}
+
+// argFlag returns the flag name of a create argument.
+func argFlag(name string) string {
+	return strings.ReplaceAll(name, "_", "-")
 }
 
 func makeListCmd(res resource.Resource, schema resource.ResourceSchema, ctx *agentops.AppContext) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: fmt.Sprintf("List %s resources", schema.Kind),
+		Long: fmt.Sprintf("List %s resources. Each --where predicate is <field><op><value> with op one of "+
+			"= != > >= < <= ~ (case-insensitive substring), e.g. type=pr, created>=20260101 or body~\"timeout\".", schema.Kind),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			filter := resource.Filter{}
-			if status, _ := cmd.Flags().GetString("status"); status != "" {
-				filter["status"] = status
-			}
-			if slot, _ := cmd.Flags().GetString("slot"); slot != "" {
-				filter["slot"] = slot
+			filter, err := listFilter(cmd)
+			if err != nil {
+				return agentops.NewCLIError(agentops.ExitUsage, "usage", err.Error(), nil)
 			}
 
 			records, err := res.List(ctx, filter)
 			if err != nil {
 				return err
 			}
-			mode, fields, jqExpr := resolveOutputMode(cmd)
+			mode, fields, jqExpr := ResolveOutputMode(cmd)
 			return RenderRecords(cmd.OutOrStdout(), records, schema, mode, fields, jqExpr)
 		},
 	}
 	cmd.Flags().String("status", "", "filter by status")
 	cmd.Flags().String("slot", "", "filter by slot")
+	cmd.Flags().StringArray("where", nil, "filter by a field predicate (repeatable; all must match)")
+	cmd.Flags().String("sort", "", "sort by comma-separated fields; prefix a field with - for descending")
+	cmd.Flags().Int("limit", 0, "return at most this many records (0 for all)")
 	return cmd
 }
 
+// listFilter builds a resource.Filter from the list command's flags.
+func listFilter(cmd *cobra.Command) (resource.Filter, error) {
+	var filter resource.Filter
+	filter.Status, _ = cmd.Flags().GetString("status")
+	filter.Slot, _ = cmd.Flags().GetString("slot")
+	where, _ := cmd.Flags().GetStringArray("where")
+	for _, expr := range where {
+		p, err := resource.ParsePredicate(expr)
+		if err != nil {
+			return filter, err
+		}
+		filter.Where = append(filter.Where, p)
+	}
+	sortSpec, _ := cmd.Flags().GetString("sort")
+	var err error
+	if filter.Sort, err = resource.ParseSort(sortSpec); err != nil {
+		return filter, err
+	}
+	if filter.Limit, _ = cmd.Flags().GetInt("limit"); filter.Limit < 0 {
+		return filter, fmt.Errorf("--limit must not be negative")
+	}
+	return filter, nil
+}
+
 func makeGetCmd(res resource.Resource, schema resource.ResourceSchema, ctx *agentops.AppContext) *cobra.Command {
 	return &cobra.Command{
 		Use:   "get <id>",
@@ -151,7 +216,7 @@ func makeGetCmd(res resource.Resource, schema resource.ResourceSchema, ctx *agen
 			if err != nil {
 				return err
 			}
-			mode, fields, jqExpr := resolveOutputMode(cmd)
+			mode, fields, jqExpr := ResolveOutputMode(cmd)
 			records := []resource.Record{*record}
 			return RenderRecords(cmd.OutOrStdout(), records, schema, mode, fields, jqExpr)
 		},
@@ -207,20 +272,31 @@ func makeSyncCmd(s resource.Syncer, schema resource.ResourceSchema, ctx *agentop
 }
 
 func makeTransitionCmd(tr resource.Transitioner, schema resource.ResourceSchema, ctx *agentops.AppContext) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "transition <id> <action>",
 		Short: fmt.Sprintf("Transition a %s to a new state", schema.Kind),
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// The note is passed through ctx.Values so Transitioner keeps its signature.
+			note, _ := cmd.Flags().GetString("note")
+			if note == "" {
+				note, _ = cmd.Flags().GetString("reason")
+			}
+			if note != "" {
+				ctx.Values["note"] = note
+			}
 			record, err := tr.Transition(ctx, args[0], args[1])
 			if err != nil {
 				return err
 			}
-			mode, fields, jqExpr := resolveOutputMode(cmd)
+			mode, fields, jqExpr := ResolveOutputMode(cmd)
 			records := []resource.Record{*record}
 			return RenderRecords(cmd.OutOrStdout(), records, schema, mode, fields, jqExpr)
 		},
 	}
+	cmd.Flags().String("note", "", "note recorded with the transition")
+	cmd.Flags().String("reason", "", "alias for --note")
+	return cmd
 }
 
 func makeDoctorCmd(doc resource.Doctor, schema resource.ResourceSchema, ctx *agentops.AppContext) *cobra.Command {
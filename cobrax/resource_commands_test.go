@@ -1,6 +1,7 @@
 package cobrax
 
 import (
+	"bytes"
 	"testing"
 
 	agentops "github.com/gh-xj/agentops"
@@ -306,3 +307,51 @@ func TestExecuteRootReturnsExitCode(t *testing.T) {
 		t.Fatalf("expected exit code %d for unknown command, got %d", agentops.ExitUsage, code)
 	}
 }
+
+// mockOptsResource records the opts passed to Create.
+type mockOptsResource struct {
+	mockResource
+	got map[string]string
+}
+
+func (m *mockOptsResource) Schema() resource.ResourceSchema {
+	schema := m.mockResource.Schema()
+	schema.CreateArgs = append(schema.CreateArgs,
+		resource.ArgDef{Name: "base_dir", Description: "parent directory"},
+		resource.ArgDef{Name: "classify", Description: "classify on create", Type: "bool"},
+		resource.ArgDef{Name: "mode", Description: "unset option"},
+		resource.ArgDef{Name: "set", Description: "key=value", Type: "list"},
+	)
+	return schema
+}
+
+func (m *mockOptsResource) Create(ctx *agentops.AppContext, slug string, opts map[string]string) (*resource.Record, error) {
+	m.got = opts
+	return m.mockResource.Create(ctx, slug, opts)
+}
+
+func TestCreateOptionalArgsAsFlags(t *testing.T) {
+	res := &mockOptsResource{}
+	reg := resource.NewRegistry()
+	reg.Register(res)
+
+	root := &cobra.Command{Use: "test"}
+	root.PersistentFlags().String("json", "", "JSON field selection")
+	root.PersistentFlags().String("jq", "", "jq expression")
+	GenerateResourceCommands(reg, root, agentops.NewAppContext(nil))
+
+	root.SetArgs([]string{"mock", "create", "x", "--base-dir", "/tmp/p", "--classify", "--set", "a=1", "--set", "b=x,y", "--json", "id"})
+	root.SetOut(new(bytes.Buffer))
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	want := map[string]string{"base_dir": "/tmp/p", "classify": "true", "set": "a=1\nb=x,y"}
+	if len(res.got) != len(want) {
+		t.Fatalf("opts = %v, want %v", res.got, want)
+	}
+	for k, v := range want {
+		if res.got[k] != v {
+			t.Errorf("opts[%s] = %q, want %q", k, res.got[k], v)
+		}
+	}
+}
@@ -41,8 +41,24 @@ func (f *FileSystemImpl) ReadDir(path string) ([]DirEntry, error) {
 	return result, nil
 }
 
+func (f *FileSystemImpl) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
 func (f *FileSystemImpl) BaseName(path string) string {
 	base := filepath.Base(path)
 	ext := filepath.Ext(base)
 	return strings.TrimSuffix(base, ext)
 }
+
+func (f *FileSystemImpl) CreateExclusive(path string, perm int) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, os.FileMode(perm))
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func (f *FileSystemImpl) Remove(path string) error {
+	return os.Remove(path)
+}
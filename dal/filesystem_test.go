@@ -1,6 +1,8 @@
 package dal
 
 import (
+	"errors"
+	iofs "io/fs"
 	"os"
 	"path/filepath"
 	"testing"
@@ -59,6 +61,24 @@ func TestFileSystemImpl_ReadWriteFile(t *testing.T) {
 	}
 }
 
+func TestFileSystemImpl_CreateExclusiveAndRemove(t *testing.T) {
+	fs := NewFileSystem()
+	path := filepath.Join(t.TempDir(), "x.lock")
+
+	if err := fs.CreateExclusive(path, 0600); err != nil {
+		t.Fatalf("CreateExclusive error: %v", err)
+	}
+	if err := fs.CreateExclusive(path, 0600); !errors.Is(err, iofs.ErrExist) {
+		t.Errorf("second CreateExclusive = %v, want ErrExist", err)
+	}
+	if err := fs.Remove(path); err != nil {
+		t.Fatalf("Remove error: %v", err)
+	}
+	if fs.Exists(path) {
+		t.Error("file still exists after Remove")
+	}
+}
+
 func TestFileSystemImpl_ReadDir(t *testing.T) {
 	fs := NewFileSystem()
 	dir := t.TempDir()
@@ -85,6 +105,25 @@ func TestFileSystemImpl_ReadDir(t *testing.T) {
 	}
 }
 
+func TestFileSystemImpl_Rename(t *testing.T) {
+	fs := NewFileSystem()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	os.Mkdir(src, 0755)
+	os.WriteFile(filepath.Join(src, "f.txt"), []byte("f"), 0644)
+
+	dst := filepath.Join(dir, "dst")
+	if err := fs.Rename(src, dst); err != nil {
+		t.Fatalf("Rename error: %v", err)
+	}
+	if fs.Exists(src) {
+		t.Error("source still exists after Rename")
+	}
+	if !fs.Exists(filepath.Join(dst, "f.txt")) {
+		t.Error("file not found under renamed directory")
+	}
+}
+
 func TestFileSystemImpl_BaseName(t *testing.T) {
 	fs := NewFileSystem()
 	tests := []struct {
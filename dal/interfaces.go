@@ -9,7 +9,12 @@ type FileSystem interface {
 	ReadFile(path string) ([]byte, error)
 	WriteFile(path string, data []byte, perm int) error
 	ReadDir(path string) ([]DirEntry, error)
+	Rename(oldPath, newPath string) error
 	BaseName(path string) string
+	// CreateExclusive creates an empty file, failing with an error matching
+	// fs.ErrExist when it already exists. It is the primitive for lock files.
+	CreateExclusive(path string, perm int) error
+	Remove(path string) error
 }
 
 // DirEntry is a minimal directory entry.
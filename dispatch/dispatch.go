@@ -0,0 +1,276 @@
+// Package dispatch drives a case through the lifecycle phases defined in
+// protocol/lifecycle.md using the loaded strategy.
+package dispatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/budget"
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/hooks"
+	caseresource "github.com/gh-xj/agentops/resource/case"
+	workerresource "github.com/gh-xj/agentops/resource/worker"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// Phase names, in execution order.
+const (
+	PhaseDetectSlot     = "detect-slot"
+	PhaseFindOrCreate   = "find-or-create"
+	PhaseClassify       = "classify"
+	PhaseAssessRisk     = "assess-risk"
+	PhaseSelectWorkers  = "select-workers"
+	PhaseExecuteWorkers = "execute-workers"
+	PhaseReconcile      = "reconcile"
+	PhaseFireHooks      = "fire-hooks"
+	PhaseCommit         = "commit"
+)
+
+// Phase result statuses.
+const (
+	StatusOK      = "ok"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// blockedStatus is the status a case is moved to when a phase fails.
+const blockedStatus = strategy.BlockedStatus
+
+// ReportFile is the name of the sidecar the dispatcher writes into the case directory.
+const ReportFile = caseresource.DispatchReportFile
+
+// Report is the structured result of one dispatch cycle.
+type Report struct {
+	SchemaVersion string        `json:"schema_version"`
+	CaseID        string        `json:"case_id"`
+	OK            bool          `json:"ok"`
+	Slot          string        `json:"slot,omitempty"`
+	Status        string        `json:"status,omitempty"`
+	StartedAt     time.Time     `json:"started_at"`
+	FinishedAt    time.Time     `json:"finished_at"`
+	Phases        []PhaseResult `json:"phases"`
+}
+
+// PhaseResult records the outcome of a single lifecycle phase.
+type PhaseResult struct {
+	Name       string         `json:"name"`
+	Status     string         `json:"status"` // ok, skipped, failed
+	Message    string         `json:"message,omitempty"`
+	DurationMs int64          `json:"duration_ms"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
+// Engine runs dispatch cycles against the case store.
+type Engine struct {
+	fs      dal.FileSystem
+	exec    dal.Executor
+	strat   *strategy.Strategy
+	cases   *caseresource.CaseResource
+	sm      *caseresource.StateMachine
+	hooks   *hooks.Runner
+	workers *workerresource.WorkerResource
+
+	loadErr error // why strat is nil, reported by Run
+}
+
+// New creates a dispatch Engine.
+func New(fs dal.FileSystem, exec dal.Executor, strat *strategy.Strategy, cases *caseresource.CaseResource) *Engine {
+	e := &Engine{
+		fs:      fs,
+		exec:    exec,
+		strat:   strat,
+		cases:   cases,
+		workers: workerresource.New(fs, exec, strat),
+	}
+	if strat != nil {
+		e.sm = caseresource.NewStateMachine(strat.Transitions)
+		e.hooks = hooks.NewRunner(fs, strat.Hooks, strat.Root)
+	}
+	return e
+}
+
+// SetLoadError records why the strategy could not be loaded; Run returns it
+// instead of a generic strategy_missing error.
+func (e *Engine) SetLoadError(err error) {
+	e.loadErr = err
+	e.workers.SetLoadError(err)
+}
+
+// phase pairs a phase name with its implementation.
+type phase struct {
+	name string
+	run  func(c *cycle) (PhaseResult, error)
+}
+
+// cycle carries state between phases of a single dispatch run.
+type cycle struct {
+	ctx      *agentops.AppContext
+	target   string
+	slot     string
+	caseID   string
+	caseDir  string
+	caseType string
+	status   string
+	risk     string
+	required []string // workers required by risk escalation
+	selected []string
+	results  []workerresource.Result
+	budget   *budget.Tracker // nil when budget.yaml sets no limits
+	report   *Report
+	reported bool // the report was written and committed by the commit phase
+}
+
+// event builds a hook event describing the case in its current state.
+func (c *cycle) event(hook string) hooks.Event {
+	return hooks.Event{
+		Hook:    hook,
+		CaseID:  c.caseID,
+		CaseDir: c.caseDir,
+		Type:    c.caseType,
+		Status:  c.status,
+		Slot:    c.slot,
+	}
+}
+
+func (e *Engine) phases() []phase {
+	return []phase{
+		{PhaseDetectSlot, e.detectSlot},
+		{PhaseFindOrCreate, e.findOrCreate},
+		{PhaseClassify, e.classify},
+		{PhaseAssessRisk, e.assessRisk},
+		{PhaseSelectWorkers, e.selectWorkers},
+		{PhaseExecuteWorkers, e.executeWorkers},
+		{PhaseReconcile, e.reconcile},
+		{PhaseFireHooks, e.fireHooks},
+		{PhaseCommit, e.commit},
+	}
+}
+
+// Run drives the case identified by target through every lifecycle phase.
+// target is a case ID, or a slug: the case created from it is reused, and
+// one is created when there is none.
+//
+// The returned report is always non-nil once the strategy is loaded. When a
+// phase fails, the remaining phases are reported as skipped, the case is moved
+// to blocked, and a typed error is returned alongside the report.
+func (e *Engine) Run(ctx *agentops.AppContext, target string) (*Report, error) {
+	return e.run(ctx, target, e.phases())
+}
+
+// run executes the given phases in order against target.
+func (e *Engine) run(ctx *agentops.AppContext, target string, phases []phase) (*Report, error) {
+	if e.strat == nil {
+		return nil, strategy.Missing(e.loadErr)
+	}
+
+	report := &Report{
+		SchemaVersion: "1.0",
+		CaseID:        target,
+		OK:            true,
+		StartedAt:     time.Now().UTC(),
+	}
+	c := &cycle{ctx: ctx, target: target, caseID: target, report: report}
+
+	var failure error
+	var note string
+	for _, p := range phases {
+		if failure != nil {
+			report.Phases = append(report.Phases, PhaseResult{
+				Name:    p.name,
+				Status:  StatusSkipped,
+				Message: "not run: an earlier phase failed",
+			})
+			continue
+		}
+
+		start := time.Now()
+		res, err := p.run(c)
+		res.Name = p.name
+		res.DurationMs = time.Since(start).Milliseconds()
+		if err != nil {
+			res.Status = StatusFailed
+			res.Message = err.Error()
+			failure = phaseError(p.name, err)
+			note = fmt.Sprintf("%s failed: %s", p.name, res.Message)
+			report.OK = false
+		} else if res.Status == "" {
+			res.Status = StatusOK
+		}
+		report.Phases = append(report.Phases, res)
+	}
+
+	if failure != nil {
+		if err := e.block(c, note); err != nil {
+			report.Phases = append(report.Phases, PhaseResult{
+				Name:    "block",
+				Status:  StatusFailed,
+				Message: err.Error(),
+			})
+		}
+	}
+
+	finish(c, report)
+	if c.reported {
+		return report, failure
+	}
+	// The commit phase did not run, so the report is committed on its own
+	// to leave the case repository clean.
+	err := e.writeReport(c, report)
+	if err == nil && c.caseDir != "" {
+		_, err = e.cases.Commit(ctx, c.caseID, "dispatch")
+	}
+	if err != nil && failure == nil {
+		return report, fmt.Errorf("record dispatch report: %w", err)
+	}
+	return report, failure
+}
+
+// finish fills in the report fields known once the cycle is over.
+func finish(c *cycle, report *Report) {
+	report.CaseID = c.caseID
+	report.Slot = c.slot
+	report.Status = c.status
+	report.FinishedAt = time.Now().UTC()
+}
+
+// block moves the case to the blocked status after a phase failure, noting
+// the failure in the case history.
+func (e *Engine) block(c *cycle, note string) error {
+	if c.caseDir == "" || c.status == blockedStatus {
+		return nil
+	}
+	action, ok := e.sm.ActionTo(c.status, blockedStatus)
+	if !ok {
+		return fmt.Errorf("no transition from %q to %q", c.status, blockedStatus)
+	}
+	rec, err := e.cases.Transition(caseresource.NoteContext(c.ctx, note), c.caseID, action)
+	if err != nil {
+		return fmt.Errorf("block case: %w", err)
+	}
+	c.status, _ = rec.Fields["status"].(string)
+	c.caseDir = filepath.Dir(rec.RawPath)
+	return nil
+}
+
+// writeReport records the dispatch report as a sidecar in the case directory.
+func (e *Engine) writeReport(c *cycle, report *Report) error {
+	if c.caseDir == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return e.fs.WriteFile(filepath.Join(c.caseDir, ReportFile), append(data, '\n'), 0o644)
+}
+
+// phaseError wraps a phase failure in a typed CLI error, preserving any exit
+// code already carried by the cause.
+func phaseError(name string, err error) error {
+	code := agentops.ResolveExitCode(err)
+	return agentops.NewCLIError(code, "dispatch_failed", fmt.Sprintf("phase %s failed", name), err)
+}
@@ -0,0 +1,610 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/budget"
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/hooks"
+	caseresource "github.com/gh-xj/agentops/resource/case"
+	slotresource "github.com/gh-xj/agentops/resource/slot"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// setupEngine bootstraps an in-repo strategy in a temp dir and returns an engine.
+func setupEngine(t *testing.T) (*Engine, *caseresource.CaseResource, *agentops.AppContext) {
+	t.Helper()
+	tmp := t.TempDir()
+	if err := strategy.Bootstrap(tmp); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, ".agentops", "storage.yaml"), []byte("backend: in-repo\n"), 0o644); err != nil {
+		t.Fatalf("write storage.yaml: %v", err)
+	}
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	fs := dal.NewFileSystem()
+	exec := dal.NewExecutor()
+	cases := caseresource.New(fs, exec, strat)
+	return New(fs, exec, strat, cases), cases, agentops.NewAppContext(context.Background())
+}
+
+func TestRunCreatesCaseAndReportsAllPhases(t *testing.T) {
+	engine, _, ctx := setupEngine(t)
+
+	report, err := engine.Run(ctx, "new-work")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !report.OK {
+		t.Fatalf("expected OK report, got %+v", report)
+	}
+
+	want := []string{
+		PhaseDetectSlot, PhaseFindOrCreate, PhaseClassify, PhaseAssessRisk,
+		PhaseSelectWorkers, PhaseExecuteWorkers, PhaseReconcile, PhaseFireHooks, PhaseCommit,
+	}
+	if len(report.Phases) != len(want) {
+		t.Fatalf("got %d phases, want %d", len(report.Phases), len(want))
+	}
+	for i, name := range want {
+		if report.Phases[i].Name != name {
+			t.Errorf("phase %d = %q, want %q", i, report.Phases[i].Name, name)
+		}
+		if report.Phases[i].Status == StatusFailed {
+			t.Errorf("phase %s failed: %s", name, report.Phases[i].Message)
+		}
+	}
+	if report.Status != "open" {
+		t.Errorf("status = %q, want open", report.Status)
+	}
+}
+
+func TestRunRecordsReportInCase(t *testing.T) {
+	engine, cases, ctx := setupEngine(t)
+
+	created, err := cases.Create(ctx, "recorded", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := engine.Run(ctx, created.ID); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(created.RawPath), ReportFile))
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	var got Report
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("parse report: %v", err)
+	}
+	if got.CaseID != created.ID {
+		t.Errorf("case_id = %q, want %q", got.CaseID, created.ID)
+	}
+}
+
+func TestRunCommitsReportToCaseRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := filepath.Join(t.TempDir(), "proj")
+	if err := strategy.Bootstrap(root); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".agentops", "storage.yaml"), []byte("backend: separate-repo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	strat, err := strategy.Discover(root)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	fs, ex := dal.NewFileSystem(), dal.NewExecutor()
+	engine := New(fs, ex, strat, caseresource.New(fs, ex, strat))
+	ctx := agentops.NewAppContext(context.Background())
+
+	report, err := engine.Run(ctx, "versioned")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if last := report.Phases[len(report.Phases)-1]; last.Name != PhaseCommit || last.Message != "committed to the case repository" {
+		t.Errorf("commit phase = %+v", last)
+	}
+	repo := root + "-cases"
+	if status, err := ex.RunInDir(repo, "git", "status", "--porcelain"); err != nil || strings.TrimSpace(status) != "" {
+		t.Errorf("case repo left dirty after dispatch: %q, %v", status, err)
+	}
+	if files, _ := ex.RunInDir(repo, "git", "show", "--name-only", "--format=", "HEAD"); !strings.Contains(files, ReportFile) {
+		t.Errorf("dispatch commit lacks the report:\n%s", files)
+	}
+}
+
+// setupSyncedEngine bootstraps a separate-repo project under parent that
+// syncs with ../remote.git, with a budget and a pre-dispatch hook so every
+// cycle writes the agentops sidecars.
+func setupSyncedEngine(t *testing.T, parent, name string) *Engine {
+	t.Helper()
+	root := filepath.Join(parent, name)
+	if err := strategy.Bootstrap(root); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	storage := "backend: separate-repo\nremote: ../remote.git\n"
+	if err := os.WriteFile(filepath.Join(root, ".agentops", "storage.yaml"), []byte(storage), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	strat, err := strategy.Discover(root)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	strat.Budget = strategy.BudgetConfig{Limits: strategy.BudgetLimits{MaxIterations: 10}}
+	strat.Hooks = strategy.HooksConfig{PreDispatch: []strategy.HookDef{{Shell: "true"}}}
+	fs, ex := dal.NewFileSystem(), dal.NewExecutor()
+	return New(fs, ex, strat, caseresource.New(fs, ex, strat))
+}
+
+func TestSyncMergesDispatchSidecars(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	parent := t.TempDir()
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", filepath.Join(parent, "remote.git")).CombinedOutput(); err != nil {
+		t.Skipf("git init --bare: %v: %s", err, out)
+	}
+	a := setupSyncedEngine(t, parent, "a")
+	b := setupSyncedEngine(t, parent, "b")
+	ctx := agentops.NewAppContext(context.Background())
+
+	first, err := a.Run(ctx, "shared")
+	if err != nil {
+		t.Fatalf("Run a: %v", err)
+	}
+	id := first.CaseID
+	if _, err := a.cases.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync a: %v", err)
+	}
+	if _, err := b.cases.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync b: %v", err)
+	}
+
+	// Both sides dispatch the case before syncing.
+	if _, err := a.Run(ctx, id); err != nil {
+		t.Fatalf("Run a: %v", err)
+	}
+	if _, err := a.cases.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync a: %v", err)
+	}
+	if _, err := b.Run(ctx, id); err != nil {
+		t.Fatalf("Run b: %v", err)
+	}
+	got, err := b.cases.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	dir := filepath.Dir(got.RawPath)
+	newest, err := os.ReadFile(filepath.Join(dir, ReportFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sync, err := b.cases.Sync(ctx, "")
+	if err != nil {
+		t.Fatalf("sync b with both sides dispatched: %v", err)
+	}
+	resolved := make(map[string]bool)
+	for _, p := range sync.Resolved {
+		resolved[filepath.Base(p)] = true
+	}
+	for _, name := range []string{caseresource.EventsFile, hooks.LogFile, ReportFile, strategy.DefaultBudgetSidecar} {
+		if !resolved[name] {
+			t.Errorf("%s not merged; resolved %v", name, sync.Resolved)
+		}
+	}
+
+	// Every cycle's hook runs and budget usage count; the newest report wins.
+	data, err := os.ReadFile(filepath.Join(dir, hooks.LogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 3 {
+		t.Errorf("hook log has %d entries, want 3:\n%s", n, data)
+	}
+	var usage budget.Usage
+	if data, err = os.ReadFile(filepath.Join(dir, strategy.DefaultBudgetSidecar)); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		t.Fatal(err)
+	}
+	if usage.Iterations != 3 || usage.Commands != 3 {
+		t.Errorf("usage = %+v, want 3 iterations and 3 commands", usage)
+	}
+	if data, err = os.ReadFile(filepath.Join(dir, ReportFile)); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(newest) {
+		t.Errorf("dispatch report = %s\nwant b's newer report %s", data, newest)
+	}
+}
+
+func TestRunRecordsCaseSlot(t *testing.T) {
+	engine, _, ctx := setupEngine(t)
+	ctx.Values["slot"] = "agent-1"
+
+	report, err := engine.Run(ctx, "slotted")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Slot != "agent-1" || report.Slot != slotresource.CurrentSlot(ctx, engine.fs, engine.strat.Root) {
+		t.Errorf("report slot = %q, want the slot case commands use", report.Slot)
+	}
+	if !strings.Contains(report.Phases[0].Message, "agent-1") {
+		t.Errorf("detect-slot = %+v", report.Phases[0])
+	}
+}
+
+func TestRunUnknownCaseIDFails(t *testing.T) {
+	engine, _, ctx := setupEngine(t)
+
+	report, err := engine.Run(ctx, "CASE-99999999-missing")
+	if err == nil {
+		t.Fatal("expected error for unknown case id")
+	}
+	if report == nil || report.OK {
+		t.Fatalf("expected failed report, got %+v", report)
+	}
+	if report.Phases[1].Status != StatusFailed {
+		t.Errorf("find-or-create status = %q, want failed", report.Phases[1].Status)
+	}
+	for _, p := range report.Phases[2:] {
+		if p.Status != StatusSkipped {
+			t.Errorf("phase %s status = %q, want skipped", p.Name, p.Status)
+		}
+	}
+}
+
+func TestRunFailedPhaseBlocksCase(t *testing.T) {
+	engine, cases, ctx := setupEngine(t)
+
+	created, err := cases.Create(ctx, "will-block", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	phases := engine.phases()[:2]
+	phases = append(phases, phase{name: "boom", run: func(c *cycle) (PhaseResult, error) {
+		return PhaseResult{}, agentops.NewCLIError(agentops.ExitWorkerFailed, "worker_failed", "worker exploded", nil)
+	}})
+
+	report, err := engine.run(ctx, created.ID, phases)
+	if err == nil {
+		t.Fatal("expected error from failing phase")
+	}
+	if code := agentops.ResolveExitCode(err); code != agentops.ExitWorkerFailed {
+		t.Errorf("exit code = %d, want %d", code, agentops.ExitWorkerFailed)
+	}
+	var cliErr *agentops.CLIError
+	if !errors.As(err, &cliErr) || cliErr.Kind != "dispatch_failed" {
+		t.Errorf("expected dispatch_failed CLIError, got %v", err)
+	}
+	if report.Status != "blocked" {
+		t.Errorf("report status = %q, want blocked", report.Status)
+	}
+
+	got, err := cases.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Fields["status"] != "blocked" {
+		t.Errorf("persisted status = %v, want blocked", got.Fields["status"])
+	}
+}
+
+func TestRunExecutesWorkers(t *testing.T) {
+	engine, _, ctx := setupEngine(t)
+
+	skillDir := filepath.Join(engine.strat.Root, ".claude", "skills", "verify")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	skill := "---\nworker-type: verify\nsidecar-path: verify.json\nblocking: true\ncommand: echo '{}' > verify.json\n---\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skill), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := engine.Run(ctx, "with-workers")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, p := range report.Phases {
+		if (p.Name == PhaseSelectWorkers || p.Name == PhaseExecuteWorkers) && p.Status != StatusOK {
+			t.Errorf("phase %s status = %q (%s), want ok", p.Name, p.Status, p.Message)
+		}
+	}
+}
+
+func TestRunAssessesRisk(t *testing.T) {
+	engine, cases, ctx := setupEngine(t)
+	engine.strat.Risk = strategy.RiskConfig{
+		Thresholds: map[string]int{"high": 1},
+		Rules:      []strategy.RiskRule{{Name: "intake", Score: 1, Match: strategy.RiskMatch{Types: []string{"intake"}}}},
+		Escalation: map[string]strategy.RiskEscalation{"high": {Workers: []string{"security-review"}}},
+	}
+
+	report, err := engine.Run(ctx, "risky-work")
+	if err == nil {
+		t.Fatal("expected select-workers to fail for an unregistered required worker")
+	}
+	for _, p := range report.Phases {
+		if p.Name == PhaseAssessRisk && p.Message != "risk high (score 1)" {
+			t.Errorf("assess-risk message = %q", p.Message)
+		}
+		if p.Name == PhaseSelectWorkers && p.Status != StatusFailed {
+			t.Errorf("select-workers status = %q, want failed", p.Status)
+		}
+	}
+
+	got, err := cases.Get(ctx, report.CaseID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Fields["risk"] != "high" {
+		t.Errorf("risk = %v, want high", got.Fields["risk"])
+	}
+}
+
+func TestRunClassifiesAndRoutesWorkers(t *testing.T) {
+	engine, cases, ctx := setupEngine(t)
+	for _, name := range []string{"review", "lint"} {
+		skillDir := filepath.Join(engine.strat.Root, ".claude", "skills", name)
+		if err := os.MkdirAll(skillDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		skill := "---\nworker-type: " + name + "\nsidecar-path: " + name + ".json\ncommand: echo '{}' > " + name + ".json\n---\n"
+		if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skill), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	engine.strat.Routing = strategy.RoutingConfig{
+		Cues:      map[string]strategy.RoutingCue{"pr": {Keywords: []string{"review"}}},
+		Overrides: map[string]map[string][]string{"pr": {strategy.OverrideAnyRisk: {"review"}}},
+	}
+
+	report, err := engine.Run(ctx, "review-login")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, p := range report.Phases {
+		switch p.Name {
+		case PhaseClassify:
+			if p.Status != StatusOK || p.Message != `pr: keyword "review"` {
+				t.Errorf("classify = %s (%s)", p.Status, p.Message)
+			}
+		case PhaseSelectWorkers:
+			if p.Message != "selected 1 worker(s) via overrides.pr.*" {
+				t.Errorf("select-workers message = %q", p.Message)
+			}
+		}
+	}
+
+	got, err := cases.Get(ctx, report.CaseID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Fields["type"] != "pr" {
+		t.Errorf("type = %v, want pr", got.Fields["type"])
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(got.RawPath), "review.json")); err != nil {
+		t.Errorf("routed worker review did not run: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(got.RawPath), "lint.json")); !os.IsNotExist(err) {
+		t.Errorf("unrouted worker lint ran")
+	}
+}
+
+func TestRunReusesCaseBySlug(t *testing.T) {
+	engine, _, ctx := setupEngine(t)
+
+	first, err := engine.Run(ctx, "newthing")
+	if err != nil {
+		t.Fatalf("first Run: %v", err)
+	}
+	second, err := engine.Run(ctx, "newthing")
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if second.CaseID != first.CaseID {
+		t.Errorf("re-dispatching the slug created %s, want %s reused", second.CaseID, first.CaseID)
+	}
+	if created := second.Phases[1].Data["created"]; created != false {
+		t.Errorf("second find-or-create created = %v", created)
+	}
+
+	// A slug cases were created from on two dates is ambiguous.
+	older := filepath.Join(engine.strat.Root, "cases", "CASE-20250101-newthing")
+	if err := os.MkdirAll(older, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(older, "case.md"), []byte("---\ntype: intake\nstatus: open\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = engine.Run(ctx, "newthing")
+	if code := agentops.ResolveExitCode(err); code != agentops.ExitUsage || !strings.Contains(err.Error(), "matches 2 cases") {
+		t.Errorf("ambiguous slug: exit code %d (%v), want %d", code, err, agentops.ExitUsage)
+	}
+}
+
+func TestRunRefusesCaseClaimedByAnotherSlot(t *testing.T) {
+	engine, cases, ctx := setupEngine(t)
+	rec, err := cases.Create(ctx, "theirs", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	other := agentops.NewAppContext(context.Background())
+	other.Values["slot"] = "other"
+	if _, err := cases.Claim(other, rec.ID); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	before, err := os.ReadFile(rec.RawPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := engine.Run(ctx, rec.ID)
+	if code := agentops.ResolveExitCode(err); code != agentops.ExitTransitionDenied {
+		t.Fatalf("Run exit code = %d (%v), want %d", code, err, agentops.ExitTransitionDenied)
+	}
+	if report.OK {
+		t.Errorf("report = %+v, want failed", report)
+	}
+	if after, _ := os.ReadFile(rec.RawPath); string(after) != string(before) {
+		t.Errorf("case.md changed:\n%s", after)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(rec.RawPath), ReportFile)); !os.IsNotExist(err) {
+		t.Errorf("dispatch report written for a case claimed by another slot")
+	}
+}
+
+func TestRunEnforcesBudget(t *testing.T) {
+	engine, cases, ctx := setupEngine(t)
+	skillDir := filepath.Join(engine.strat.Root, ".claude", "skills", "verify")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	skill := "---\nworker-type: verify\nsidecar-path: verify.json\ncommand: echo '{}' > verify.json\n---\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skill), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	engine.strat.Budget = strategy.BudgetConfig{Limits: strategy.BudgetLimits{MaxWorkers: 1}}
+
+	report, err := engine.Run(ctx, "budgeted")
+	if err != nil {
+		t.Fatalf("first Run: %v", err)
+	}
+	got, err := cases.Get(ctx, report.CaseID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Fields["status"] == "blocked" {
+		t.Fatalf("first cycle blocked the case")
+	}
+
+	report, err = engine.Run(ctx, report.CaseID)
+	if code := agentops.ResolveExitCode(err); code != agentops.ExitBudgetExceeded {
+		t.Fatalf("second Run exit code = %d (%v), want %d", code, err, agentops.ExitBudgetExceeded)
+	}
+	if report.Status != "blocked" {
+		t.Errorf("report status = %q, want blocked", report.Status)
+	}
+
+	var usage budget.Usage
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(got.RawPath), strategy.DefaultBudgetSidecar))
+	if err != nil {
+		t.Fatalf("read budget sidecar: %v", err)
+	}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		t.Fatal(err)
+	}
+	if usage.Iterations != 2 || usage.Workers != 1 || usage.Commands != 1 || len(usage.Exceeded) != 1 || usage.Exceeded[0].Budget != budget.Workers {
+		t.Errorf("usage = %+v", usage)
+	}
+
+	events, err := cases.History(ctx, report.CaseID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.To != "blocked" || !strings.Contains(last.Note, "max_workers budget exhausted") {
+		t.Errorf("block event = %+v", last)
+	}
+}
+
+func TestRunBudgetCountsPreDispatchHooks(t *testing.T) {
+	engine, cases, ctx := setupEngine(t)
+	skillDir := filepath.Join(engine.strat.Root, ".claude", "skills", "verify")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	skill := "---\nworker-type: verify\nsidecar-path: verify.json\ncommand: echo '{}' > verify.json\n---\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skill), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	engine.strat.Budget = strategy.BudgetConfig{Limits: strategy.BudgetLimits{MaxCommands: 1}}
+	engine.strat.Hooks = strategy.HooksConfig{PreDispatch: []strategy.HookDef{{Shell: "true"}}}
+	engine.hooks = hooks.NewRunner(engine.fs, engine.strat.Hooks, engine.strat.Root)
+
+	// The pre-dispatch hook uses the only command, so the worker is refused.
+	report, err := engine.Run(ctx, "hooked")
+	if code := agentops.ResolveExitCode(err); code != agentops.ExitBudgetExceeded {
+		t.Fatalf("Run exit code = %d (%v), want %d", code, err, agentops.ExitBudgetExceeded)
+	}
+	got, err := cases.Get(ctx, report.CaseID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	var usage budget.Usage
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(got.RawPath), strategy.DefaultBudgetSidecar))
+	if err != nil {
+		t.Fatalf("read budget sidecar: %v", err)
+	}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		t.Fatal(err)
+	}
+	// The refused worker's max_workers reservation is given back.
+	if usage.Commands != 1 || usage.Workers != 0 || len(usage.Exceeded) != 1 || usage.Exceeded[0].Budget != budget.Commands {
+		t.Errorf("usage = %+v", usage)
+	}
+}
+
+func TestRunReconcilesWorkerFindings(t *testing.T) {
+	engine, cases, ctx := setupEngine(t)
+	skillDir := filepath.Join(engine.strat.Root, ".claude", "skills", "review")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	output := `{"findings":[{"code":"sql-injection","message":"query built by concatenation","severity":"error"}],"recommend":"blocked"}`
+	skill := "---\nworker-type: review\nsidecar-path: review.json\ncommand: echo '" + output + "' > review.json\n---\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skill), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	engine.strat.Routing.Reconcile = strategy.ReconcileConfig{Rules: []strategy.ReconcileRule{
+		{Name: "clean", When: strategy.ReconcileMatch{Clean: true}, Transition: "resolve"},
+		{Name: "errors", When: strategy.ReconcileMatch{Severities: []string{"error"}}, Transition: "block"},
+	}}
+
+	report, err := engine.Run(ctx, "reconciled")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, p := range report.Phases {
+		if p.Name == PhaseReconcile && p.Message != "1 finding(s) from 1 sidecar(s): 1 added, 0 updated; rule errors applied block to blocked" {
+			t.Errorf("reconcile = %s (%s)", p.Status, p.Message)
+		}
+	}
+	if report.Status != "blocked" {
+		t.Errorf("report status = %q, want blocked", report.Status)
+	}
+
+	findings, err := cases.GetSection(ctx, report.CaseID, caseresource.FindingsSection)
+	if err != nil {
+		t.Fatalf("findings: %v", err)
+	}
+	if want := "- [error] sql-injection: query built by concatenation (source: review)"; findings != want {
+		t.Errorf("findings = %q, want %q", findings, want)
+	}
+	got, err := cases.Get(ctx, report.CaseID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(got.RawPath), caseresource.ReconcileReportFile)); err != nil {
+		t.Errorf("reconcile report: %v", err)
+	}
+}
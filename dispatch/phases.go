@@ -0,0 +1,390 @@
+package dispatch
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/budget"
+	"github.com/gh-xj/agentops/hooks"
+	caseresource "github.com/gh-xj/agentops/resource/case"
+	slotresource "github.com/gh-xj/agentops/resource/slot"
+	workerresource "github.com/gh-xj/agentops/resource/worker"
+	"github.com/gh-xj/agentops/routing"
+)
+
+// detectSlot finds the slot the cycle runs in, the same way case commands
+// do: from the context, or the marker of the slot worktree the strategy was
+// loaded from.
+func (e *Engine) detectSlot(c *cycle) (PhaseResult, error) {
+	c.slot = slotresource.CurrentSlot(c.ctx, e.fs, e.strat.Root)
+	if c.slot == "" {
+		return PhaseResult{Message: "no slot marker; dispatching from the project root"}, nil
+	}
+	return PhaseResult{
+		Message: fmt.Sprintf("slot %s", c.slot),
+		Data:    map[string]any{"slot": c.slot},
+	}, nil
+}
+
+// findOrCreate locates the target case, by ID or by the slug it was created
+// from, creating it when target is a new slug.
+func (e *Engine) findOrCreate(c *cycle) (PhaseResult, error) {
+	rec, err := e.cases.Get(c.ctx, c.target)
+	created := false
+	if err != nil {
+		if strings.HasPrefix(c.target, "CASE-") {
+			return PhaseResult{}, err
+		}
+		if rec, err = e.cases.FindBySlug(c.ctx, c.target); err != nil {
+			return PhaseResult{}, err
+		}
+	}
+	if rec == nil {
+		if rec, err = e.cases.Create(c.ctx, c.target, nil); err != nil {
+			return PhaseResult{}, err
+		}
+		created = true
+	}
+
+	c.caseID = rec.ID
+	// Only the owning slot may dispatch a claimed case (protocol/slot.md).
+	// caseDir stays unset on refusal, so nothing is written to the case.
+	claimedBy, _ := rec.Fields["claimed_by"].(string)
+	if err := caseresource.CheckClaim(rec.ID, claimedBy, c.slot); err != nil {
+		return PhaseResult{}, err
+	}
+	c.caseDir = filepath.Dir(rec.RawPath)
+	c.caseType, _ = rec.Fields["type"].(string)
+	c.status, _ = rec.Fields["status"].(string)
+	if err := e.openBudget(c); err != nil {
+		return PhaseResult{}, err
+	}
+
+	msg := "found " + c.caseID
+	if created {
+		msg = "created " + c.caseID
+	}
+	res := PhaseResult{
+		Message: msg,
+		Data:    map[string]any{"case_id": c.caseID, "created": created, "status": c.status},
+	}
+
+	outcomes, err := e.fire(c, c.event(hooks.PreDispatch))
+	if len(outcomes) > 0 {
+		res.Data["pre_dispatch_hooks"] = outcomes
+	}
+	return res, err
+}
+
+// classify determines the case type from routing cues. Only cases still of
+// the default type are classified; a type set explicitly is kept.
+func (e *Engine) classify(c *cycle) (PhaseResult, error) {
+	if len(e.strat.Routing.Cues) == 0 {
+		return PhaseResult{
+			Status:  StatusSkipped,
+			Message: fmt.Sprintf("no classifier configured; keeping type %q", c.caseType),
+		}, nil
+	}
+	if c.caseType != "" && c.caseType != e.strat.Routing.DefaultType() {
+		return PhaseResult{
+			Status:  StatusSkipped,
+			Message: fmt.Sprintf("type %q already set; not reclassifying", c.caseType),
+		}, nil
+	}
+	cl, rec, err := e.cases.Classify(c.ctx, c.caseID, true)
+	if err != nil {
+		return PhaseResult{}, err
+	}
+	c.caseType, _ = rec.Fields["type"].(string)
+	return PhaseResult{
+		Message: cl.Explain(),
+		Data:    map[string]any{"classification": cl},
+	}, nil
+}
+
+// assessRisk computes the case risk level from risk.yaml, records it in the
+// case frontmatter and applies any escalation.
+func (e *Engine) assessRisk(c *cycle) (PhaseResult, error) {
+	if !e.strat.Risk.Configured() {
+		return PhaseResult{Status: StatusSkipped, Message: "no risk rules configured"}, nil
+	}
+	a, rec, err := e.cases.AssessRisk(c.ctx, c.caseID)
+	if err != nil {
+		return PhaseResult{}, err
+	}
+	c.risk = a.Level
+	c.required = a.Workers
+	c.status, _ = rec.Fields["status"].(string)
+	c.caseDir = filepath.Dir(rec.RawPath)
+
+	msg := fmt.Sprintf("risk %s (score %d)", a.Level, a.Score)
+	if a.Transition != "" {
+		msg += fmt.Sprintf("; escalated with %s to %s", a.Transition, c.status)
+	}
+	return PhaseResult{
+		Message: msg,
+		Data:    map[string]any{"risk": a},
+	}, nil
+}
+
+// openBudget opens the case's budget tracker as soon as the case is known,
+// so every hook the cycle fires is counted. It does nothing when budget.yaml
+// sets no limits.
+func (e *Engine) openBudget(c *cycle) error {
+	if !e.strat.Budget.Configured() {
+		return nil
+	}
+	tracker, err := budget.Open(e.fs, e.strat.Budget.Limits, filepath.Join(c.caseDir, e.strat.Budget.Tracking.SidecarPath()))
+	if err != nil {
+		return err
+	}
+	c.budget = tracker
+	return nil
+}
+
+// selectWorkers maps (type, risk) to the workers to run using routing.yaml,
+// adding any workers risk escalation requires. Without routing rules every
+// registered worker is selected. When budget.yaml sets limits, the cycle is
+// counted against max_iterations first.
+func (e *Engine) selectWorkers(c *cycle) (PhaseResult, error) {
+	if c.budget != nil {
+		if err := c.budget.Reserve(budget.Iterations, 1); err != nil {
+			return PhaseResult{}, budgetError(err)
+		}
+	}
+	reg, err := e.workers.Registry()
+	if err != nil {
+		return PhaseResult{}, err
+	}
+	routed, source := routing.Workers(e.strat.Routing, c.caseType, c.risk)
+	if source == "" {
+		for _, w := range reg.All() {
+			routed = append(routed, w.Name)
+		}
+	}
+
+	seen := make(map[string]bool)
+	add := func(name, why string) error {
+		if seen[name] {
+			return nil
+		}
+		if _, ok := reg.Get(name); !ok {
+			return agentops.NewCLIError(agentops.ExitWorkerFailed, "worker_missing",
+				fmt.Sprintf("%s requires worker %q, which is not registered", why, name), nil)
+		}
+		seen[name] = true
+		c.selected = append(c.selected, name)
+		return nil
+	}
+	for _, name := range routed {
+		if err := add(name, "routing "+source); err != nil {
+			return PhaseResult{}, err
+		}
+	}
+	for _, name := range c.required {
+		if err := add(name, "risk "+c.risk); err != nil {
+			return PhaseResult{}, err
+		}
+	}
+
+	if len(c.selected) == 0 {
+		msg := "no workers registered"
+		if source != "" {
+			msg = fmt.Sprintf("no workers routed by %s", source)
+		}
+		return PhaseResult{Status: StatusSkipped, Message: msg}, nil
+	}
+	msg := fmt.Sprintf("selected %d worker(s)", len(c.selected))
+	if source != "" {
+		msg += " via " + source
+	}
+	return PhaseResult{
+		Message: msg,
+		Data:    map[string]any{"workers": c.selected},
+	}, nil
+}
+
+// executeWorkers runs the selected workers in dependency order, collects their
+// sidecars and fires on-worker-complete for each worker that ran. The phase
+// fails when a blocking worker does not complete.
+func (e *Engine) executeWorkers(c *cycle) (PhaseResult, error) {
+	if len(c.selected) == 0 {
+		return PhaseResult{Status: StatusSkipped, Message: "no workers selected"}, nil
+	}
+	executor, err := e.workers.Executor()
+	if err != nil {
+		return PhaseResult{}, err
+	}
+	if c.budget != nil {
+		if executor.Timeout, err = e.strat.Budget.Limits.Timeout(); err != nil {
+			return PhaseResult{}, agentops.NewCLIError(agentops.ExitValidationFailed, "invalid_budget", "budget.yaml", err)
+		}
+		executor.Admit = func(workerresource.Worker) error {
+			if err := c.budget.Reserve(budget.Workers, 1); err != nil {
+				return err
+			}
+			if err := c.budget.Reserve(budget.Commands, 1); err != nil {
+				// The worker is not run, so it does not count against max_workers.
+				if relErr := c.budget.Release(budget.Workers, 1); relErr != nil {
+					return relErr
+				}
+				return err
+			}
+			return nil
+		}
+	}
+	c.results, err = executor.Run(c.caseDir, c.selected)
+	res := PhaseResult{Data: map[string]any{"results": c.results}}
+	if err != nil {
+		return res, budgetError(err)
+	}
+	if err := e.chargeTime(c); err != nil {
+		return res, err
+	}
+
+	var failed []string
+	for _, r := range c.results {
+		if !r.Skipped {
+			ev := c.event(hooks.OnWorkerComplete)
+			ev.Worker = r.Worker
+			if _, err := e.fire(c, ev); err != nil {
+				return res, err
+			}
+		}
+		if !r.OK && r.Blocking {
+			failed = append(failed, r.Worker)
+		}
+	}
+	if len(failed) > 0 {
+		return res, agentops.NewCLIError(agentops.ExitWorkerFailed, "worker_failed",
+			fmt.Sprintf("blocking worker(s) did not complete: %s", strings.Join(failed, ", ")), nil)
+	}
+	res.Message = fmt.Sprintf("ran %d worker(s)", len(c.results))
+	return res, nil
+}
+
+// chargeTime records the wall-clock time of the cycle's workers and fails
+// the cycle if one hit the worker_timeout budget.
+func (e *Engine) chargeTime(c *cycle) error {
+	if c.budget == nil {
+		return nil
+	}
+	var spent time.Duration
+	for _, r := range c.results {
+		spent += time.Duration(r.DurationMs) * time.Millisecond
+	}
+	if err := c.budget.AddTime(spent); err != nil {
+		return err
+	}
+	for _, r := range c.results {
+		if r.TimedOut {
+			return budgetError(c.budget.TimedOut(r.Worker))
+		}
+	}
+	return nil
+}
+
+// budgetError turns a budget finding into a typed CLI error.
+func budgetError(err error) error {
+	var exceeded *budget.Exceeded
+	if errors.As(err, &exceeded) {
+		return agentops.NewCLIError(agentops.ExitBudgetExceeded, "budget_exceeded", exceeded.Message, nil)
+	}
+	return err
+}
+
+// reconcile merges the findings and recommendations in the case's worker
+// sidecars into case.md and applies the transition the reconcile rules in
+// routing.yaml choose.
+func (e *Engine) reconcile(c *cycle) (PhaseResult, error) {
+	rep, rec, err := e.cases.Reconcile(c.ctx, c.caseID)
+	if err != nil {
+		return PhaseResult{}, err
+	}
+	if rec == nil {
+		return PhaseResult{Status: StatusSkipped, Message: "no worker outputs to reconcile"}, nil
+	}
+	c.status, _ = rec.Fields["status"].(string)
+	c.caseDir = filepath.Dir(rec.RawPath)
+
+	msg := fmt.Sprintf("%d finding(s) from %d sidecar(s): %d added, %d updated",
+		len(rep.Findings), len(rep.Sources), rep.Added, rep.Updated)
+	if rep.Transition != "" {
+		msg += fmt.Sprintf("; rule %s applied %s to %s", rep.Rule, rep.Transition, c.status)
+	}
+	return PhaseResult{
+		Message: msg,
+		Data:    map[string]any{"reconcile": rep},
+	}, nil
+}
+
+// fireHooks runs the on-reconcile-done hooks bound by the strategy.
+func (e *Engine) fireHooks(c *cycle) (PhaseResult, error) {
+	if !e.hooks.Has(hooks.OnReconcileDone) {
+		return PhaseResult{Status: StatusSkipped, Message: "no on-reconcile-done hooks bound"}, nil
+	}
+	outcomes, err := e.fire(c, c.event(hooks.OnReconcileDone))
+	failed := 0
+	for _, o := range outcomes {
+		if !o.OK {
+			failed++
+		}
+	}
+	return PhaseResult{
+		Message: fmt.Sprintf("ran %d hook(s), %d failed", len(outcomes), failed),
+		Data:    map[string]any{"hooks": outcomes},
+	}, err
+}
+
+// commit records the cycle's changes to the case, such as worker sidecars,
+// hook history and the dispatch report, as one dispatcher-owned commit in the
+// case repository. The report is written first, already listing this phase,
+// so that it is part of the commit.
+func (e *Engine) commit(c *cycle) (PhaseResult, error) {
+	if !e.cases.Versioned() {
+		return PhaseResult{Status: StatusSkipped, Message: fmt.Sprintf("%s backend: cases are not committed by agentops", e.backend())}, nil
+	}
+	res := PhaseResult{Name: PhaseCommit, Status: StatusOK, Message: "committed to the case repository"}
+	snapshot := *c.report
+	snapshot.Phases = append(append([]PhaseResult(nil), c.report.Phases...), res)
+	finish(c, &snapshot)
+	if err := e.writeReport(c, &snapshot); err != nil {
+		return PhaseResult{}, fmt.Errorf("record dispatch report: %w", err)
+	}
+	committed, err := e.cases.Commit(c.ctx, c.caseID, "dispatch")
+	if err != nil {
+		return PhaseResult{}, err
+	}
+	c.reported = true
+	if !committed {
+		res.Message = "nothing to commit"
+	}
+	return res, nil
+}
+
+// fire runs the hooks bound to ev.Hook and records their outcomes in the case
+// history. Each hook run counts against max_commands.
+func (e *Engine) fire(c *cycle, ev hooks.Event) ([]hooks.Outcome, error) {
+	outcomes, err := e.hooks.Fire(ev)
+	if recErr := e.cases.RecordHooks(c.ctx, c.caseDir, outcomes); recErr != nil && err == nil {
+		err = recErr
+	}
+	if c.budget != nil && len(outcomes) > 0 {
+		if budgetErr := c.budget.Charge(budget.Commands, len(outcomes)); budgetErr != nil && err == nil {
+			err = budgetError(budgetErr)
+		}
+	}
+	return outcomes, err
+}
+
+// backend returns the configured storage backend name.
+func (e *Engine) backend() string {
+	if e.strat.Storage.Backend == "" {
+		return "separate-repo"
+	}
+	return e.strat.Storage.Backend
+}
@@ -16,6 +16,7 @@ const (
 	ExitTransitionDenied = 11 // invalid state transition
 	ExitWorkerFailed     = 12 // worker returned error
 	ExitValidationFailed = 13 // case/strategy validation failed
+	ExitBudgetExceeded   = 14 // budget.yaml limit reached
 )
 
 // ExitCoder describes errors that can provide a process exit code.
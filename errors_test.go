@@ -40,6 +40,7 @@ func TestAgentopsExitCodes(t *testing.T) {
 		{"TransitionDenied", ExitTransitionDenied, 11},
 		{"WorkerFailed", ExitWorkerFailed, 12},
 		{"ValidationFailed", ExitValidationFailed, 13},
+		{"BudgetExceeded", ExitBudgetExceeded, 14},
 	}
 	for _, tc := range codes {
 		t.Run(tc.name, func(t *testing.T) {
@@ -0,0 +1,244 @@
+// Package hooks executes the lifecycle hooks a strategy binds in hooks.yaml.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/dal"
+	workerresource "github.com/gh-xj/agentops/resource/worker"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// Hook points from protocol/hooks.md.
+const (
+	PreDispatch      = "pre-dispatch"
+	OnCaseOpen       = "on-case-open"
+	OnCaseTransition = "on-case-transition"
+	OnWorkerComplete = "on-worker-complete"
+	OnReconcileDone  = "on-reconcile-done"
+	OnCaseClose      = "on-case-close"
+)
+
+// LogFile is the per-case sidecar every hook outcome is appended to.
+const LogFile = "hooks.jsonl"
+
+// maxOutput bounds the captured hook output kept in the log.
+const maxOutput = 800
+
+// DefaultTimeout stops a hook that runs longer, so a hung hook cannot stall
+// the operation that fired it.
+const DefaultTimeout = 10 * time.Minute
+
+// Event describes the case state a hook fires for. It is passed to hooks as
+// JSON on stdin and flattened into AGENTOPS_* environment variables.
+type Event struct {
+	Hook       string `json:"hook"`
+	CaseID     string `json:"case_id"`
+	CaseDir    string `json:"case_dir"`
+	Type       string `json:"type,omitempty"`
+	Status     string `json:"status,omitempty"`
+	FromStatus string `json:"from_status,omitempty"`
+	ToStatus   string `json:"to_status,omitempty"`
+	Action     string `json:"action,omitempty"`
+	Slot       string `json:"slot,omitempty"`
+	Worker     string `json:"worker,omitempty"`
+}
+
+// Outcome records the result of running one hook.
+type Outcome struct {
+	Hook       string    `json:"hook"`
+	Kind       string    `json:"kind"`
+	Command    string    `json:"command"`
+	Blocking   bool      `json:"blocking"`
+	OK         bool      `json:"ok"`
+	ExitCode   int       `json:"exit_code"`
+	DurationMs int64     `json:"duration_ms"`
+	Output     string    `json:"output,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// Runner fires hooks bound in a HooksConfig.
+type Runner struct {
+	fs   dal.FileSystem
+	cfg  strategy.HooksConfig
+	root string
+	self string
+
+	// Timeout stops a hook that runs longer; zero means no limit.
+	Timeout time.Duration
+}
+
+// NewRunner creates a Runner for hooks rooted at the project directory root.
+// Hooks are stopped after DefaultTimeout.
+func NewRunner(fs dal.FileSystem, cfg strategy.HooksConfig, root string) *Runner {
+	self, _ := os.Executable()
+	return &Runner{fs: fs, cfg: cfg, root: root, self: self, Timeout: DefaultTimeout}
+}
+
+// Has reports whether any hooks are bound to the hook point.
+func (r *Runner) Has(point string) bool {
+	return r != nil && len(r.cfg.For(point)) > 0
+}
+
+// Fire runs every hook bound to ev.Hook in declaration order and appends each
+// outcome to the case's hook log. Non-blocking failures are logged and
+// skipped; the first blocking failure stops the run and is returned as a
+// typed error.
+func (r *Runner) Fire(ev Event) ([]Outcome, error) {
+	if r == nil {
+		return nil, nil
+	}
+	var outcomes []Outcome
+	for _, def := range r.cfg.For(ev.Hook) {
+		out := r.run(def, ev)
+		outcomes = append(outcomes, out)
+		if err := r.log(ev.CaseDir, out); err != nil {
+			return outcomes, fmt.Errorf("log hook outcome: %w", err)
+		}
+		if !out.OK && out.Blocking {
+			msg := fmt.Sprintf("blocking %s hook %q failed", ev.Hook, out.Command)
+			var cause error
+			if out.Error != "" {
+				cause = fmt.Errorf("%s", out.Error)
+			}
+			return outcomes, agentops.NewCLIError(agentops.ExitFailure, "hook_failed", msg, cause)
+		}
+	}
+	return outcomes, nil
+}
+
+// run executes a single hook and captures its outcome.
+func (r *Runner) run(def strategy.HookDef, ev Event) Outcome {
+	out := Outcome{Hook: ev.Hook, Blocking: def.Blocking, At: time.Now().UTC()}
+	kind, target, err := def.Kind()
+	if err != nil {
+		out.Error = err.Error()
+		out.ExitCode = -1
+		return out
+	}
+	out.Kind = kind
+	out.Command = target
+
+	ctx := context.Background()
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	cmd, err := r.command(ctx, kind, target)
+	if err != nil {
+		out.Error = err.Error()
+		out.ExitCode = -1
+		return out
+	}
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		out.Error = err.Error()
+		out.ExitCode = -1
+		return out
+	}
+	cmd.Dir = r.root
+	cmd.Env = append(os.Environ(), env(ev)...)
+	if kind == strategy.HookSkill {
+		cmd.Env = append(cmd.Env, "AGENTOPS_SKILL="+target, "AGENTOPS_SKILL_PATH="+workerresource.SkillPath(r.fs, r.root, target))
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	var buf bytes.Buffer
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+	// Children of sh may keep the output open after a timeout kill.
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	runErr := cmd.Run()
+	out.DurationMs = time.Since(start).Milliseconds()
+	out.Output = tail(strings.TrimSpace(buf.String()), maxOutput)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		out.Error = fmt.Sprintf("hook timed out after %s", r.Timeout)
+		out.ExitCode = -1
+		return out
+	}
+	if runErr != nil {
+		out.Error = runErr.Error()
+		out.ExitCode = 1
+		if exitErr, ok := runErr.(*exec.ExitError); ok {
+			out.ExitCode = exitErr.ExitCode()
+		}
+		return out
+	}
+	out.OK = true
+	return out
+}
+
+// command builds the process for a hook of the given kind.
+func (r *Runner) command(ctx context.Context, kind, target string) (*exec.Cmd, error) {
+	switch kind {
+	case strategy.HookShell:
+		return exec.CommandContext(ctx, "sh", "-c", target), nil
+	case strategy.HookAgentops:
+		if r.self == "" {
+			return nil, fmt.Errorf("cannot resolve agentops executable")
+		}
+		return exec.CommandContext(ctx, r.self, strings.Fields(target)...), nil
+	default:
+		if strings.TrimSpace(r.cfg.SkillRunner) == "" {
+			return nil, fmt.Errorf("skill hook %q requires skill_runner in hooks.yaml", target)
+		}
+		return exec.CommandContext(ctx, "sh", "-c", r.cfg.SkillRunner+" "+shellQuote(target)), nil
+	}
+}
+
+// log appends an outcome to the case's hook log sidecar.
+func (r *Runner) log(caseDir string, out Outcome) error {
+	if caseDir == "" {
+		return nil
+	}
+	line, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(caseDir, LogFile)
+	existing, _ := r.fs.ReadFile(path)
+	return r.fs.WriteFile(path, append(existing, append(line, '\n')...), 0o644)
+}
+
+// env flattens an event into AGENTOPS_* environment variables.
+func env(ev Event) []string {
+	return []string{
+		"AGENTOPS_HOOK=" + ev.Hook,
+		"AGENTOPS_CASE_ID=" + ev.CaseID,
+		"AGENTOPS_CASE_DIR=" + ev.CaseDir,
+		"AGENTOPS_CASE_TYPE=" + ev.Type,
+		"AGENTOPS_CASE_STATUS=" + ev.Status,
+		"AGENTOPS_FROM_STATUS=" + ev.FromStatus,
+		"AGENTOPS_TO_STATUS=" + ev.ToStatus,
+		"AGENTOPS_ACTION=" + ev.Action,
+		"AGENTOPS_SLOT=" + ev.Slot,
+		"AGENTOPS_WORKER=" + ev.Worker,
+	}
+}
+
+// shellQuote single-quotes s for safe use in a sh -c command line.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// tail keeps the last max bytes of s.
+func tail(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[len(s)-max:]
+}
@@ -0,0 +1,199 @@
+package hooks
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func readLog(t *testing.T, caseDir string) []Outcome {
+	t.Helper()
+	f, err := os.Open(filepath.Join(caseDir, LogFile))
+	if err != nil {
+		t.Fatalf("open hook log: %v", err)
+	}
+	defer f.Close()
+	var outs []Outcome
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var o Outcome
+		if err := json.Unmarshal(sc.Bytes(), &o); err != nil {
+			t.Fatalf("parse log line: %v", err)
+		}
+		outs = append(outs, o)
+	}
+	return outs
+}
+
+func TestFireShellHookReceivesEnvAndStdin(t *testing.T) {
+	root := t.TempDir()
+	caseDir := t.TempDir()
+	cfg := strategy.HooksConfig{
+		OnCaseOpen: []strategy.HookDef{
+			{Shell: `echo "$AGENTOPS_CASE_ID:$AGENTOPS_CASE_STATUS" > env.txt && cat > stdin.json`},
+		},
+	}
+	r := NewRunner(dal.NewFileSystem(), cfg, root)
+
+	outcomes, err := r.Fire(Event{Hook: OnCaseOpen, CaseID: "CASE-1", CaseDir: caseDir, Status: "open"})
+	if err != nil {
+		t.Fatalf("Fire: %v", err)
+	}
+	if len(outcomes) != 1 || !outcomes[0].OK {
+		t.Fatalf("expected one successful outcome, got %+v", outcomes)
+	}
+
+	envOut, err := os.ReadFile(filepath.Join(root, "env.txt"))
+	if err != nil {
+		t.Fatalf("read env.txt: %v", err)
+	}
+	if strings.TrimSpace(string(envOut)) != "CASE-1:open" {
+		t.Errorf("env output = %q", envOut)
+	}
+
+	var ev Event
+	stdin, err := os.ReadFile(filepath.Join(root, "stdin.json"))
+	if err != nil {
+		t.Fatalf("read stdin.json: %v", err)
+	}
+	if err := json.Unmarshal(stdin, &ev); err != nil {
+		t.Fatalf("parse stdin: %v", err)
+	}
+	if ev.Hook != OnCaseOpen || ev.CaseID != "CASE-1" {
+		t.Errorf("stdin event = %+v", ev)
+	}
+
+	if got := readLog(t, caseDir); len(got) != 1 || got[0].Kind != strategy.HookShell {
+		t.Errorf("hook log = %+v", got)
+	}
+}
+
+func TestFireNonBlockingFailureContinues(t *testing.T) {
+	caseDir := t.TempDir()
+	cfg := strategy.HooksConfig{
+		OnCaseTransition: []strategy.HookDef{
+			{Shell: "exit 3"},
+			{Shell: "true"},
+		},
+	}
+	r := NewRunner(dal.NewFileSystem(), cfg, t.TempDir())
+
+	outcomes, err := r.Fire(Event{Hook: OnCaseTransition, CaseDir: caseDir})
+	if err != nil {
+		t.Fatalf("non-blocking failure should not error: %v", err)
+	}
+	if len(outcomes) != 2 {
+		t.Fatalf("expected 2 outcomes, got %d", len(outcomes))
+	}
+	if outcomes[0].OK || outcomes[0].ExitCode != 3 {
+		t.Errorf("first outcome = %+v, want exit 3", outcomes[0])
+	}
+	if !outcomes[1].OK {
+		t.Errorf("second outcome should succeed: %+v", outcomes[1])
+	}
+	if got := readLog(t, caseDir); len(got) != 2 {
+		t.Errorf("expected 2 logged outcomes, got %d", len(got))
+	}
+}
+
+func TestFireBlockingFailureStops(t *testing.T) {
+	caseDir := t.TempDir()
+	cfg := strategy.HooksConfig{
+		PostClose: []strategy.HookDef{
+			{Shell: "exit 1", Blocking: true},
+			{Shell: "true"},
+		},
+	}
+	r := NewRunner(dal.NewFileSystem(), cfg, t.TempDir())
+
+	outcomes, err := r.Fire(Event{Hook: OnCaseClose, CaseDir: caseDir})
+	if err == nil {
+		t.Fatal("expected error for blocking failure")
+	}
+	if code := agentops.ResolveExitCode(err); code != agentops.ExitFailure {
+		t.Errorf("exit code = %d, want %d", code, agentops.ExitFailure)
+	}
+	if len(outcomes) != 1 {
+		t.Errorf("expected run to stop after blocking failure, got %d outcomes", len(outcomes))
+	}
+}
+
+func TestFireSkillHookRequiresRunner(t *testing.T) {
+	cfg := strategy.HooksConfig{
+		OnReconcileDone: []strategy.HookDef{{Skill: "audit"}},
+	}
+	r := NewRunner(dal.NewFileSystem(), cfg, t.TempDir())
+
+	outcomes, err := r.Fire(Event{Hook: OnReconcileDone, CaseDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Fire: %v", err)
+	}
+	if outcomes[0].OK || !strings.Contains(outcomes[0].Error, "skill_runner") {
+		t.Errorf("expected skill_runner error, got %+v", outcomes[0])
+	}
+
+	cfg.SkillRunner = "echo"
+	r = NewRunner(dal.NewFileSystem(), cfg, t.TempDir())
+	outcomes, err = r.Fire(Event{Hook: OnReconcileDone, CaseDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Fire: %v", err)
+	}
+	if !outcomes[0].OK || outcomes[0].Output != "audit" {
+		t.Errorf("skill outcome = %+v", outcomes[0])
+	}
+}
+
+func TestFireSkillHookResolvesWorkerPrecedence(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{filepath.Join(".agentops", "workers"), filepath.Join(".claude", "skills")} {
+		skillDir := filepath.Join(root, dir, "audit")
+		if err := os.MkdirAll(skillDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("# audit\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := strategy.HooksConfig{
+		SkillRunner:     `echo "$AGENTOPS_SKILL_PATH"`,
+		OnReconcileDone: []strategy.HookDef{{Skill: "audit"}},
+	}
+	r := NewRunner(dal.NewFileSystem(), cfg, root)
+
+	outcomes, err := r.Fire(Event{Hook: OnReconcileDone, CaseDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Fire: %v", err)
+	}
+	want := filepath.Join(root, ".agentops", "workers", "audit", "SKILL.md") + " audit"
+	if outcomes[0].Output != want {
+		t.Errorf("skill path = %q, want %q", outcomes[0].Output, want)
+	}
+}
+
+func TestFireHookTimeout(t *testing.T) {
+	cfg := strategy.HooksConfig{
+		PreDispatch: []strategy.HookDef{{Shell: "sleep 5", Blocking: true}},
+	}
+	r := NewRunner(dal.NewFileSystem(), cfg, t.TempDir())
+	r.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	outcomes, err := r.Fire(Event{Hook: PreDispatch, CaseDir: t.TempDir()})
+	if err == nil {
+		t.Fatal("expected a blocking timeout to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("timed out hook ran for %s", elapsed)
+	}
+	if outcomes[0].OK || !strings.Contains(outcomes[0].Error, "timed out after 100ms") {
+		t.Errorf("outcome = %+v", outcomes[0])
+	}
+}
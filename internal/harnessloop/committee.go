@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/gh-xj/agentops/budget"
 )
 
 type plannerOutput struct {
@@ -32,7 +34,6 @@ type roleContext struct {
 	Mode        string         `json:"mode"`
 	Iteration   int            `json:"iteration"`
 	Threshold   float64        `json:"threshold"`
-	Budget      int            `json:"budget"`
 	Seed        int64          `json:"seed"`
 	Scenario    ScenarioResult `json:"scenario"`
 	Findings    []Finding      `json:"findings"`
@@ -41,7 +42,7 @@ type roleContext struct {
 	ArtifactDir string         `json:"artifact_dir"`
 }
 
-func runCommittee(cfg Config, agentcliBin string, started time.Time, runID string) (RunResult, error) {
+func runCommittee(cfg Config, tracker *budget.Tracker, agentcliBin string, started time.Time, runID string) (RunResult, error) {
 	roles, err := loadRoleConfig(cfg.RoleConfigPath)
 	if err != nil {
 		return RunResult{}, err
@@ -68,7 +69,13 @@ func runCommittee(cfg Config, agentcliBin string, started time.Time, runID strin
 		},
 	}
 
+	var overBudget []Finding
 	for i := 1; i <= cfg.MaxIterations; i++ {
+		if stop, err := budgetStop(tracker.Reserve(budget.Iterations, 1), &overBudget); err != nil {
+			return result, err
+		} else if stop {
+			break
+		}
 		iterArtifacts := ""
 		if cfg.VerboseArtifacts {
 			iterArtifacts = filepath.Join(baseArtifacts, fmt.Sprintf("iter-%02d", i))
@@ -81,12 +88,16 @@ func runCommittee(cfg Config, agentcliBin string, started time.Time, runID strin
 		if err != nil {
 			return result, err
 		}
+		if stop, err := budgetStop(tracker.Charge(budget.Commands, len(sr.Steps)), &overBudget); err != nil {
+			return result, err
+		} else if stop {
+			break
+		}
 		ctx := roleContext{
 			RunID:       runID,
 			Mode:        cfg.Mode,
 			Iteration:   i,
 			Threshold:   cfg.Threshold,
-			Budget:      cfg.Budget,
 			Seed:        cfg.Seed,
 			Scenario:    sr,
 			Findings:    findings,
@@ -115,6 +126,10 @@ func runCommittee(cfg Config, agentcliBin string, started time.Time, runID strin
 		if err != nil {
 			return result, err
 		}
+		stop, err := budgetStop(tracker.Charge(budget.Commands, len(postScenario.Steps)), &overBudget)
+		if err != nil {
+			return result, err
+		}
 		jCtx := ctx
 		jCtx.Scenario = postScenario
 		jCtx.Findings = postFindings
@@ -140,13 +155,14 @@ func runCommittee(cfg Config, agentcliBin string, started time.Time, runID strin
 		result.Iterations = i
 		result.FinishedAt = time.Now().UTC()
 
-		if score.Pass || !cfg.AutoFix || len(fixes) == 0 {
+		if score.Pass || !cfg.AutoFix || len(fixes) == 0 || stop {
 			break
 		}
 	}
+	result.Findings = append(result.Findings, overBudget...)
 
 	if result.Iterations == 0 {
-		result.Iterations = 1
+		// No iteration completed, e.g. budget.yaml allowed none.
 		result.FinishedAt = time.Now().UTC()
 	}
 
@@ -1,8 +1,14 @@
 package harnessloop
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 	"time"
+
+	"github.com/gh-xj/agentops/budget"
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
 )
 
 type Config struct {
@@ -15,8 +21,9 @@ type Config struct {
 	Mode             string
 	RoleConfigPath   string
 	Seed             int64
-	Budget           int
 	VerboseArtifacts bool
+	BudgetLimits     strategy.BudgetLimits // budget.yaml limits; iterations and scenario steps count against them
+	BudgetSidecar    string                // file in the run's artifact directory that records usage; empty keeps it in memory
 }
 
 func RunLoop(cfg Config) (RunResult, error) {
@@ -28,17 +35,31 @@ func RunLoop(cfg Config) (RunResult, error) {
 	if err != nil {
 		return RunResult{}, err
 	}
+	// Usage is scoped to the run: every run starts from zero and records its
+	// own sidecar next to its artifacts.
+	budgetPath := ""
+	if cfg.BudgetSidecar != "" {
+		budgetPath = filepath.Join(cfg.RepoRoot, ".docs", "onboarding-loop", "runs", runID, cfg.BudgetSidecar)
+	}
+	tracker, err := budget.Open(dal.NewFileSystem(), cfg.BudgetLimits, budgetPath)
+	if err != nil {
+		return RunResult{}, err
+	}
 
 	var result RunResult
 	switch cfg.Mode {
 	case "committee":
-		result, err = runCommittee(cfg, agentcliBin, started, runID)
+		result, err = runCommittee(cfg, tracker, agentcliBin, started, runID)
 	default:
-		result, err = runClassic(cfg, agentcliBin, started, runID)
+		result, err = runClassic(cfg, tracker, agentcliBin, started, runID)
 	}
 	if err != nil {
 		return result, err
 	}
+	if cfg.BudgetLimits != (strategy.BudgetLimits{}) {
+		usage := tracker.Usage
+		result.Budget = &usage
+	}
 
 	if err := WriteReports(cfg.RepoRoot, result); err != nil {
 		return result, err
@@ -72,25 +93,33 @@ func normalizeConfig(cfg Config) Config {
 	if cfg.Mode == "" {
 		cfg.Mode = "committee"
 	}
-	if cfg.Budget <= 0 {
-		cfg.Budget = 1
-	}
 	return cfg
 }
 
-func runClassic(cfg Config, agentcliBin string, started time.Time, runID string) (RunResult, error) {
+func runClassic(cfg Config, tracker *budget.Tracker, agentcliBin string, started time.Time, runID string) (RunResult, error) {
 	var best RunResult
 	best.Judge.Score = -1
 	best.Mode = cfg.Mode
 	best.RunID = runID
 
+	var overBudget []Finding
 	iterations := 0
-	for ; iterations < cfg.MaxIterations; iterations++ {
+	for i := 0; i < cfg.MaxIterations; i++ {
+		if stop, err := budgetStop(tracker.Reserve(budget.Iterations, 1), &overBudget); err != nil {
+			return RunResult{}, err
+		} else if stop {
+			break
+		}
+		iterations++
 		scenario := DefaultOnboardingScenario(agentcliBin)
 		sr, err := RunScenario(scenario)
 		if err != nil {
 			return RunResult{}, err
 		}
+		stop, err := budgetStop(tracker.Charge(budget.Commands, len(sr.Steps)), &overBudget)
+		if err != nil {
+			return RunResult{}, err
+		}
 		findings := DetectFindings(sr)
 		findings = append(findings, CheckOnboardingInstallReadiness(cfg.RepoRoot)...)
 		judge := Judge(sr, findings, cfg.Threshold)
@@ -101,7 +130,7 @@ func runClassic(cfg Config, agentcliBin string, started time.Time, runID string)
 			Scenario:      sr,
 			Findings:      findings,
 			Judge:         judge,
-			Iterations:    iterations + 1,
+			Iterations:    iterations,
 			Branch:        CurrentBranch(cfg.RepoRoot),
 			Mode:          cfg.Mode,
 			RunID:         runID,
@@ -109,7 +138,7 @@ func runClassic(cfg Config, agentcliBin string, started time.Time, runID string)
 		if judge.Score > best.Judge.Score {
 			best = run
 		}
-		if judge.Pass || !cfg.AutoFix {
+		if judge.Pass || !cfg.AutoFix || stop {
 			break
 		}
 		applied, err := ApplyFixes(cfg.RepoRoot, findings)
@@ -122,7 +151,27 @@ func runClassic(cfg Config, agentcliBin string, started time.Time, runID string)
 			break
 		}
 	}
-	best.Iterations = iterations + 1
+	best.Iterations = iterations
 	best.FinishedAt = time.Now().UTC()
+	best.Findings = append(best.Findings, overBudget...)
 	return best, nil
 }
+
+// budgetStop records a budget.yaml limit the loop reached as a finding and
+// reports whether the loop must stop. Other errors are returned as is.
+func budgetStop(err error, findings *[]Finding) (bool, error) {
+	if err == nil {
+		return false, nil
+	}
+	var exceeded *budget.Exceeded
+	if !errors.As(err, &exceeded) {
+		return false, err
+	}
+	*findings = append(*findings, Finding{
+		Code:     "budget_exceeded",
+		Severity: "high",
+		Message:  exceeded.Message,
+		Source:   "budget.yaml",
+	})
+	return true, nil
+}
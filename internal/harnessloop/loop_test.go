@@ -0,0 +1,64 @@
+package harnessloop
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gh-xj/agentops/budget"
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func TestBudgetStop(t *testing.T) {
+	tracker, err := budget.Open(dal.NewFileSystem(), strategy.BudgetLimits{MaxIterations: 1}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var findings []Finding
+	if stop, err := budgetStop(tracker.Reserve(budget.Iterations, 1), &findings); stop || err != nil {
+		t.Fatalf("first iteration: stop=%v err=%v", stop, err)
+	}
+	if stop, err := budgetStop(tracker.Reserve(budget.Iterations, 1), &findings); !stop || err != nil {
+		t.Fatalf("second iteration: stop=%v err=%v", stop, err)
+	}
+	if len(findings) != 1 || findings[0].Code != "budget_exceeded" || findings[0].Source != "budget.yaml" {
+		t.Errorf("findings = %+v", findings)
+	}
+
+	boom := errors.New("disk full")
+	if stop, err := budgetStop(boom, &findings); stop || !errors.Is(err, boom) {
+		t.Errorf("other error: stop=%v err=%v", stop, err)
+	}
+}
+
+func TestRunStopsBeforeFirstIterationOverBudget(t *testing.T) {
+	for _, mode := range []string{"classic", "committee"} {
+		t.Run(mode, func(t *testing.T) {
+			tracker, err := budget.Open(dal.NewFileSystem(), strategy.BudgetLimits{MaxIterations: 1}, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tracker.Reserve(budget.Iterations, 1); err != nil {
+				t.Fatal(err)
+			}
+			cfg := normalizeConfig(Config{RepoRoot: t.TempDir(), Mode: mode})
+
+			var result RunResult
+			if mode == "committee" {
+				result, err = runCommittee(cfg, tracker, "agentops", time.Now().UTC(), "r1")
+			} else {
+				result, err = runClassic(cfg, tracker, "agentops", time.Now().UTC(), "r1")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Iterations != 0 {
+				t.Errorf("Iterations = %d, want 0", result.Iterations)
+			}
+			if len(result.Findings) != 1 || result.Findings[0].Code != "budget_exceeded" {
+				t.Errorf("findings = %+v", result.Findings)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package harnessloop
 
-import "time"
+import (
+	"time"
+
+	"github.com/gh-xj/agentops/budget"
+)
 
 type Step struct {
 	Name    string `json:"name"`
@@ -52,6 +56,7 @@ type RunResult struct {
 	Mode          string         `json:"mode,omitempty"`
 	RunID         string         `json:"run_id,omitempty"`
 	Committee     *CommitteeMeta `json:"committee,omitempty"`
+	Budget        *budget.Usage  `json:"budget,omitempty"`
 }
 
 type JudgeScore struct {
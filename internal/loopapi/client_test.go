@@ -44,7 +44,6 @@ func TestRunClientSuccess(t *testing.T) {
 		RoleConfig:       "configs/skill-quality.roles.json",
 		VerboseArtifacts: true,
 		Seed:             42,
-		Budget:           2,
 	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -52,7 +51,7 @@ func TestRunClientSuccess(t *testing.T) {
 	if !out.Judge.Pass || out.Judge.Score < 9 {
 		t.Fatalf("unexpected score: %+v", out.Judge)
 	}
-	if got.Action != "judge" || got.RepoRoot != "/tmp/repo" || got.Threshold != 8.5 || got.MaxIterations != 4 || got.Branch != "autofix/test" || got.Mode != "committee" || got.RoleConfig != "configs/skill-quality.roles.json" || !got.VerboseArtifacts || got.Seed != 42 || got.Budget != 2 {
+	if got.Action != "judge" || got.RepoRoot != "/tmp/repo" || got.Threshold != 8.5 || got.MaxIterations != 4 || got.Branch != "autofix/test" || got.Mode != "committee" || got.RoleConfig != "configs/skill-quality.roles.json" || !got.VerboseArtifacts || got.Seed != 42 {
 		t.Fatalf("unexpected request payload: %+v", got)
 	}
 }
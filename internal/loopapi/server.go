@@ -24,7 +24,6 @@ type RunRequest struct {
 	RoleConfig       string  `json:"role_config"`
 	VerboseArtifacts bool    `json:"verbose_artifacts"`
 	Seed             int64   `json:"seed"`
-	Budget           int     `json:"budget"`
 }
 
 func Serve(addr, defaultRepoRoot string) error {
@@ -67,7 +66,6 @@ func loopRunHandler(defaultRepoRoot string) http.HandlerFunc {
 			RoleConfigPath:   roleConfigPath,
 			VerboseArtifacts: req.VerboseArtifacts,
 			Seed:             req.Seed,
-			Budget:           req.Budget,
 		}
 		switch req.Action {
 		case "run", "judge":
@@ -0,0 +1,112 @@
+// Package queue ranks the cases a slot may pick up by the weights a strategy
+// declares in queue.yaml.
+package queue
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// Candidate is one eligible case and the signals it is ranked by.
+type Candidate struct {
+	ID        string
+	Priority  any    // the priority frontmatter field: a rank or a named value
+	RiskLevel string // the risk frontmatter field
+	Created   any    // the created frontmatter field: a date string or time.Time
+}
+
+// Ranked is a candidate with its score and the parts that make it up.
+type Ranked struct {
+	ID       string  `json:"id"`
+	Score    float64 `json:"score"`
+	Priority int     `json:"priority"` // priority rank
+	Risk     int     `json:"risk"`     // risk levels above the lowest
+	AgeDays  float64 `json:"age_days"` // days since created
+	created  time.Time
+}
+
+// Rank scores candidates and orders them best first: highest score, then
+// oldest, then by ID.
+func Rank(cfg strategy.QueueConfig, risk strategy.RiskConfig, candidates []Candidate, now time.Time) []Ranked {
+	w := cfg.EffectiveWeights()
+	ranks := cfg.PriorityRanks()
+	levels := risk.LevelNames()
+
+	out := make([]Ranked, 0, len(candidates))
+	for _, c := range candidates {
+		r := Ranked{
+			ID:       c.ID,
+			Priority: priorityRank(c.Priority, ranks),
+			Risk:     max(slices.Index(levels, c.RiskLevel), 0),
+			created:  createdAt(c.Created),
+		}
+		if !r.created.IsZero() {
+			r.AgeDays = math.Round(max(now.Sub(r.created).Hours()/24, 0)*100) / 100
+		}
+		r.Score = w.Priority*float64(r.Priority) + w.Risk*float64(r.Risk) + w.Age*r.AgeDays
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.created.Equal(b.created) {
+			switch {
+			case a.created.IsZero(): // undated cases go last
+				return false
+			case b.created.IsZero():
+				return true
+			}
+			return a.created.Before(b.created)
+		}
+		return a.ID < b.ID
+	})
+	return out
+}
+
+// priorityRank converts a priority field value to its rank. Integers rank as
+// themselves; names are looked up case-insensitively. Anything else is 0.
+func priorityRank(v any, ranks map[string]int) int {
+	switch t := v.(type) {
+	case nil:
+		return 0
+	case int:
+		return t
+	case float64:
+		return int(t)
+	}
+	s := strings.TrimSpace(fmt.Sprint(v))
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	for name, rank := range ranks {
+		if strings.EqualFold(name, s) {
+			return rank
+		}
+	}
+	return 0
+}
+
+// createdAt parses the created field: the 20060102 form new cases are
+// written with, an ISO date, or a time YAML has already decoded.
+func createdAt(v any) time.Time {
+	switch t := v.(type) {
+	case time.Time:
+		return t
+	case string:
+		for _, layout := range []string{"20060102", time.DateOnly, time.RFC3339} {
+			if ts, err := time.Parse(layout, t); err == nil {
+				return ts
+			}
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func TestRankDefaults(t *testing.T) {
+	now := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	candidates := []Candidate{
+		{ID: "old-low", Priority: "low", Created: "2026-03-01"},                              // age 30
+		{ID: "new-high", Priority: "high", RiskLevel: "high", Created: "2026-03-30"},         // 20 + 10 + 1
+		{ID: "critical", Priority: 3, Created: time.Date(2026, 3, 29, 0, 0, 0, 0, time.UTC)}, // 30 + 2
+		{ID: "undated", Priority: "medium"},                                                  // 10
+	}
+	got := Rank(strategy.QueueConfig{}, strategy.RiskConfig{}, candidates, now)
+	want := []struct {
+		id    string
+		score float64
+	}{{"critical", 32}, {"new-high", 31}, {"old-low", 30}, {"undated", 10}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d ranked, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].ID != w.id || got[i].Score != w.score {
+			t.Errorf("rank %d = %s (%g), want %s (%g)", i, got[i].ID, got[i].Score, w.id, w.score)
+		}
+	}
+}
+
+func TestRankConfiguredWeightsAndTies(t *testing.T) {
+	now := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	cfg := strategy.QueueConfig{
+		Weights:    strategy.QueueWeights{Priority: 1},
+		Priorities: map[string]int{"P0": 5},
+	}
+	candidates := []Candidate{
+		{ID: "b", Priority: "p0", Created: "2026-03-20"},
+		{ID: "a", Priority: "p0", Created: "2026-03-20"},
+		{ID: "older", Priority: "p0", Created: "2026-03-01"},
+		{ID: "unknown", Priority: "someday", Created: "2026-01-01"},
+	}
+	got := Rank(cfg, strategy.RiskConfig{}, candidates, now)
+	order := []string{"older", "a", "b", "unknown"}
+	for i, id := range order {
+		if got[i].ID != id {
+			t.Errorf("rank %d = %s, want %s", i, got[i].ID, id)
+		}
+	}
+	if got[0].Score != 5 || got[3].Score != 0 {
+		t.Errorf("scores = %g, %g; age weight should be off", got[0].Score, got[3].Score)
+	}
+}
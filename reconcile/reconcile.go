@@ -0,0 +1,240 @@
+// Package reconcile merges the findings and status recommendations workers
+// write to their sidecars and picks the transition the reconcile rules in
+// routing.yaml call for.
+package reconcile
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gh-xj/agentops/risk"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// DefaultSeverity is the severity of a finding that does not declare one.
+const DefaultSeverity = "info"
+
+// Finding is one problem a worker reported.
+type Finding struct {
+	Code     string `json:"code"`
+	Message  string `json:"message,omitempty"`
+	Severity string `json:"severity"`
+	Source   string `json:"source"` // the worker that reported it
+}
+
+// Key identifies a finding across reconciliations: the same code from the
+// same worker is the same finding.
+type Key struct {
+	Code   string
+	Source string
+}
+
+// Key returns the finding's deduplication key.
+func (f Finding) Key() Key {
+	return Key{Code: f.Code, Source: f.Source}
+}
+
+// Recommendation is a status a worker suggests for the case. The dispatcher
+// decides whether to follow it.
+type Recommendation struct {
+	Source string `json:"source"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Output is what one worker sidecar contributes.
+type Output struct {
+	Findings       []Finding
+	Recommendation *Recommendation
+}
+
+// Report is the outcome of reconciling a case.
+type Report struct {
+	CaseID          string           `json:"case_id"`
+	ReconciledAt    time.Time        `json:"reconciled_at"`
+	Sources         []string         `json:"sources"` // sidecars read, relative to the case directory
+	Findings        []Finding        `json:"findings"`
+	Added           int              `json:"added"`   // findings new to case.md
+	Updated         int              `json:"updated"` // findings whose message or severity changed
+	Recommendations []Recommendation `json:"recommendations,omitempty"`
+	Rule            string           `json:"rule,omitempty"`       // the reconcile rule that applied
+	Transition      string           `json:"transition,omitempty"` // the action it applied
+	From            string           `json:"from,omitempty"`
+	To              string           `json:"to,omitempty"`
+}
+
+// Parse reads worker output from a decoded sidecar. An object contributes its
+// findings list and its recommend value, either a status or an object with
+// status and reason; a list is read as findings. Anything else, including an
+// object with neither key, contributes nothing.
+func Parse(source string, v any) (Output, error) {
+	var out Output
+	var findings any
+	switch t := v.(type) {
+	case []any:
+		findings = t
+	case map[string]any:
+		findings = t["findings"]
+		rec, err := parseRecommendation(source, t["recommend"])
+		if err != nil {
+			return Output{}, err
+		}
+		out.Recommendation = rec
+	default:
+		return out, nil
+	}
+	if findings == nil {
+		return out, nil
+	}
+	list, ok := findings.([]any)
+	if !ok {
+		return Output{}, fmt.Errorf("findings must be a list")
+	}
+	for i, item := range list {
+		f, err := parseFinding(source, item)
+		if err != nil {
+			return Output{}, fmt.Errorf("finding %d: %w", i+1, err)
+		}
+		out.Findings = append(out.Findings, f)
+	}
+	return out, nil
+}
+
+func parseFinding(source string, v any) (Finding, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return Finding{}, fmt.Errorf("must be an object")
+	}
+	f := Finding{
+		Code:     stringValue(m["code"]),
+		Message:  stringValue(m["message"]),
+		Severity: strings.ToLower(stringValue(m["severity"])),
+		Source:   source,
+	}
+	if f.Code == "" {
+		return Finding{}, fmt.Errorf("missing code")
+	}
+	if strings.ContainsAny(f.Code, " \t\n[]") {
+		return Finding{}, fmt.Errorf("code %q must be a single word", f.Code)
+	}
+	if f.Severity == "" {
+		f.Severity = DefaultSeverity
+	}
+	f.Message = strings.Join(strings.Fields(f.Message), " ")
+	return f, nil
+}
+
+func parseRecommendation(source string, v any) (*Recommendation, error) {
+	rec := &Recommendation{Source: source}
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		rec.Status = t
+	case map[string]any:
+		rec.Status = stringValue(t["status"])
+		rec.Reason = stringValue(t["reason"])
+	default:
+		return nil, fmt.Errorf("recommend must be a status or an object")
+	}
+	rec.Status = strings.TrimSpace(rec.Status)
+	if rec.Status == "" {
+		return nil, fmt.Errorf("recommend has no status")
+	}
+	return rec, nil
+}
+
+func stringValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(v))
+}
+
+// Dedupe drops all but the last of findings that share a key, keeping the
+// order in which each key first appeared.
+func Dedupe(findings []Finding) []Finding {
+	index := make(map[Key]int, len(findings))
+	var out []Finding
+	for _, f := range findings {
+		if i, ok := index[f.Key()]; ok {
+			out[i] = f
+			continue
+		}
+		index[f.Key()] = len(out)
+		out = append(out, f)
+	}
+	return out
+}
+
+// Decide returns the first rule in cfg whose criteria hold and whose
+// transition allowed accepts from the case's status.
+func Decide(cfg strategy.ReconcileConfig, findings []Finding, recs []Recommendation, allowed func(action string) bool) (strategy.ReconcileRule, bool) {
+	for i, rule := range cfg.Rules {
+		if rule.Transition == "" || !matches(rule.When, findings, recs) || !allowed(rule.Transition) {
+			continue
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		return rule, true
+	}
+	return strategy.ReconcileRule{}, false
+}
+
+// matches reports whether every non-empty criterion of m holds.
+func matches(m strategy.ReconcileMatch, findings []Finding, recs []Recommendation) bool {
+	if m.Clean && len(findings) > 0 {
+		return false
+	}
+	if len(m.Severities) > 0 || len(m.Codes) > 0 {
+		found := false
+		for _, f := range findings {
+			if matchFinding(m, f) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(m.Recommend) > 0 {
+		found := false
+		for _, r := range recs {
+			if containsFold(m.Recommend, r.Status) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func matchFinding(m strategy.ReconcileMatch, f Finding) bool {
+	if len(m.Severities) > 0 && !containsFold(m.Severities, f.Severity) {
+		return false
+	}
+	if len(m.Codes) == 0 {
+		return true
+	}
+	for _, pattern := range m.Codes {
+		if risk.Glob(pattern, f.Code) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, e := range list {
+		if strings.EqualFold(e, s) {
+			return true
+		}
+	}
+	return false
+}
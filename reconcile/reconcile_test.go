@@ -0,0 +1,121 @@
+package reconcile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		v         any
+		findings  string
+		recommend string
+		err       string
+	}{
+		{name: "not output", v: map[string]any{"verdict": "pass"}},
+		{name: "scalar", v: "pass"},
+		{
+			name: "object",
+			v: map[string]any{
+				"findings":  []any{map[string]any{"code": "nil-deref", "message": "x may be\n nil", "severity": "ERROR"}, map[string]any{"code": "style"}},
+				"recommend": "blocked",
+			},
+			findings:  "error nil-deref x may be nil|info style ",
+			recommend: "blocked",
+		},
+		{
+			name:      "recommend object",
+			v:         map[string]any{"recommend": map[string]any{"status": "resolved", "reason": "tests pass"}},
+			recommend: "resolved: tests pass",
+		},
+		{name: "list", v: []any{map[string]any{"code": "a"}}, findings: "info a "},
+		{name: "missing code", v: []any{map[string]any{"message": "m"}}, err: "finding 1: missing code"},
+		{name: "spaced code", v: []any{map[string]any{"code": "two words"}}, err: "must be a single word"},
+		{name: "findings not list", v: map[string]any{"findings": "none"}, err: "findings must be a list"},
+		{name: "empty recommend", v: map[string]any{"recommend": map[string]any{}}, err: "recommend has no status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Parse("review", tt.v)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			var got []string
+			for _, f := range out.Findings {
+				if f.Source != "review" {
+					t.Errorf("source = %q", f.Source)
+				}
+				got = append(got, f.Severity+" "+f.Code+" "+f.Message)
+			}
+			if strings.Join(got, "|") != tt.findings {
+				t.Errorf("findings = %q, want %q", strings.Join(got, "|"), tt.findings)
+			}
+			rec := ""
+			if r := out.Recommendation; r != nil {
+				rec = r.Status
+				if r.Reason != "" {
+					rec += ": " + r.Reason
+				}
+			}
+			if rec != tt.recommend {
+				t.Errorf("recommend = %q, want %q", rec, tt.recommend)
+			}
+		})
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	got := Dedupe([]Finding{
+		{Code: "a", Source: "review", Message: "first"},
+		{Code: "b", Source: "review"},
+		{Code: "a", Source: "lint"},
+		{Code: "a", Source: "review", Message: "second"},
+	})
+	if len(got) != 3 || got[0].Message != "second" || got[1].Code != "b" || got[2].Source != "lint" {
+		t.Errorf("Dedupe = %+v", got)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	cfg := strategy.ReconcileConfig{Rules: []strategy.ReconcileRule{
+		{Name: "clean", When: strategy.ReconcileMatch{Clean: true}, Transition: "resolve"},
+		{Name: "security", When: strategy.ReconcileMatch{Severities: []string{"error"}, Codes: []string{"sec-*"}}, Transition: "escalate"},
+		{Name: "errors", When: strategy.ReconcileMatch{Severities: []string{"error"}}, Transition: "block"},
+		{When: strategy.ReconcileMatch{Recommend: []string{"resolved"}}, Transition: "resolve"},
+	}}
+	errorFinding := Finding{Code: "nil-deref", Severity: "error", Source: "review"}
+	secWarning := Finding{Code: "sec-secret", Severity: "warning", Source: "review"}
+	resolved := Recommendation{Source: "test", Status: "Resolved"}
+
+	tests := []struct {
+		name     string
+		findings []Finding
+		recs     []Recommendation
+		denied   string
+		rule     string
+	}{
+		{name: "clean", rule: "clean"},
+		{name: "clean but not allowed", denied: "resolve"},
+		{name: "codes and severities on one finding", findings: []Finding{errorFinding, secWarning}, rule: "errors"},
+		{name: "security", findings: []Finding{{Code: "sec-secret", Severity: "error"}}, rule: "security"},
+		{name: "recommendation", findings: []Finding{secWarning}, recs: []Recommendation{resolved}, rule: "rule-4"},
+		{name: "no match", findings: []Finding{secWarning}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := Decide(cfg, tt.findings, tt.recs, func(action string) bool { return action != tt.denied })
+			if ok != (tt.rule != "") || rule.Name != tt.rule {
+				t.Errorf("Decide = %q, %v; want %q", rule.Name, ok, tt.rule)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package caseresource
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/resource"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// ActionArchive is the history action of moving a case to the archive. The
+// storage group it left is recorded in the event's from field.
+const ActionArchive = "archive"
+
+// Prune result actions for cases, which are archived rather than removed.
+const (
+	PruneArchived     = "archived"
+	PruneWouldArchive = "would_archive"
+	PruneSkipped      = "skipped"
+)
+
+// Delete archives a case: its directory moves to archive/<slot>/ along with
+// its history, and it no longer appears in list, get or next. Nothing is
+// deleted from disk. Cases claimed by another slot are refused.
+func (cr *CaseResource) Delete(ctx *agentops.AppContext, id string) error {
+	if cr.strat == nil {
+		return strategy.Missing(cr.loadErr)
+	}
+	loc, err := cr.locate(id)
+	if err != nil {
+		return err
+	}
+	fm, err := cr.readFrontmatter(loc.Dir)
+	if err != nil {
+		return err
+	}
+	if err := CheckClaim(id, fm.GetString(keyClaimedBy), cr.currentSlot(ctx)); err != nil {
+		return err
+	}
+	_, err = cr.archive(ctx, loc)
+	return err
+}
+
+// Prune archives cases that have been idle in a status category for longer
+// than the category's retention in storage.yaml. A case is idle since its
+// last history event, or since it was created when it has no history. Nothing
+// is archived unless confirm is true, and cases claimed by another slot are
+// skipped.
+func (cr *CaseResource) Prune(ctx *agentops.AppContext, confirm bool) ([]resource.PruneResult, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	locs, err := cr.scanCases()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var results []resource.PruneResult
+	for _, loc := range locs {
+		fm, err := cr.readFrontmatter(loc.Dir)
+		if err != nil {
+			continue // reported by case validate
+		}
+		status := fm.GetString(keyStatus)
+		category := cr.sm.CategoryForStatus(status)
+		keep, ok, err := cr.strat.Storage.RetentionFor(category)
+		if err != nil {
+			return nil, agentops.NewCLIError(agentops.ExitValidationFailed, "invalid_retention", "storage.yaml", err)
+		}
+		if !ok {
+			continue
+		}
+		since, ok := cr.lastActivity(loc.Dir, fm)
+		if !ok || now.Sub(since) < keep {
+			continue
+		}
+
+		res := resource.PruneResult{
+			Name: loc.ID,
+			Path: loc.Dir,
+			Reason: fmt.Sprintf("%s for %dd, past the %s retention of %s",
+				status, int(now.Sub(since).Hours()/24), category, cr.strat.Storage.Retention[category]),
+		}
+		switch {
+		case CheckClaim(loc.ID, fm.GetString(keyClaimedBy), cr.currentSlot(ctx)) != nil:
+			res.Action = PruneSkipped
+			res.Reason = "claimed by " + fm.GetString(keyClaimedBy)
+		case !confirm:
+			res.Action = PruneWouldArchive
+		default:
+			dest, err := cr.archive(NoteContext(ctx, "pruned: "+res.Reason), loc)
+			if err != nil {
+				res.Action = PruneSkipped
+				res.Reason = fmt.Sprintf("archive failed: %v", err)
+				break
+			}
+			res.Action, res.Path = PruneArchived, dest
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// archive moves a case into the archive group, keeping its slot, and records
+// the move in its history. It returns the archived case directory.
+func (cr *CaseResource) archive(ctx *agentops.AppContext, loc caseLocation) (string, error) {
+	casesRoot, err := cr.casesDir()
+	if err != nil {
+		return "", err
+	}
+	slot := loc.Slot
+	if slot == "" {
+		slot = unassignedSlot
+	}
+	dest, err := cr.moveCase(loc, filepath.Join(casesRoot, ArchiveGroup, slot, loc.ID))
+	if err != nil {
+		return "", err
+	}
+	ev := cr.newEvent(ctx, ActionArchive, loc.Group, ArchiveGroup)
+	if err := cr.appendEvent(dest, ev); err != nil {
+		return "", err
+	}
+	if err := cr.commit(loc.ID, ev); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// lastActivity returns when a case last changed: its latest history event,
+// or its created date.
+func (cr *CaseResource) lastActivity(caseDir string, fm *Frontmatter) (time.Time, bool) {
+	events, _ := cr.readEvents(filepath.Join(caseDir, EventsFile))
+	if len(events) > 0 {
+		return events[len(events)-1].At, true
+	}
+	created, err := time.ParseInLocation("20060102", fm.GetString(keyCreated), time.Local)
+	return created, err == nil
+}
+
+// readFrontmatter reads the frontmatter of the case in caseDir.
+func (cr *CaseResource) readFrontmatter(caseDir string) (*Frontmatter, error) {
+	data, err := cr.fs.ReadFile(filepath.Join(caseDir, "case.md"))
+	if err != nil {
+		return nil, fmt.Errorf("read case.md: %w", err)
+	}
+	fm, _, err := ParseFrontmatter(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+	return fm, nil
+}
@@ -0,0 +1,113 @@
+package caseresource
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/resource"
+)
+
+func TestCaseResourceDeleteArchives(t *testing.T) {
+	root, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+
+	created, err := cr.Create(testCtx(), "mistake", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := cr.Delete(testCtx(), created.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := cr.Get(testCtx(), created.ID); err == nil {
+		t.Error("archived case is still found by get")
+	}
+	records, err := cr.List(testCtx(), resource.Filter{})
+	if err != nil || len(records) != 0 {
+		t.Errorf("list = %v, %v; want no cases", records, err)
+	}
+
+	archived := filepath.Join(root, "cases", ArchiveGroup, unassignedSlot, created.ID)
+	events, err := cr.readEvents(filepath.Join(archived, EventsFile))
+	if err != nil || len(events) != 2 {
+		t.Fatalf("archived history = %+v, %v", events, err)
+	}
+	if last := events[1]; last.Action != ActionArchive || last.From != "active" || last.To != ArchiveGroup {
+		t.Errorf("archive event = %+v", last)
+	}
+
+	again, err := cr.Create(testCtx(), "mistake", nil)
+	if err != nil {
+		t.Fatalf("create again: %v", err)
+	}
+	if again.ID != created.ID+"-02" {
+		t.Errorf("new case reused archived ID: %s", again.ID)
+	}
+}
+
+func TestCaseResourcePrune(t *testing.T) {
+	_, strat := setupTestProject(t)
+	strat.Storage.Retention = map[string]string{"completed": "30d"}
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+
+	create := func(slug string, actions ...string) *resource.Record {
+		t.Helper()
+		rec, err := cr.Create(testCtx(), slug, nil)
+		if err != nil {
+			t.Fatalf("create %s: %v", slug, err)
+		}
+		for _, action := range actions {
+			if rec, err = cr.Transition(testCtx(), rec.ID, action); err != nil {
+				t.Fatalf("%s %s: %v", action, slug, err)
+			}
+		}
+		return rec
+	}
+	backdate := func(rec *resource.Record) {
+		t.Helper()
+		line := `{"at":"2020-01-02T15:04:05Z","actor":"user:test","action":"resolve","from":"in_progress","to":"resolved"}` + "\n"
+		if err := os.WriteFile(filepath.Join(filepath.Dir(rec.RawPath), EventsFile), []byte(line), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := create("old", "start", "resolve")
+	backdate(old)
+	create("recent", "start", "resolve")
+	stale := create("stale-but-active")
+	backdate(stale)
+
+	results, err := cr.Prune(testCtx(), false)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != old.ID || results[0].Action != PruneWouldArchive {
+		t.Fatalf("dry run = %+v", results)
+	}
+	if !strings.Contains(results[0].Reason, "past the completed retention of 30d") {
+		t.Errorf("reason = %q", results[0].Reason)
+	}
+	if _, err := cr.Get(testCtx(), old.ID); err != nil {
+		t.Errorf("dry run archived the case: %v", err)
+	}
+
+	results, err = cr.Prune(testCtx(), true)
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if len(results) != 1 || results[0].Action != PruneArchived || !strings.Contains(results[0].Path, ArchiveGroup) {
+		t.Fatalf("prune = %+v", results)
+	}
+	records, err := cr.List(testCtx(), resource.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, r := range records {
+		ids = append(ids, r.ID)
+	}
+	if len(ids) != 2 || strings.Contains(strings.Join(ids, ","), old.ID) {
+		t.Errorf("remaining cases = %v", ids)
+	}
+}
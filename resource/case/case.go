@@ -1,7 +1,9 @@
 package caseresource
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -9,18 +11,30 @@ import (
 
 	agentops "github.com/gh-xj/agentops"
 	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/hooks"
 	"github.com/gh-xj/agentops/resource"
+	slotresource "github.com/gh-xj/agentops/resource/slot"
+	"github.com/gh-xj/agentops/routing"
 	"github.com/gh-xj/agentops/strategy"
 )
 
 var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
 
+// completedCategory is the status category whose entry fires on-case-close hooks.
+const completedCategory = "completed"
+
+// keyBody is the pseudo-field list filters match against the case.md body.
+const keyBody = "body"
+
 // CaseResource implements the Resource, Validator, and Transitioner interfaces.
 type CaseResource struct {
 	fs    dal.FileSystem
 	exec  dal.Executor
 	strat *strategy.Strategy
 	sm    *StateMachine
+	hooks *hooks.Runner
+
+	loadErr error // why strat is nil, reported by commands that need it
 }
 
 // Compile-time interface checks.
@@ -39,14 +53,21 @@ func New(fs dal.FileSystem, exec dal.Executor, strat *strategy.Strategy) *CaseRe
 	}
 	if strat != nil {
 		cr.sm = NewStateMachine(strat.Transitions)
+		cr.hooks = hooks.NewRunner(fs, strat.Hooks, strat.Root)
 	}
 	return cr
 }
 
+// SetLoadError records why the strategy could not be loaded. Commands that
+// need a strategy return it instead of a generic strategy_missing error.
+func (cr *CaseResource) SetLoadError(err error) {
+	cr.loadErr = err
+}
+
 // casesDir resolves the cases directory based on storage backend configuration.
 func (cr *CaseResource) casesDir() (string, error) {
 	if cr.strat == nil {
-		return "", fmt.Errorf("no strategy loaded")
+		return "", strategy.Missing(cr.loadErr)
 	}
 
 	switch cr.strat.Storage.Backend {
@@ -54,19 +75,27 @@ func (cr *CaseResource) casesDir() (string, error) {
 		return filepath.Join(cr.strat.Root, "cases"), nil
 	default:
 		// separate-repo (default)
+		root := cr.sharedRoot()
 		caseRepoPath := cr.strat.Storage.CaseRepoPath
 		if caseRepoPath == "" {
-			base := filepath.Base(cr.strat.Root)
+			base := filepath.Base(root)
 			caseRepoPath = filepath.Join("..", base+"-cases")
 		}
 		// Resolve relative to the project root.
 		if !filepath.IsAbs(caseRepoPath) {
-			caseRepoPath = filepath.Join(cr.strat.Root, caseRepoPath)
+			caseRepoPath = filepath.Join(root, caseRepoPath)
 		}
 		return filepath.Join(caseRepoPath, "cases"), nil
 	}
 }
 
+// sharedRoot returns the project root in the main repository's checkout. Every
+// slot worktree resolves the separate case repository from it, so all slots
+// share one store.
+func (cr *CaseResource) sharedRoot() string {
+	return slotresource.MainPath(cr.fs, cr.strat.Root)
+}
+
 // Schema returns the resource schema for cases.
 func (cr *CaseResource) Schema() resource.ResourceSchema {
 	var statuses []string
@@ -75,167 +104,261 @@ func (cr *CaseResource) Schema() resource.ResourceSchema {
 	}
 
 	return resource.ResourceSchema{
-		Kind: "case",
-		Fields: []resource.FieldDef{
-			{Name: "id", Type: "string", Required: true},
-			{Name: "type", Type: "string", Required: true},
-			{Name: "status", Type: "string", Required: true},
-			{Name: "claimed_by", Type: "string", Required: false},
-			{Name: "created", Type: "string", Required: true},
-		},
-		Statuses: statuses,
-		CreateArgs: []resource.ArgDef{
-			{Name: "slug", Description: "URL-safe case identifier", Required: true},
-		},
+		Kind:        "case",
+		Fields:      cr.schemaFields(),
+		Statuses:    statuses,
+		CreateArgs:  cr.createArgs(),
 		Description: "A case record tracking an operational task through its lifecycle.",
 	}
 }
 
-// Create creates a new case directory and case.md file.
+// Create creates a new case directory and case.md file. case.md is rendered
+// from templates/<type>.md when the strategy has a template for the case's
+// type, and built from schema.md otherwise.
 func (cr *CaseResource) Create(ctx *agentops.AppContext, slug string, opts map[string]string) (*resource.Record, error) {
 	if cr.strat == nil {
-		return nil, fmt.Errorf("no strategy loaded")
+		return nil, strategy.Missing(cr.loadErr)
 	}
 
 	if err := validateSlug(slug); err != nil {
 		return nil, err
 	}
+	in, err := parseCreateOpts(opts)
+	if err != nil {
+		return nil, err
+	}
+	typ := in.typ
+	if typ == "" {
+		typ = cr.strat.Routing.DefaultType()
+	}
+	tmpl, values, err := cr.caseTemplate(typ, in)
+	if err != nil {
+		return nil, err
+	}
 
-	casesRoot, err := cr.casesDir()
+	groupDir, err := cr.groupDir(cr.sm.Initial(), cr.creatorSlot(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	if err := cr.fs.EnsureDir(casesRoot); err != nil {
+	if err := cr.initStore(); err != nil {
+		return nil, err
+	}
+	if err := cr.fs.EnsureDir(groupDir); err != nil {
 		return nil, fmt.Errorf("ensure cases dir: %w", err)
 	}
 
-	dateStr := time.Now().Format("20060102")
+	existing, err := cr.scanCases()
+	if err != nil {
+		return nil, err
+	}
+	archived, err := cr.scanArchive()
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool, len(existing)+len(archived))
+	for _, loc := range append(existing, archived...) {
+		taken[loc.ID] = true
+	}
+
+	now := time.Now()
+	dateStr := now.Format("20060102")
 	baseName := fmt.Sprintf("CASE-%s-%s", dateStr, slug)
 	dirName := baseName
-	caseDir := filepath.Join(casesRoot, dirName)
 
-	// Handle collision with -02, -03 suffix.
+	// Handle collision with -02, -03 suffix. IDs are unique across every
+	// group and slot, archived cases included, not just the directory the
+	// case is created in.
 	suffix := 2
-	for cr.fs.Exists(caseDir) {
+	for taken[dirName] || cr.fs.Exists(filepath.Join(groupDir, dirName)) {
 		dirName = fmt.Sprintf("%s-%02d", baseName, suffix)
-		caseDir = filepath.Join(casesRoot, dirName)
 		suffix++
 	}
+	caseDir := filepath.Join(groupDir, dirName)
 
-	if err := cr.fs.EnsureDir(caseDir); err != nil {
-		return nil, fmt.Errorf("create case dir: %w", err)
+	// Build case.md from the type's template, or from the strategy's
+	// schema.md if available. The template's frontmatter supplies defaults;
+	// runtime values override them and its declarations are dropped.
+	fm := NewFrontmatter()
+	body := "# " + dirName + "\n"
+	if tmpl != nil {
+		if fm, body, err = cr.renderTemplate(ctx, tmpl, dirName, slug, now, values); err != nil {
+			return nil, err
+		}
+	} else if cr.strat.SchemaTemplate != "" {
+		if tplFM, tplBody, err := ParseFrontmatter(cr.strat.SchemaTemplate); err == nil {
+			fm = tplFM
+			fm.Delete(strategy.SchemaFieldsKey)
+			body = strings.Replace(tplBody, "# Case Title", "# "+dirName, 1)
+		}
 	}
-
-	// Build case.md content from strategy's SchemaTemplate if available.
-	fm := Frontmatter{
-		Type:      "intake",
-		Status:    cr.sm.Initial(),
-		ClaimedBy: "none",
-		Created:   dateStr,
+	// routing.yaml's default_route type takes precedence over the template's,
+	// and --type over both. --type and --set values are checked against the
+	// declared fields.
+	if cr.strat.Routing.DefaultRoute.Type != "" || fm.GetString(keyType) == "" {
+		fm.Set(keyType, cr.strat.Routing.DefaultType())
 	}
-
-	var content string
-	if cr.strat.SchemaTemplate != "" {
-		// Parse template frontmatter and override with runtime values.
-		tplFM, body, err := ParseFrontmatter(cr.strat.SchemaTemplate)
-		if err == nil {
-			// Use template values as defaults, override with runtime.
-			if tplFM.Type != "" {
-				fm.Type = tplFM.Type
-			}
-			fm.Status = cr.sm.Initial()
-			if tplFM.ClaimedBy != "" && tplFM.ClaimedBy != "none" {
-				fm.ClaimedBy = tplFM.ClaimedBy
-			}
-			fm.Created = dateStr
-
-			// Replace title placeholder.
-			body = strings.Replace(body, "# Case Title", "# "+dirName, 1)
-			content = RenderFrontmatter(fm) + body
-		} else {
-			content = RenderFrontmatter(fm) + "# " + dirName + "\n"
+	sets := in.sets
+	if in.typ != "" {
+		sets = append([]fieldValue{{keyType, in.typ}}, sets...)
+	}
+	if err := cr.applySets(fm, sets); err != nil {
+		return nil, err
+	}
+	fm.Set(keyStatus, cr.sm.Initial())
+	if fm.GetString(keyClaimedBy) == "" {
+		fm.Set(keyClaimedBy, unclaimed)
+	}
+	fm.Set(keyCreated, dateStr)
+	for _, spec := range cr.fieldSpecs() {
+		if spec.Default != nil && !fm.Has(spec.Name) {
+			fm.Set(spec.Name, spec.Default)
 		}
-	} else {
-		content = RenderFrontmatter(fm) + "# " + dirName + "\n"
 	}
+	// --classify picks the type from routing cues before the case is written,
+	// so on-case-open hooks see the final type.
+	var classification string
+	if opts["classify"] == "true" {
+		c := routing.Classify(cr.strat.Routing, routing.Input{
+			Text:   caseTitle(dirName) + "\n" + body,
+			Paths:  stringList(fm.Get(keyPaths)),
+			Labels: stringList(fm.Get(keyLabels)),
+		})
+		fm.Set(keyType, c.Type)
+		classification = "classified " + c.Explain()
+	}
+	content := RenderFrontmatter(fm) + body
 
+	if err := cr.fs.EnsureDir(caseDir); err != nil {
+		return nil, fmt.Errorf("create case dir: %w", err)
+	}
 	caseMDPath := filepath.Join(caseDir, "case.md")
 	if err := cr.fs.WriteFile(caseMDPath, []byte(content), 0o644); err != nil {
 		return nil, fmt.Errorf("write case.md: %w", err)
 	}
+	created := cr.newEvent(ctx, ActionCreate, "", fm.GetString(keyStatus))
+	if created.Note == "" {
+		created.Note = classification
+	}
+	if err := cr.appendEvent(caseDir, created); err != nil {
+		return nil, err
+	}
+
+	// The case exists once case.md is written, so it is committed even when
+	// a blocking on-case-open hook fails.
+	hookErr := cr.fire(ctx, hooks.Event{
+		Hook:    hooks.OnCaseOpen,
+		CaseID:  dirName,
+		CaseDir: caseDir,
+		Type:    fm.GetString(keyType),
+		Status:  fm.GetString(keyStatus),
+		Slot:    cr.currentSlot(ctx),
+	})
+	if err := cr.commit(dirName, created); err != nil {
+		return nil, err
+	}
+	if hookErr != nil {
+		return nil, fmt.Errorf("case %s created: %w", dirName, hookErr)
+	}
 
 	return cr.recordFromFrontmatter(dirName, caseMDPath, fm), nil
 }
 
-// List walks the cases directory and returns matching records.
+// List walks the cases directory, including every {group}/{slot}
+// subdirectory, and returns matching records.
 func (cr *CaseResource) List(ctx *agentops.AppContext, filter resource.Filter) ([]resource.Record, error) {
 	if cr.strat == nil {
-		return nil, fmt.Errorf("no strategy loaded")
+		return nil, strategy.Missing(cr.loadErr)
 	}
 
-	casesRoot, err := cr.casesDir()
+	locs, err := cr.scanCases()
 	if err != nil {
 		return nil, err
 	}
 
-	entries, err := cr.fs.ReadDir(casesRoot)
-	if err != nil {
-		// If directory doesn't exist, return empty list.
-		return nil, nil
-	}
-
 	// Pre-compute status filter if specified.
 	var statusFilter map[string]bool
-	if statusVal, ok := filter["status"]; ok && statusVal != "" {
-		statusFilter, err = cr.sm.ExpandStatusFilter(statusVal)
+	if filter.Status != "" {
+		statusFilter, err = cr.sm.ExpandStatusFilter(filter.Status)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	slotFilter := ""
-	if slot, ok := filter["slot"]; ok {
-		slotFilter = slot
-	}
-
 	var records []resource.Record
-	for _, entry := range entries {
-		if !entry.IsDir || !strings.HasPrefix(entry.Name, "CASE-") {
-			continue
-		}
-
-		caseMDPath := filepath.Join(casesRoot, entry.Name, "case.md")
+	for _, loc := range locs {
+		caseMDPath := filepath.Join(loc.Dir, "case.md")
 		data, err := cr.fs.ReadFile(caseMDPath)
 		if err != nil {
 			continue
 		}
 
-		fm, _, err := ParseFrontmatter(string(data))
+		fm, body, err := ParseFrontmatter(string(data))
 		if err != nil {
 			continue
 		}
 
 		// Apply filters.
-		if statusFilter != nil && !statusFilter[fm.Status] {
+		if statusFilter != nil && !statusFilter[fm.GetString(keyStatus)] {
+			continue
+		}
+		if filter.Slot != "" && fm.GetString(keyClaimedBy) != filter.Slot {
 			continue
 		}
-		if slotFilter != "" && fm.ClaimedBy != slotFilter {
+		rec := cr.recordFromFrontmatter(loc.ID, caseMDPath, fm)
+		fields := rec.Fields
+		if filter.References(keyBody) {
+			// The body is matched on but never returned.
+			fields = maps.Clone(rec.Fields)
+			fields[keyBody] = body
+		}
+		if !filter.Match(fields) {
 			continue
 		}
 
-		records = append(records, *cr.recordFromFrontmatter(entry.Name, caseMDPath, fm))
+		records = append(records, *rec)
 	}
 
-	return records, nil
+	return filter.Order(records), nil
 }
 
-// Get retrieves a case record by its ID.
+// Get retrieves a case record by its ID, with the paths of its sidecars.
 func (cr *CaseResource) Get(ctx *agentops.AppContext, id string) (*resource.Record, error) {
 	if cr.strat == nil {
-		return nil, fmt.Errorf("no strategy loaded")
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	return cr.get(id)
+}
+
+// FindBySlug returns the case created from slug, the one whose ID is
+// CASE-<date>-<slug>, or nil when there is none. More than one match is
+// refused as ambiguous.
+func (cr *CaseResource) FindBySlug(ctx *agentops.AppContext, slug string) (*resource.Record, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	locs, err := cr.scanCases()
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, loc := range locs {
+		if caseSlug(loc.ID) == slug {
+			ids = append(ids, loc.ID)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return nil, nil
+	case 1:
+		return cr.get(ids[0])
 	}
+	return nil, agentops.NewCLIError(agentops.ExitUsage, "ambiguous_case",
+		fmt.Sprintf("slug %q matches %d cases: %s", slug, len(ids), strings.Join(ids, ", ")), nil)
+}
 
+// get reads the case with the given ID.
+func (cr *CaseResource) get(id string) (*resource.Record, error) {
 	caseMDPath, err := cr.findCaseMD(id)
 	if err != nil {
 		return nil, err
@@ -251,13 +374,24 @@ func (cr *CaseResource) Get(ctx *agentops.AppContext, id string) (*resource.Reco
 		return nil, fmt.Errorf("parse frontmatter: %w", err)
 	}
 
-	return cr.recordFromFrontmatter(id, caseMDPath, fm), nil
+	rec := cr.recordFromFrontmatter(id, caseMDPath, fm)
+	sidecars, err := cr.sidecarPaths(filepath.Dir(caseMDPath))
+	if err != nil {
+		return nil, err
+	}
+	if sidecars == nil {
+		sidecars = []string{}
+	}
+	rec.Fields["sidecars"] = sidecars
+	return rec, nil
 }
 
-// Validate checks that a case has all required frontmatter fields.
+// Validate checks that a case has all required frontmatter fields with
+// values of the declared types, and that its sidecars are owned and well
+// formed.
 func (cr *CaseResource) Validate(ctx *agentops.AppContext, id string) (*agentops.DoctorReport, error) {
 	if cr.strat == nil {
-		return nil, fmt.Errorf("no strategy loaded")
+		return nil, strategy.Missing(cr.loadErr)
 	}
 
 	caseMDPath, err := cr.findCaseMD(id)
@@ -286,44 +420,53 @@ func (cr *CaseResource) Validate(ctx *agentops.AppContext, id string) (*agentops
 		return report, nil
 	}
 
-	if fm.Type == "" {
-		report.OK = false
-		report.Findings = append(report.Findings, agentops.DoctorFinding{
-			Code:    "missing_field",
-			Path:    caseMDPath,
-			Message: "missing required field: type",
-		})
+	for _, spec := range cr.fieldSpecs() {
+		if fm.blank(spec.Name) {
+			if spec.Required {
+				report.OK = false
+				report.Findings = append(report.Findings, agentops.DoctorFinding{
+					Code:    "missing_field",
+					Path:    caseMDPath,
+					Message: "missing required field: " + spec.Name,
+				})
+			}
+			continue
+		}
+		if msg := checkField(spec, fm); msg != "" {
+			report.OK = false
+			report.Findings = append(report.Findings, agentops.DoctorFinding{
+				Code:    "invalid_field",
+				Path:    caseMDPath,
+				Message: msg,
+			})
+		}
 	}
-	if fm.Status == "" {
-		report.OK = false
-		report.Findings = append(report.Findings, agentops.DoctorFinding{
-			Code:    "missing_field",
-			Path:    caseMDPath,
-			Message: "missing required field: status",
-		})
+
+	sidecarFindings, err := cr.validateSidecars(filepath.Dir(caseMDPath))
+	if err != nil {
+		return nil, err
 	}
-	if fm.Created == "" {
+	if len(sidecarFindings) > 0 {
 		report.OK = false
-		report.Findings = append(report.Findings, agentops.DoctorFinding{
-			Code:    "missing_field",
-			Path:    caseMDPath,
-			Message: "missing required field: created",
-		})
+		report.Findings = append(report.Findings, sidecarFindings...)
 	}
 
 	return report, nil
 }
 
-// Transition applies a state machine action to a case and returns the updated record.
+// Transition applies a state machine action to a case and returns the updated
+// record. A case claimed by another slot, or one that does not meet the
+// action's guards, is refused with ExitTransitionDenied.
 func (cr *CaseResource) Transition(ctx *agentops.AppContext, id string, action string) (*resource.Record, error) {
 	if cr.strat == nil {
-		return nil, fmt.Errorf("no strategy loaded")
+		return nil, strategy.Missing(cr.loadErr)
 	}
 
-	caseMDPath, err := cr.findCaseMD(id)
+	loc, err := cr.locate(id)
 	if err != nil {
 		return nil, err
 	}
+	caseMDPath := filepath.Join(loc.Dir, "case.md")
 
 	data, err := cr.fs.ReadFile(caseMDPath)
 	if err != nil {
@@ -335,58 +478,114 @@ func (cr *CaseResource) Transition(ctx *agentops.AppContext, id string, action s
 		return nil, fmt.Errorf("parse frontmatter: %w", err)
 	}
 
-	oldCategory := cr.sm.CategoryForStatus(fm.Status)
+	// Only the owning slot may move a claimed case (protocol/slot.md).
+	if err := CheckClaim(id, fm.GetString(keyClaimedBy), cr.currentSlot(ctx)); err != nil {
+		return nil, err
+	}
+
+	oldStatus := fm.GetString(keyStatus)
+	oldCategory := cr.sm.CategoryForStatus(oldStatus)
 
-	newStatus, err := cr.sm.Apply(fm.Status, action)
+	// Guards are checked before hooks fire so a denied transition has no
+	// side effects.
+	subject := &caseSubject{cr: cr, ctx: ctx, caseDir: loc.Dir, fm: fm}
+	newStatus, err := cr.sm.Apply(oldStatus, action, subject)
+	var guardErr *GuardError
+	if errors.As(err, &guardErr) {
+		return nil, agentops.NewCLIError(agentops.ExitTransitionDenied, "guard_failed",
+			fmt.Sprintf("cannot %s %s", action, id), err)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	fm.Status = newStatus
+	newCategory := cr.sm.CategoryForStatus(newStatus)
+
+	// Hooks fire before the new status is persisted so a blocking failure
+	// leaves the case untouched.
+	ev := hooks.Event{
+		CaseID:     id,
+		CaseDir:    filepath.Dir(caseMDPath),
+		Type:       fm.GetString(keyType),
+		Status:     newStatus,
+		FromStatus: oldStatus,
+		ToStatus:   newStatus,
+		Action:     action,
+		Slot:       cr.currentSlot(ctx),
+	}
+	ev.Hook = hooks.OnCaseTransition
+	if err := cr.fire(ctx, ev); err != nil {
+		return nil, err
+	}
+	if newCategory == completedCategory && oldCategory != completedCategory {
+		ev.Hook = hooks.OnCaseClose
+		if err := cr.fire(ctx, ev); err != nil {
+			return nil, err
+		}
+	}
+
+	fm.Set(keyStatus, newStatus)
 	newContent := RenderFrontmatter(fm) + body
 
 	if err := cr.fs.WriteFile(caseMDPath, []byte(newContent), 0o644); err != nil {
 		return nil, fmt.Errorf("write case.md: %w", err)
 	}
 
-	// Check if category changed — for now this is informational.
-	// In separate-repo mode with group subdirs, we would move the directory.
-	// In in-repo mode, cases stay in the same flat directory.
-	newCategory := cr.sm.CategoryForStatus(newStatus)
-	_ = oldCategory
-	_ = newCategory
+	// A status change that crosses storage groups moves the case directory,
+	// keeping its slot. If the move fails the status write is undone so the
+	// directory and the status never disagree.
+	caseDir, err := cr.moveToGroup(loc, newStatus)
+	if err != nil {
+		_ = cr.fs.WriteFile(caseMDPath, data, 0o644)
+		return nil, err
+	}
+	transitioned := cr.newEvent(ctx, action, oldStatus, newStatus)
+	if err := cr.appendEvent(caseDir, transitioned); err != nil {
+		return nil, err
+	}
+	if err := cr.commit(id, transitioned); err != nil {
+		return nil, err
+	}
 
-	return cr.recordFromFrontmatter(id, caseMDPath, fm), nil
+	return cr.recordFromFrontmatter(id, filepath.Join(caseDir, "case.md"), fm), nil
 }
 
-// findCaseMD locates the case.md file for a given case ID.
-func (cr *CaseResource) findCaseMD(id string) (string, error) {
-	casesRoot, err := cr.casesDir()
+// CaseDir returns the directory holding the case with the given ID.
+func (cr *CaseResource) CaseDir(id string) (string, error) {
+	if cr.strat == nil {
+		return "", strategy.Missing(cr.loadErr)
+	}
+	caseMDPath, err := cr.findCaseMD(id)
 	if err != nil {
 		return "", err
 	}
+	return filepath.Dir(caseMDPath), nil
+}
 
-	// Direct lookup in the flat cases directory.
-	caseMDPath := filepath.Join(casesRoot, id, "case.md")
-	if cr.fs.Exists(caseMDPath) {
-		return caseMDPath, nil
+// findCaseMD locates the case.md file for a given case ID in either the
+// grouped or the flat layout.
+func (cr *CaseResource) findCaseMD(id string) (string, error) {
+	loc, err := cr.locate(id)
+	if err != nil {
+		return "", err
 	}
-
-	return "", fmt.Errorf("case %q not found", id)
+	return filepath.Join(loc.Dir, "case.md"), nil
 }
 
 // recordFromFrontmatter builds a Record from a case ID and its frontmatter.
-func (cr *CaseResource) recordFromFrontmatter(id, rawPath string, fm Frontmatter) *resource.Record {
+// Every frontmatter key is included; declared fields the case lacks are "".
+func (cr *CaseResource) recordFromFrontmatter(id, rawPath string, fm *Frontmatter) *resource.Record {
+	fields := fm.Map()
+	for _, spec := range cr.fieldSpecs() {
+		if _, ok := fields[spec.Name]; !ok {
+			fields[spec.Name] = ""
+		}
+	}
+	fields["id"] = id
 	return &resource.Record{
-		Kind: "case",
-		ID:   id,
-		Fields: map[string]any{
-			"id":         id,
-			"type":       fm.Type,
-			"status":     fm.Status,
-			"claimed_by": fm.ClaimedBy,
-			"created":    fm.Created,
-		},
+		Kind:    "case",
+		ID:      id,
+		Fields:  fields,
 		RawPath: rawPath,
 	}
 }
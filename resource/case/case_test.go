@@ -9,6 +9,7 @@ import (
 
 	agentops "github.com/gh-xj/agentops"
 	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/resource"
 	"github.com/gh-xj/agentops/strategy"
 )
 
@@ -93,7 +94,7 @@ func TestCaseResourceCreate(t *testing.T) {
 	}
 
 	// Verify case.md exists on disk
-	caseMDPath := filepath.Join(strat.Root, "cases", rec.ID, "case.md")
+	caseMDPath := filepath.Join(strat.Root, "cases", "active", "unassigned", rec.ID, "case.md")
 	if _, err := os.Stat(caseMDPath); err != nil {
 		t.Errorf("case.md not found at %s: %v", caseMDPath, err)
 	}
@@ -167,7 +168,7 @@ func TestCaseResourceList(t *testing.T) {
 	}
 
 	// List all
-	records, err := cr.List(ctx, nil)
+	records, err := cr.List(ctx, resource.Filter{})
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
@@ -189,7 +190,7 @@ func TestCaseResourceListFilterByStatus(t *testing.T) {
 	}
 
 	// Filter by exact status
-	records, err := cr.List(ctx, map[string]string{"status": "open"})
+	records, err := cr.List(ctx, resource.Filter{Status: "open"})
 	if err != nil {
 		t.Fatalf("List with status filter: %v", err)
 	}
@@ -198,7 +199,7 @@ func TestCaseResourceListFilterByStatus(t *testing.T) {
 	}
 
 	// Filter by status group
-	records, err = cr.List(ctx, map[string]string{"status": "active"})
+	records, err = cr.List(ctx, resource.Filter{Status: "active"})
 	if err != nil {
 		t.Fatalf("List with group filter: %v", err)
 	}
@@ -207,7 +208,7 @@ func TestCaseResourceListFilterByStatus(t *testing.T) {
 	}
 
 	// Filter by non-matching status
-	records, err = cr.List(ctx, map[string]string{"status": "resolved"})
+	records, err = cr.List(ctx, resource.Filter{Status: "resolved"})
 	if err != nil {
 		t.Fatalf("List with resolved filter: %v", err)
 	}
@@ -216,6 +217,59 @@ func TestCaseResourceListFilterByStatus(t *testing.T) {
 	}
 }
 
+func TestCaseResourceListWhere(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	for _, slug := range []string{"flaky-test", "slow-build", "docs-typo"} {
+		rec, err := cr.Create(ctx, slug, nil)
+		if err != nil {
+			t.Fatalf("create %s: %v", slug, err)
+		}
+		if slug == "slow-build" {
+			data, err := os.ReadFile(rec.RawPath)
+			if err != nil {
+				t.Fatalf("read case.md: %v", err)
+			}
+			if err := os.WriteFile(rec.RawPath, append(data, []byte("\nThe build hits a TIMEOUT in CI.\n")...), 0o644); err != nil {
+				t.Fatalf("write case.md: %v", err)
+			}
+		}
+	}
+
+	ids := func(f resource.Filter) []string {
+		t.Helper()
+		records, err := cr.List(ctx, f)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		var out []string
+		for _, r := range records {
+			out = append(out, r.ID)
+			if _, ok := r.Fields["body"]; ok {
+				t.Errorf("%s: body leaked into the record fields", r.ID)
+			}
+		}
+		return out
+	}
+
+	body := resource.Filter{Where: []resource.Predicate{{Field: "body", Op: "~", Value: "timeout"}}}
+	if got := ids(body); len(got) != 1 || !strings.HasSuffix(got[0], "slow-build") {
+		t.Errorf("body~timeout = %v, want only slow-build", got)
+	}
+
+	unclaimed := resource.Filter{
+		Where: []resource.Predicate{{Field: "claimed_by", Op: "=", Value: "none"}},
+		Sort:  []resource.SortKey{{Field: "id", Desc: true}},
+		Limit: 2,
+	}
+	got := ids(unclaimed)
+	if len(got) != 2 || !strings.HasSuffix(got[0], "slow-build") || !strings.HasSuffix(got[1], "flaky-test") {
+		t.Errorf("claimed_by=none sorted by -id, limit 2 = %v, want slow-build, flaky-test", got)
+	}
+}
+
 func TestCaseResourceGet(t *testing.T) {
 	_, strat := setupTestProject(t)
 	fs := dal.NewFileSystem()
@@ -287,7 +341,7 @@ func TestCaseResourceValidateMissingFields(t *testing.T) {
 		t.Fatalf("create: %v", err)
 	}
 
-	caseMDPath := filepath.Join(strat.Root, "cases", created.ID, "case.md")
+	caseMDPath := created.RawPath
 	if err := os.WriteFile(caseMDPath, []byte("---\nstatus: open\n---\n# No type or created\n"), 0o644); err != nil {
 		t.Fatalf("write corrupted case.md: %v", err)
 	}
@@ -389,6 +443,110 @@ func TestCaseResourceTransitionCategoryMove(t *testing.T) {
 	if got.Fields["status"] != "resolved" {
 		t.Errorf("status = %v, want 'resolved'", got.Fields["status"])
 	}
+
+	// The directory moved from active/<slot>/ to completed/<slot>/.
+	wantPath := filepath.Join(strat.Root, "cases", "completed", "unassigned", created.ID, "case.md")
+	if got.RawPath != wantPath {
+		t.Errorf("RawPath = %q, want %q", got.RawPath, wantPath)
+	}
+	if _, err := os.Stat(filepath.Dir(created.RawPath)); !os.IsNotExist(err) {
+		t.Errorf("old case directory still exists: %v", err)
+	}
+}
+
+func TestCaseResourceCreateInSlot(t *testing.T) {
+	tmp, strat := setupTestProject(t)
+	if err := os.WriteFile(filepath.Join(tmp, ".slot"), []byte("agent-1\n"), 0o644); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	rec, err := cr.Create(ctx, "from-slot", nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	want := filepath.Join(strat.Root, "cases", "active", "agent-1", rec.ID, "case.md")
+	if rec.RawPath != want {
+		t.Errorf("RawPath = %q, want %q", rec.RawPath, want)
+	}
+
+	// A case with the same slug in another slot still gets a unique ID.
+	ctx.Values["slot"] = "agent-2"
+	other, err := cr.Create(ctx, "from-slot", nil)
+	if err != nil {
+		t.Fatalf("Create in agent-2: %v", err)
+	}
+	if other.ID == rec.ID {
+		t.Errorf("expected distinct IDs across slots, got %q twice", rec.ID)
+	}
+}
+
+func TestCaseResourceMigrateLayout(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	// Seed a legacy flat store: one open claimed case, one resolved case and
+	// one claimed by a name that is not a slot.
+	flat := map[string]string{
+		"CASE-20250101-open-one":  "---\ntype: intake\nstatus: open\nclaimed_by: agent-1\ncreated: \"20250101\"\n---\n# one\n",
+		"CASE-20250101-done-two":  "---\ntype: intake\nstatus: resolved\nclaimed_by: none\ncreated: \"20250101\"\n---\n# two\n",
+		"CASE-20250101-bad-three": "---\ntype: intake\nstatus: open\nclaimed_by: ../../escape\ncreated: \"20250101\"\n---\n# three\n",
+	}
+	for id, content := range flat {
+		dir := filepath.Join(strat.Root, "cases", id)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "case.md"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Flat cases are readable before migration.
+	records, err := cr.List(ctx, resource.Filter{})
+	if err != nil || len(records) != 3 {
+		t.Fatalf("List before migrate = %d records, err %v", len(records), err)
+	}
+
+	moves, err := cr.MigrateLayout(ctx, false)
+	if err != nil {
+		t.Fatalf("MigrateLayout dry-run: %v", err)
+	}
+	if len(moves) != 3 || moves[0].Moved || moves[1].Moved || moves[2].Moved {
+		t.Fatalf("dry-run moves = %+v", moves)
+	}
+
+	moves, err = cr.MigrateLayout(ctx, true)
+	if err != nil {
+		t.Fatalf("MigrateLayout: %v", err)
+	}
+	for _, m := range moves {
+		if m.ID == "CASE-20250101-bad-three" && (m.Moved || m.To != "" || !strings.Contains(m.Error, "not a valid slot name")) {
+			t.Errorf("invalid claimed_by move = %+v", m)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(strat.Root, "cases", "CASE-20250101-bad-three", "case.md")); err != nil {
+		t.Errorf("case with invalid claimed_by was moved: %v", err)
+	}
+	for id, want := range map[string]string{
+		"CASE-20250101-open-one": filepath.Join(strat.Root, "cases", "active", "agent-1", "CASE-20250101-open-one", "case.md"),
+		"CASE-20250101-done-two": filepath.Join(strat.Root, "cases", "completed", "unassigned", "CASE-20250101-done-two", "case.md"),
+	} {
+		got, err := cr.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("Get %s: %v", id, err)
+		}
+		if got.RawPath != want {
+			t.Errorf("%s RawPath = %q, want %q", id, got.RawPath, want)
+		}
+	}
+
+	moves, err = cr.MigrateLayout(ctx, true)
+	if err != nil || len(moves) != 1 || moves[0].Moved {
+		t.Errorf("second migrate = %+v, err %v; want only the invalid case reported", moves, err)
+	}
 }
 
 func TestCaseResourceNilStrategy(t *testing.T) {
@@ -430,7 +588,7 @@ func TestCaseResourceSeparateRepoStorage(t *testing.T) {
 	// Verify cases dir is under the expected separate repo location
 	expectedBase := filepath.Base(tmp) + "-cases"
 	expectedCasesDir := filepath.Join(filepath.Dir(tmp), expectedBase, "cases")
-	caseMDPath := filepath.Join(expectedCasesDir, rec.ID, "case.md")
+	caseMDPath := filepath.Join(expectedCasesDir, "active", "unassigned", rec.ID, "case.md")
 	if _, err := os.Stat(caseMDPath); err != nil {
 		t.Errorf("case.md not found at expected separate-repo path %s: %v", caseMDPath, err)
 	}
@@ -450,7 +608,7 @@ func TestCaseResourceListFilterBySlot(t *testing.T) {
 	}
 
 	// Manually set claimed_by in case.md
-	caseMDPath := filepath.Join(strat.Root, "cases", created.ID, "case.md")
+	caseMDPath := created.RawPath
 	data, err := os.ReadFile(caseMDPath)
 	if err != nil {
 		t.Fatalf("read case.md: %v", err)
@@ -461,7 +619,7 @@ func TestCaseResourceListFilterBySlot(t *testing.T) {
 	}
 
 	// Filter by slot (claimed_by)
-	records, err := cr.List(ctx, map[string]string{"slot": "agent-1"})
+	records, err := cr.List(ctx, resource.Filter{Slot: "agent-1"})
 	if err != nil {
 		t.Fatalf("List with slot filter: %v", err)
 	}
@@ -470,7 +628,7 @@ func TestCaseResourceListFilterBySlot(t *testing.T) {
 	}
 
 	// Filter by different slot
-	records, err = cr.List(ctx, map[string]string{"slot": "other"})
+	records, err = cr.List(ctx, resource.Filter{Slot: "other"})
 	if err != nil {
 		t.Fatalf("List with other slot filter: %v", err)
 	}
@@ -492,3 +650,230 @@ func TestCaseResourceCreateSlugMaxLength(t *testing.T) {
 		t.Fatal("expected error for slug exceeding 128 chars")
 	}
 }
+
+func TestCaseResourceTransitionBlockingHook(t *testing.T) {
+	tmp, _ := setupTestProject(t)
+	hooksYAML := "on_case_transition:\n  - shell: exit 1\n    blocking: true\n"
+	if err := os.WriteFile(filepath.Join(tmp, ".agentops", "hooks.yaml"), []byte(hooksYAML), 0o644); err != nil {
+		t.Fatalf("write hooks.yaml: %v", err)
+	}
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	created, err := cr.Create(ctx, "hooked", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := cr.Transition(ctx, created.ID, "start"); err == nil {
+		t.Fatal("expected blocking hook failure to abort transition")
+	}
+
+	got, err := cr.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Fields["status"] != "open" {
+		t.Errorf("status = %v, want open (unchanged)", got.Fields["status"])
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(got.RawPath), "hooks.jsonl")); err != nil {
+		t.Errorf("expected hook log sidecar: %v", err)
+	}
+}
+
+func TestCaseResourceHooksReceiveSlot(t *testing.T) {
+	tmp, _ := setupTestProject(t)
+	hook := "  - shell: echo \"$AGENTOPS_HOOK $AGENTOPS_SLOT\" >> slots.log\n"
+	hooksYAML := "on_case_open:\n" + hook + "on_case_transition:\n" + hook
+	if err := os.WriteFile(filepath.Join(tmp, ".agentops", "hooks.yaml"), []byte(hooksYAML), 0o644); err != nil {
+		t.Fatalf("write hooks.yaml: %v", err)
+	}
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+	ctx.Values["slot"] = "agent-1"
+
+	created, err := cr.Create(ctx, "slotted", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := cr.Transition(ctx, created.ID, "start"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(tmp, "slots.log"))
+	if err != nil {
+		t.Fatalf("read hook output: %v", err)
+	}
+	if want := "on-case-open agent-1\non-case-transition agent-1\n"; string(data) != want {
+		t.Errorf("hook events:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestCaseResourceSchemaDeclaredFields(t *testing.T) {
+	tmp, _ := setupTestProject(t)
+	schema := "---\nfields:\n  - {name: type, type: enum, values: [intake, bug], required: true}\n  - {name: risk, type: enum, values: [low, medium, high], default: low}\n  - {name: linear_ref, type: string}\n  - {name: labels, type: list}\ntype: intake\nstatus: open\nclaimed_by: none\ncreated: \"YYYY-MM-DD\"\n---\n# Case Title\n"
+	if err := os.WriteFile(filepath.Join(tmp, ".agentops", "schema.md"), []byte(schema), 0o644); err != nil {
+		t.Fatalf("write schema.md: %v", err)
+	}
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	fieldTypes := make(map[string]string)
+	for _, f := range cr.Schema().Fields {
+		fieldTypes[f.Name] = f.Type
+	}
+	if fieldTypes["risk"] != "enum" || fieldTypes["labels"] != "list" || fieldTypes["type"] != "enum" {
+		t.Errorf("schema fields = %v", fieldTypes)
+	}
+
+	created, err := cr.Create(ctx, "declared", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.Fields["risk"] != "low" {
+		t.Errorf("risk default = %v, want low", created.Fields["risk"])
+	}
+	data, err := os.ReadFile(created.RawPath)
+	if err != nil {
+		t.Fatalf("read case.md: %v", err)
+	}
+	if strings.Contains(string(data), "fields:") {
+		t.Errorf("fields declaration leaked into case.md:\n%s", data)
+	}
+
+	// Hand-edited and undeclared keys survive a transition.
+	content := strings.Replace(string(data), "risk: low\n", "risk: severe\nlinear_ref: ENG-7\nowner_note: keep me\nlabels: db\n", 1)
+	if err := os.WriteFile(created.RawPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("write case.md: %v", err)
+	}
+	if _, err := cr.Transition(ctx, created.ID, "start"); err != nil {
+		t.Fatalf("transition: %v", err)
+	}
+	got, err := cr.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Fields["linear_ref"] != "ENG-7" || got.Fields["owner_note"] != "keep me" {
+		t.Errorf("custom fields lost on transition: %+v", got.Fields)
+	}
+
+	report, err := cr.Validate(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	var invalid []string
+	for _, f := range report.Findings {
+		if f.Code == "invalid_field" {
+			invalid = append(invalid, f.Message)
+		}
+	}
+	if report.OK || len(invalid) != 2 {
+		t.Errorf("expected invalid risk and labels findings, got %+v", report.Findings)
+	}
+}
+
+func TestCaseResourceTransitionGuards(t *testing.T) {
+	tmp, _ := setupTestProject(t)
+	transitions := `categories:
+  active: [open, in_progress, blocked]
+  completed: [resolved, closed_no_action]
+initial: open
+transitions:
+  start:
+    from: open
+    to: in_progress
+  resolve:
+    from: in_progress
+    to: resolved
+    guards:
+      fields: [resolution]
+      sidecars: [findings.md]
+      workers: [review]
+      note: true
+`
+	if err := os.WriteFile(filepath.Join(tmp, ".agentops", "transitions.yaml"), []byte(transitions), 0o644); err != nil {
+		t.Fatalf("write transitions.yaml: %v", err)
+	}
+	skillDir := filepath.Join(tmp, ".claude", "skills", "review")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	skill := "---\nworker-type: review\nsidecar-path: review.json\nsidecar-schema: schema.json\n---\n# review\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skill), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema := `{"type": "object", "required": ["verdict"]}`
+	if err := os.WriteFile(filepath.Join(skillDir, "schema.json"), []byte(schema), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	created, err := cr.Create(ctx, "guarded", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	started, err := cr.Transition(ctx, created.ID, "start")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	_, err = cr.Transition(ctx, created.ID, "resolve")
+	if code := agentops.ResolveExitCode(err); code != agentops.ExitTransitionDenied {
+		t.Fatalf("exit code = %d, want %d (err %v)", code, agentops.ExitTransitionDenied, err)
+	}
+	for _, want := range []string{"resolution", "findings.md", "worker review", "note is required"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	// Meet every guard.
+	caseDir := filepath.Dir(started.RawPath)
+	data, err := os.ReadFile(started.RawPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := strings.Replace(string(data), "status: in_progress\n", "status: in_progress\nresolution: patched\n", 1)
+	if err := os.WriteFile(started.RawPath, []byte(updated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(caseDir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("findings.md", "ok\n")
+	ctx.Values["note"] = "verified in staging"
+
+	// A sidecar that breaks the worker's schema is not a completed worker.
+	write("review.json", `{"summary": "partial"}`+"\n")
+	_, err = cr.Transition(ctx, created.ID, "resolve")
+	if err == nil || !strings.Contains(err.Error(), "worker review has not completed (review.json:") {
+		t.Fatalf("resolve with an invalid sidecar: %v", err)
+	}
+	write("review.json", `{"verdict": "pass"}`+"\n")
+
+	resolved, err := cr.Transition(ctx, created.ID, "resolve")
+	if err != nil {
+		t.Fatalf("resolve with guards met: %v", err)
+	}
+	if resolved.Fields["status"] != "resolved" {
+		t.Errorf("status = %v, want resolved", resolved.Fields["status"])
+	}
+}
@@ -0,0 +1,130 @@
+package caseresource
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/resource"
+	slotresource "github.com/gh-xj/agentops/resource/slot"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// unclaimed is the claimed_by value of a case no slot owns.
+const unclaimed = "none"
+
+// currentSlot returns the slot the command runs in, as slotresource.CurrentSlot
+// finds it from the strategy root. It returns "" outside any slot.
+func (cr *CaseResource) currentSlot(ctx *agentops.AppContext) string {
+	return slotresource.CurrentSlot(ctx, cr.fs, cr.strat.Root)
+}
+
+// Claim marks the case as owned by the current slot and records when. Claiming
+// a case the slot already owns is a no-op; claiming one owned by another slot
+// is refused. Claims hold the queue lock, so a claim and a pick by next never
+// both take the same case.
+func (cr *CaseResource) Claim(ctx *agentops.AppContext, id string) (*resource.Record, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	slot := cr.currentSlot(ctx)
+	if slot == "" {
+		return nil, agentops.NewCLIError(agentops.ExitTransitionDenied, "no_slot",
+			fmt.Sprintf("cannot claim %s: not inside a slot (no slot marker found)", id), nil)
+	}
+	unlock, err := cr.lockQueue()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return cr.claim(ctx, id, slot)
+}
+
+// claim claims the case for slot. The caller holds the queue lock.
+func (cr *CaseResource) claim(ctx *agentops.AppContext, id, slot string) (*resource.Record, error) {
+	return cr.updateClaim(ctx, id, ActionClaim, func(fm *Frontmatter) error {
+		if err := CheckClaim(id, fm.GetString(keyClaimedBy), slot); err != nil {
+			return err
+		}
+		if fm.GetString(keyClaimedBy) != slot {
+			fm.Set(keyClaimedBy, slot)
+			fm.Set(keyClaimedAt, time.Now().UTC().Format(time.RFC3339))
+		}
+		return nil
+	})
+}
+
+// Release gives up the current slot's claim on the case. Releasing an
+// unclaimed case is a no-op; releasing one owned by another slot is refused.
+func (cr *CaseResource) Release(ctx *agentops.AppContext, id string) (*resource.Record, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	slot := cr.currentSlot(ctx)
+	unlock, err := cr.lockQueue()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return cr.updateClaim(ctx, id, ActionRelease, func(fm *Frontmatter) error {
+		if err := CheckClaim(id, fm.GetString(keyClaimedBy), slot); err != nil {
+			return err
+		}
+		fm.Set(keyClaimedBy, unclaimed)
+		fm.Delete(keyClaimedAt)
+		return nil
+	})
+}
+
+// updateClaim rewrites the case's frontmatter after applying update and
+// records the change in the case history. Unchanged claims are not recorded.
+func (cr *CaseResource) updateClaim(ctx *agentops.AppContext, id, action string, update func(*Frontmatter) error) (*resource.Record, error) {
+	loc, err := cr.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	caseMDPath := filepath.Join(loc.Dir, "case.md")
+	data, err := cr.fs.ReadFile(caseMDPath)
+	if err != nil {
+		return nil, fmt.Errorf("read case.md: %w", err)
+	}
+	fm, body, err := ParseFrontmatter(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+	before := fm.GetString(keyClaimedBy)
+	if err := update(fm); err != nil {
+		return nil, err
+	}
+	after := fm.GetString(keyClaimedBy)
+	if before == after {
+		return cr.recordFromFrontmatter(id, caseMDPath, fm), nil
+	}
+	if err := cr.fs.WriteFile(caseMDPath, []byte(RenderFrontmatter(fm)+body), 0o644); err != nil {
+		return nil, fmt.Errorf("write case.md: %w", err)
+	}
+	// Claim events carry the previous and new owner in from/to.
+	ev := cr.newEvent(ctx, action, before, after)
+	if err := cr.appendEvent(loc.Dir, ev); err != nil {
+		return nil, err
+	}
+	if err := cr.commit(id, ev); err != nil {
+		return nil, err
+	}
+	return cr.recordFromFrontmatter(id, caseMDPath, fm), nil
+}
+
+// CheckClaim refuses to proceed when the case is claimed by a slot other than
+// slot. Unclaimed cases pass.
+func CheckClaim(id, claimedBy, slot string) error {
+	if claimedBy == "" || claimedBy == unclaimed || claimedBy == slot {
+		return nil
+	}
+	current := slot
+	if current == "" {
+		current = "no slot"
+	}
+	return agentops.NewCLIError(agentops.ExitTransitionDenied, "claimed_by_other_slot",
+		fmt.Sprintf("case %s is claimed by slot %q (current: %s)", id, claimedBy, current), nil)
+}
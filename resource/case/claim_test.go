@@ -0,0 +1,188 @@
+package caseresource
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/dal"
+)
+
+func TestCaseResourceClaimAndRelease(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+	ctx.Values["slot"] = "agent-1"
+
+	created, err := cr.Create(ctx, "claim-me", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	claimed, err := cr.Claim(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if claimed.Fields["claimed_by"] != "agent-1" {
+		t.Errorf("claimed_by = %v, want agent-1", claimed.Fields["claimed_by"])
+	}
+	claimedAt, _ := claimed.Fields["claimed_at"].(string)
+	if claimedAt == "" {
+		t.Fatal("claimed_at not recorded")
+	}
+
+	// Re-claiming from the same slot keeps the original timestamp.
+	again, err := cr.Claim(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("second Claim: %v", err)
+	}
+	if again.Fields["claimed_at"] != claimedAt {
+		t.Errorf("claimed_at changed on re-claim: %v -> %v", claimedAt, again.Fields["claimed_at"])
+	}
+
+	released, err := cr.Release(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if released.Fields["claimed_by"] != "none" || released.Fields["claimed_at"] != "" {
+		t.Errorf("after release fields = %+v", released.Fields)
+	}
+	data, err := os.ReadFile(released.RawPath)
+	if err != nil {
+		t.Fatalf("read case.md: %v", err)
+	}
+	if strings.Contains(string(data), "claimed_at") {
+		t.Errorf("claimed_at should be removed on release:\n%s", data)
+	}
+}
+
+func TestCaseResourceClaimOtherSlotDenied(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	owner := testCtx()
+	owner.Values["slot"] = "agent-1"
+	other := testCtx()
+	other.Values["slot"] = "agent-2"
+
+	created, err := cr.Create(owner, "contended", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := cr.Claim(owner, created.ID); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+
+	for name, op := range map[string]func() error{
+		"claim":      func() error { _, err := cr.Claim(other, created.ID); return err },
+		"release":    func() error { _, err := cr.Release(other, created.ID); return err },
+		"transition": func() error { _, err := cr.Transition(other, created.ID, "start"); return err },
+	} {
+		err := op()
+		if err == nil {
+			t.Errorf("%s from another slot: expected error", name)
+			continue
+		}
+		if code := agentops.ResolveExitCode(err); code != agentops.ExitTransitionDenied {
+			t.Errorf("%s exit code = %d, want %d", name, code, agentops.ExitTransitionDenied)
+		}
+	}
+
+	got, err := cr.Get(owner, created.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Fields["status"] != "open" || got.Fields["claimed_by"] != "agent-1" {
+		t.Errorf("case changed by denied operations: %+v", got.Fields)
+	}
+
+	// The owning slot can still transition.
+	if _, err := cr.Transition(owner, created.ID, "start"); err != nil {
+		t.Errorf("owner Transition: %v", err)
+	}
+}
+
+func TestCaseResourceClaimOutsideSlot(t *testing.T) {
+	tmp, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	created, err := cr.Create(ctx, "no-slot", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := cr.Claim(ctx, created.ID); err == nil {
+		t.Fatal("expected Claim to fail outside a slot")
+	}
+
+	// The .slot marker at the project root identifies the slot.
+	if err := os.WriteFile(filepath.Join(tmp, ".slot"), []byte("agent-3"), 0o644); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+	claimed, err := cr.Claim(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Claim with marker: %v", err)
+	}
+	if claimed.Fields["claimed_by"] != "agent-3" {
+		t.Errorf("claimed_by = %v, want agent-3", claimed.Fields["claimed_by"])
+	}
+}
+
+func TestCaseResourceMutatorsRefuseOtherSlotsCase(t *testing.T) {
+	root, strat := setupTestProject(t)
+	writeWorker(t, root, "review", "review.json", "")
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	owner := testCtx()
+	owner.Values["slot"] = "agent-1"
+
+	created, err := cr.Claim(owner, mustCreate(t, cr, "owned"))
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	dir := filepath.Dir(created.RawPath)
+	if err := os.WriteFile(filepath.Join(dir, "review.json"), []byte(`{"findings":[{"code":"nil-deref"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(created.RawPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := testCtx()
+	other.Values["slot"] = "agent-2"
+	calls := map[string]func() error{
+		"classify": func() error {
+			_, _, err := cr.Classify(other, created.ID, true)
+			return err
+		},
+		"risk": func() error {
+			_, _, err := cr.AssessRisk(other, created.ID)
+			return err
+		},
+		"reconcile": func() error {
+			_, _, err := cr.Reconcile(other, created.ID)
+			return err
+		},
+	}
+	for name, call := range calls {
+		if code := agentops.ResolveExitCode(call()); code != agentops.ExitTransitionDenied {
+			t.Errorf("%s by another slot: exit code %d, want %d", name, code, agentops.ExitTransitionDenied)
+		}
+	}
+	if after, _ := os.ReadFile(created.RawPath); string(after) != string(before) {
+		t.Errorf("case.md changed:\n%s", after)
+	}
+	if _, _, err := cr.AssessRisk(owner, created.ID); err != nil {
+		t.Errorf("AssessRisk by the owner: %v", err)
+	}
+}
+
+func mustCreate(t *testing.T, cr *CaseResource, slug string) string {
+	t.Helper()
+	rec, err := cr.Create(testCtx(), slug, nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return rec.ID
+}
@@ -0,0 +1,84 @@
+package caseresource
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/resource"
+	"github.com/gh-xj/agentops/routing"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// ActionClassify is the history action of a classification that changed the
+// case type.
+const ActionClassify = "classify"
+
+// Classify matches the case against the strategy's routing cues. When apply is
+// true and the chosen type differs from the case's, the type is written to
+// the frontmatter and recorded in the history; applying to a case claimed by
+// another slot is refused.
+func (cr *CaseResource) Classify(ctx *agentops.AppContext, id string, apply bool) (*routing.Classification, *resource.Record, error) {
+	if cr.strat == nil {
+		return nil, nil, strategy.Missing(cr.loadErr)
+	}
+	loc, err := cr.locate(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	caseMDPath := filepath.Join(loc.Dir, "case.md")
+	data, err := cr.fs.ReadFile(caseMDPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read case.md: %w", err)
+	}
+	fm, body, err := ParseFrontmatter(string(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+	if apply {
+		if err := CheckClaim(id, fm.GetString(keyClaimedBy), cr.currentSlot(ctx)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	c := routing.Classify(cr.strat.Routing, routing.Input{
+		Text:   caseTitle(id) + "\n" + body,
+		Paths:  stringList(fm.Get(keyPaths)),
+		Labels: stringList(fm.Get(keyLabels)),
+	})
+
+	before := fm.GetString(keyType)
+	if apply && c.Type != before {
+		fm.Set(keyType, c.Type)
+		if err := cr.fs.WriteFile(caseMDPath, []byte(RenderFrontmatter(fm)+body), 0o644); err != nil {
+			return nil, nil, fmt.Errorf("write case.md: %w", err)
+		}
+		// Classify events carry the previous and new type in from/to.
+		ev := cr.newEvent(ctx, ActionClassify, before, c.Type)
+		ev.Note = c.Explain()
+		if err := cr.appendEvent(loc.Dir, ev); err != nil {
+			return nil, nil, err
+		}
+		if err := cr.commit(id, ev); err != nil {
+			return nil, nil, err
+		}
+	}
+	return &c, cr.recordFromFrontmatter(id, caseMDPath, fm), nil
+}
+
+// caseTitle turns a case ID into words cues can match: the slug after the
+// CASE-<date>- prefix with hyphens as spaces.
+func caseTitle(id string) string {
+	return strings.ReplaceAll(caseSlug(id), "-", " ")
+}
+
+// caseSlug returns the slug after the CASE-<date>- prefix of a case ID, or
+// the ID itself when it has no such prefix.
+func caseSlug(id string) string {
+	parts := strings.SplitN(id, "-", 3)
+	if len(parts) == 3 {
+		return parts[2]
+	}
+	return id
+}
@@ -0,0 +1,85 @@
+package caseresource
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func TestCaseResourceClassify(t *testing.T) {
+	tmp, _ := setupTestProject(t)
+	routingYAML := `default_route: {type: triage}
+cues:
+  pr: {keywords: [review]}
+  incident: {labels: [sev1]}
+`
+	if err := os.WriteFile(filepath.Join(tmp, ".agentops", "routing.yaml"), []byte(routingYAML), 0o644); err != nil {
+		t.Fatalf("write routing.yaml: %v", err)
+	}
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	plain, err := cr.Create(ctx, "review-login", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if plain.Fields["type"] != "triage" {
+		t.Errorf("type = %v, want default_route type triage", plain.Fields["type"])
+	}
+
+	// Dry run explains but leaves the case alone.
+	c, rec, err := cr.Classify(ctx, plain.ID, false)
+	if err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	if c.Type != "pr" || rec.Fields["type"] != "triage" {
+		t.Errorf("dry run: classification %+v, type %v", c, rec.Fields["type"])
+	}
+
+	c, rec, err = cr.Classify(ctx, plain.ID, true)
+	if err != nil {
+		t.Fatalf("Classify apply: %v", err)
+	}
+	if rec.Fields["type"] != "pr" {
+		t.Errorf("type = %v, want pr", rec.Fields["type"])
+	}
+	events, err := cr.History(ctx, plain.ID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.Action != ActionClassify || last.From != "triage" || last.To != "pr" || last.Note != c.Explain() {
+		t.Errorf("last event = %+v", last)
+	}
+
+	// Applying again is a no-op.
+	if _, _, err := cr.Classify(ctx, plain.ID, true); err != nil {
+		t.Fatalf("Classify again: %v", err)
+	}
+	if again, _ := cr.History(ctx, plain.ID); len(again) != len(events) {
+		t.Errorf("reclassifying appended events: %d -> %d", len(events), len(again))
+	}
+
+	classified, err := cr.Create(ctx, "please-review", map[string]string{"classify": "true"})
+	if err != nil {
+		t.Fatalf("create --classify: %v", err)
+	}
+	if classified.Fields["type"] != "pr" {
+		t.Errorf("type = %v, want pr", classified.Fields["type"])
+	}
+	events, err = cr.History(ctx, classified.ID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(events) != 1 || !strings.Contains(events[0].Note, `keyword "review"`) {
+		t.Errorf("create events = %+v", events)
+	}
+}
@@ -0,0 +1,108 @@
+package caseresource
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gh-xj/agentops/resource"
+	"github.com/gh-xj/agentops/strategy"
+	"gopkg.in/yaml.v3"
+)
+
+// builtinFields are the frontmatter fields every case has. schema.md may
+// redeclare them (e.g. to turn type into an enum) and add its own.
+var builtinFields = []strategy.FieldSpec{
+	{Name: keyType, Type: strategy.FieldString, Required: true},
+	{Name: keyStatus, Type: strategy.FieldString, Required: true},
+	{Name: keyClaimedBy, Type: strategy.FieldString},
+	{Name: keyClaimedAt, Type: strategy.FieldString},
+	{Name: keyCreated, Type: strategy.FieldDate, Required: true},
+}
+
+var datePattern = regexp.MustCompile(`^\d{4}-?\d{2}-?\d{2}$`)
+
+// fieldSpecs returns the case's frontmatter fields: the built-in fields,
+// overridden by any schema.md declaration of the same name, followed by the
+// strategy's additional fields in declaration order.
+func (cr *CaseResource) fieldSpecs() []strategy.FieldSpec {
+	var declared []strategy.FieldSpec
+	if cr.strat != nil {
+		declared = cr.strat.Fields
+	}
+	byName := make(map[string]strategy.FieldSpec, len(declared))
+	for _, f := range declared {
+		byName[f.Name] = f
+	}
+
+	specs := make([]strategy.FieldSpec, 0, len(builtinFields)+len(declared))
+	builtin := make(map[string]bool, len(builtinFields))
+	for _, f := range builtinFields {
+		builtin[f.Name] = true
+		if override, ok := byName[f.Name]; ok {
+			f = override
+		}
+		specs = append(specs, f)
+	}
+	for _, f := range declared {
+		if !builtin[f.Name] {
+			specs = append(specs, f)
+		}
+	}
+	return specs
+}
+
+// schemaFields converts the field specs into resource schema fields.
+func (cr *CaseResource) schemaFields() []resource.FieldDef {
+	fields := []resource.FieldDef{{Name: "id", Type: "string", Required: true}}
+	for _, f := range cr.fieldSpecs() {
+		fields = append(fields, resource.FieldDef{Name: f.Name, Type: f.Type, Required: f.Required})
+	}
+	return fields
+}
+
+// blank reports whether key is absent, null or an empty string.
+func (fm *Frontmatter) blank(key string) bool {
+	node, ok := fm.nodes[key]
+	if !ok {
+		return true
+	}
+	return node.Kind == yaml.ScalarNode && (node.Value == "" || node.ShortTag() == "!!null")
+}
+
+// checkField validates a present field against its declared type and returns
+// a message describing the problem, or "" when the value conforms.
+func checkField(spec strategy.FieldSpec, fm *Frontmatter) string {
+	node := fm.nodes[spec.Name]
+	scalar := node.Kind == yaml.ScalarNode
+	tag := node.ShortTag()
+
+	var ok bool
+	switch spec.Type {
+	case strategy.FieldInt:
+		ok = scalar && tag == "!!int"
+	case strategy.FieldBool:
+		ok = scalar && tag == "!!bool"
+	case strategy.FieldList:
+		ok = node.Kind == yaml.SequenceNode
+	case strategy.FieldDate:
+		ok = scalar && datePattern.MatchString(node.Value)
+	case strategy.FieldEnum:
+		if scalar {
+			for _, v := range spec.Values {
+				if node.Value == v {
+					ok = true
+				}
+			}
+		}
+		if !ok {
+			return fmt.Sprintf("field %s: %q is not one of [%s]", spec.Name, node.Value, strings.Join(spec.Values, ", "))
+		}
+	default:
+		ok = scalar
+	}
+	if !ok {
+		return fmt.Sprintf("field %s: expected %s", spec.Name, spec.Type)
+	}
+	return ""
+}
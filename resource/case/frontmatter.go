@@ -1,24 +1,117 @@
 package caseresource
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
-// Frontmatter represents YAML frontmatter in case.md files.
+// Frontmatter keys the case resource reads and writes itself. Every other key
+// is carried through untouched.
+const (
+	keyType      = "type"
+	keyStatus    = "status"
+	keyClaimedBy = "claimed_by"
+	keyClaimedAt = "claimed_at"
+	keyCreated   = "created"
+)
+
+// Frontmatter is the YAML frontmatter of a case.md file. It is an ordered map
+// so keys added by a strategy or by hand keep their position and formatting
+// when the case is rewritten.
 type Frontmatter struct {
-	Type      string `yaml:"type"`
-	Status    string `yaml:"status"`
-	ClaimedBy string `yaml:"claimed_by"`
-	Created   string `yaml:"created"`
+	keys  []string
+	nodes map[string]*yaml.Node
+}
+
+// NewFrontmatter returns an empty Frontmatter.
+func NewFrontmatter() *Frontmatter {
+	return &Frontmatter{nodes: make(map[string]*yaml.Node)}
+}
+
+// Keys returns the frontmatter keys in document order.
+func (fm *Frontmatter) Keys() []string {
+	return append([]string(nil), fm.keys...)
+}
+
+// Has reports whether key is present.
+func (fm *Frontmatter) Has(key string) bool {
+	_, ok := fm.nodes[key]
+	return ok
+}
+
+// Get returns the decoded value of key, or nil when it is absent. Unquoted
+// timestamps are returned as their literal text rather than time.Time.
+func (fm *Frontmatter) Get(key string) any {
+	node, ok := fm.nodes[key]
+	if !ok {
+		return nil
+	}
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!timestamp" {
+		return node.Value
+	}
+	var v any
+	if err := node.Decode(&v); err != nil {
+		return node.Value
+	}
+	return v
+}
+
+// GetString returns key as a string. Scalars are returned as written; absent
+// keys and non-scalar values return "".
+func (fm *Frontmatter) GetString(key string) string {
+	node, ok := fm.nodes[key]
+	if !ok || node.Kind != yaml.ScalarNode || node.ShortTag() == "!!null" {
+		return ""
+	}
+	return node.Value
+}
+
+// Set assigns key, keeping its position if it already exists and appending it
+// otherwise.
+func (fm *Frontmatter) Set(key string, value any) {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprint(value)}
+	}
+	if fm.nodes == nil {
+		fm.nodes = make(map[string]*yaml.Node)
+	}
+	if _, ok := fm.nodes[key]; !ok {
+		fm.keys = append(fm.keys, key)
+	}
+	fm.nodes[key] = node
+}
+
+// Delete removes key.
+func (fm *Frontmatter) Delete(key string) {
+	if _, ok := fm.nodes[key]; !ok {
+		return
+	}
+	delete(fm.nodes, key)
+	for i, k := range fm.keys {
+		if k == key {
+			fm.keys = append(fm.keys[:i], fm.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Map returns the decoded frontmatter as a plain map.
+func (fm *Frontmatter) Map() map[string]any {
+	m := make(map[string]any, len(fm.keys))
+	for _, k := range fm.keys {
+		m[k] = fm.Get(k)
+	}
+	return m
 }
 
 // ParseFrontmatter extracts YAML frontmatter from case.md content.
 // Returns the parsed frontmatter and the remaining body content.
-func ParseFrontmatter(content string) (Frontmatter, string, error) {
-	var fm Frontmatter
+func ParseFrontmatter(content string) (*Frontmatter, string, error) {
+	fm := NewFrontmatter()
 	if !strings.HasPrefix(content, "---\n") {
 		return fm, content, fmt.Errorf("no YAML frontmatter found")
 	}
@@ -32,21 +125,51 @@ func ParseFrontmatter(content string) (Frontmatter, string, error) {
 	if strings.HasPrefix(body, "\n") {
 		body = body[1:]
 	}
-	if err := yaml.Unmarshal([]byte(yamlBlock), &fm); err != nil {
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(yamlBlock), &doc); err != nil {
 		return fm, content, fmt.Errorf("parse frontmatter: %w", err)
 	}
+	if len(doc.Content) == 0 {
+		return fm, body, nil
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return fm, content, fmt.Errorf("parse frontmatter: expected a mapping, got %s", mapping.ShortTag())
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i].Value
+		if _, ok := fm.nodes[key]; !ok {
+			fm.keys = append(fm.keys, key)
+		}
+		fm.nodes[key] = mapping.Content[i+1]
+	}
 	return fm, body, nil
 }
 
-// RenderFrontmatter renders a Frontmatter as a YAML frontmatter block.
-func RenderFrontmatter(fm Frontmatter) string {
+// RenderFrontmatter renders a Frontmatter as a YAML frontmatter block. Keys
+// are written in order; values keep the style they were parsed with, and new
+// string values are quoted only when YAML would otherwise read them as
+// another type (e.g. created: "20250101").
+func RenderFrontmatter(fm *Frontmatter) string {
 	var b strings.Builder
 	b.WriteString("---\n")
-	b.WriteString("type: " + fm.Type + "\n")
-	b.WriteString("status: " + fm.Status + "\n")
-	b.WriteString("claimed_by: " + fm.ClaimedBy + "\n")
-	// Quote created to prevent YAML date parsing.
-	b.WriteString("created: \"" + fm.Created + "\"\n")
+	if len(fm.keys) > 0 {
+		mapping := &yaml.Node{Kind: yaml.MappingNode}
+		for _, k := range fm.keys {
+			mapping.Content = append(mapping.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k},
+				fm.nodes[k],
+			)
+		}
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(mapping); err == nil {
+			_ = enc.Close()
+			b.Write(buf.Bytes())
+		}
+	}
 	b.WriteString("---\n")
 	return b.String()
 }
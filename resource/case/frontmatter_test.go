@@ -12,17 +12,17 @@ func TestParseFrontmatter(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if fm.Type != "intake" {
-		t.Errorf("Type = %q, want %q", fm.Type, "intake")
+	if fm.GetString("type") != "intake" {
+		t.Errorf("Type = %q, want %q", fm.GetString("type"), "intake")
 	}
-	if fm.Status != "open" {
-		t.Errorf("Status = %q, want %q", fm.Status, "open")
+	if fm.GetString("status") != "open" {
+		t.Errorf("Status = %q, want %q", fm.GetString("status"), "open")
 	}
-	if fm.ClaimedBy != "agent-1" {
-		t.Errorf("ClaimedBy = %q, want %q", fm.ClaimedBy, "agent-1")
+	if fm.GetString("claimed_by") != "agent-1" {
+		t.Errorf("ClaimedBy = %q, want %q", fm.GetString("claimed_by"), "agent-1")
 	}
-	if fm.Created != "20250101" {
-		t.Errorf("Created = %q, want %q", fm.Created, "20250101")
+	if fm.GetString("created") != "20250101" {
+		t.Errorf("Created = %q, want %q", fm.GetString("created"), "20250101")
 	}
 	if !strings.HasPrefix(body, "# Title") {
 		t.Errorf("body should start with '# Title', got %q", body)
@@ -51,18 +51,17 @@ func TestParseFrontmatterEmptyFields(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if fm.Type != "" {
-		t.Errorf("Type = %q, want empty", fm.Type)
+	if fm.GetString("type") != "" {
+		t.Errorf("Type = %q, want empty", fm.GetString("type"))
 	}
 }
 
 func TestRenderFrontmatter(t *testing.T) {
-	fm := Frontmatter{
-		Type:      "intake",
-		Status:    "open",
-		ClaimedBy: "agent-1",
-		Created:   "20250101",
-	}
+	fm := NewFrontmatter()
+	fm.Set("type", "intake")
+	fm.Set("status", "open")
+	fm.Set("claimed_by", "agent-1")
+	fm.Set("created", "20250101")
 
 	rendered := RenderFrontmatter(fm)
 
@@ -87,31 +86,61 @@ func TestRenderFrontmatter(t *testing.T) {
 }
 
 func TestRenderThenParse(t *testing.T) {
-	original := Frontmatter{
-		Type:      "bug",
-		Status:    "in_progress",
-		ClaimedBy: "dev-2",
-		Created:   "20250315",
-	}
+	original := NewFrontmatter()
+	original.Set("type", "bug")
+	original.Set("status", "in_progress")
+	original.Set("claimed_by", "dev-2")
+	original.Set("created", "20250315")
 
 	rendered := RenderFrontmatter(original)
 	parsed, body, err := ParseFrontmatter(rendered + "# Body\n")
 	if err != nil {
 		t.Fatalf("parse after render: %v", err)
 	}
-	if parsed.Type != original.Type {
-		t.Errorf("Type roundtrip: got %q, want %q", parsed.Type, original.Type)
+	if parsed.GetString("type") != original.GetString("type") {
+		t.Errorf("Type roundtrip: got %q, want %q", parsed.GetString("type"), original.GetString("type"))
 	}
-	if parsed.Status != original.Status {
-		t.Errorf("Status roundtrip: got %q, want %q", parsed.Status, original.Status)
+	if parsed.GetString("status") != original.GetString("status") {
+		t.Errorf("Status roundtrip: got %q, want %q", parsed.GetString("status"), original.GetString("status"))
 	}
-	if parsed.ClaimedBy != original.ClaimedBy {
-		t.Errorf("ClaimedBy roundtrip: got %q, want %q", parsed.ClaimedBy, original.ClaimedBy)
+	if parsed.GetString("claimed_by") != original.GetString("claimed_by") {
+		t.Errorf("ClaimedBy roundtrip: got %q, want %q", parsed.GetString("claimed_by"), original.GetString("claimed_by"))
 	}
-	if parsed.Created != original.Created {
-		t.Errorf("Created roundtrip: got %q, want %q", parsed.Created, original.Created)
+	if parsed.GetString("created") != original.GetString("created") {
+		t.Errorf("Created roundtrip: got %q, want %q", parsed.GetString("created"), original.GetString("created"))
 	}
 	if body != "# Body\n" {
 		t.Errorf("body = %q, want %q", body, "# Body\n")
 	}
 }
+
+func TestFrontmatterRoundTripsUnknownKeys(t *testing.T) {
+	content := "---\ntype: intake\nrisk: high\nstatus: open\nlinear-ref: 'ENG-12'\nlabels: [infra, db]\ncreated: \"20250101\"\nnext_action:\n  owner: agent-1\n  due: 2025-02-01\n---\n# Body\n"
+
+	fm, body, err := ParseFrontmatter(content)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	fm.Set("status", "in_progress")
+	fm.Set("claimed_at", "2025-01-02T03:04:05Z")
+
+	want := "---\ntype: intake\nrisk: high\nstatus: in_progress\nlinear-ref: 'ENG-12'\nlabels: [infra, db]\ncreated: \"20250101\"\nnext_action:\n  owner: agent-1\n  due: 2025-02-01\nclaimed_at: \"2025-01-02T03:04:05Z\"\n---\n# Body\n"
+	if got := RenderFrontmatter(fm) + body; got != want {
+		t.Errorf("round trip mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := fm.Get("labels"); len(got.([]any)) != 2 {
+		t.Errorf("labels = %v, want 2 items", got)
+	}
+	if got := fm.Get("claimed_at"); got != "2025-01-02T03:04:05Z" {
+		t.Errorf("claimed_at = %#v, want string", got)
+	}
+
+	fm.Delete("risk")
+	if fm.Has("risk") || strings.Contains(RenderFrontmatter(fm), "risk") {
+		t.Error("risk should be deleted")
+	}
+	if keys := strings.Join(fm.Keys(), ","); keys != "type,status,linear-ref,labels,created,next_action,claimed_at" {
+		t.Errorf("keys = %s", keys)
+	}
+}
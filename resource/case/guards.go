@@ -0,0 +1,60 @@
+package caseresource
+
+import (
+	"path/filepath"
+	"strings"
+
+	agentops "github.com/gh-xj/agentops"
+	workerresource "github.com/gh-xj/agentops/resource/worker"
+)
+
+// caseSubject is the GuardSubject for a case being transitioned.
+type caseSubject struct {
+	cr      *CaseResource
+	ctx     *agentops.AppContext
+	caseDir string
+	fm      *Frontmatter
+	workers *workerresource.Registry
+}
+
+func (s *caseSubject) FieldSet(name string) bool {
+	return !s.fm.blank(name)
+}
+
+func (s *caseSubject) SidecarExists(path string) bool {
+	return s.cr.fs.Exists(filepath.Join(s.caseDir, path))
+}
+
+func (s *caseSubject) WorkerCompleted(name string) (bool, string) {
+	if s.workers == nil {
+		reg, err := workerresource.Load(s.cr.fs, s.cr.strat.Root)
+		if err != nil {
+			return false, ""
+		}
+		s.workers = reg
+	}
+	w, ok := s.workers.Get(name)
+	if !ok {
+		return false, ""
+	}
+	data, err := s.cr.fs.ReadFile(filepath.Join(s.caseDir, w.SidecarPath))
+	if err != nil {
+		return true, "no " + w.SidecarPath
+	}
+	problems, err := w.CheckSidecar(s.cr.fs, data)
+	if err != nil {
+		return true, err.Error()
+	}
+	if len(problems) > 0 {
+		return true, w.SidecarPath + ": " + strings.Join(problems, "; ")
+	}
+	return true, ""
+}
+
+func (s *caseSubject) Note() string {
+	if s.ctx == nil {
+		return ""
+	}
+	note, _ := s.ctx.Values["note"].(string)
+	return note
+}
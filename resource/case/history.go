@@ -0,0 +1,209 @@
+package caseresource
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/hooks"
+	"github.com/gh-xj/agentops/resource"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// EventsFile is the append-only history log kept in every case directory.
+const EventsFile = "events.jsonl"
+
+// Event actions recorded besides transition action names.
+const (
+	ActionCreate  = "create"
+	ActionClaim   = "claim"
+	ActionRelease = "release"
+	ActionHook    = "hook"
+)
+
+// Event is one entry in a case's history. Transitions record the action name
+// (e.g. "start") with the from and to statuses.
+type Event struct {
+	At     time.Time `json:"at"`
+	Actor  string    `json:"actor"`
+	Action string    `json:"action"`
+	From   string    `json:"from,omitempty"`
+	To     string    `json:"to,omitempty"`
+	Note   string    `json:"note,omitempty"`
+}
+
+// EventSchema describes history events for rendering.
+var EventSchema = resource.ResourceSchema{
+	Kind:        "event",
+	Description: "A case history entry",
+	Fields: []resource.FieldDef{
+		{Name: "at", Type: "string", Required: true},
+		{Name: "actor", Type: "string", Required: true},
+		{Name: "action", Type: "string", Required: true},
+		{Name: "from", Type: "string"},
+		{Name: "to", Type: "string"},
+		{Name: "note", Type: "string"},
+	},
+}
+
+// History returns the events recorded for a case, oldest first.
+func (cr *CaseResource) History(ctx *agentops.AppContext, id string) ([]Event, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	loc, err := cr.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	return cr.readEvents(filepath.Join(loc.Dir, EventsFile))
+}
+
+// HistoryRecords returns a case's history as records for cobrax rendering.
+func (cr *CaseResource) HistoryRecords(ctx *agentops.AppContext, id string) ([]resource.Record, error) {
+	events, err := cr.History(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]resource.Record, 0, len(events))
+	for i, ev := range events {
+		records = append(records, resource.Record{
+			Kind: "event",
+			ID:   fmt.Sprintf("%s#%d", id, i+1),
+			Fields: map[string]any{
+				"at":     ev.At.Format(time.RFC3339),
+				"actor":  ev.Actor,
+				"action": ev.Action,
+				"from":   ev.From,
+				"to":     ev.To,
+				"note":   ev.Note,
+			},
+		})
+	}
+	return records, nil
+}
+
+// RecordHooks appends one history event per hook outcome.
+func (cr *CaseResource) RecordHooks(ctx *agentops.AppContext, caseDir string, outcomes []hooks.Outcome) error {
+	for _, o := range outcomes {
+		result := "ok"
+		if !o.OK {
+			result = fmt.Sprintf("failed, exit %d", o.ExitCode)
+		}
+		ev := cr.newEvent(ctx, ActionHook, "", "")
+		ev.Note = fmt.Sprintf("%s %s %q: %s", o.Hook, o.Kind, o.Command, result)
+		if err := cr.appendEvent(caseDir, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fire runs the hooks bound to ev.Hook and records each outcome in the case
+// history. A blocking hook failure is returned after its outcome is recorded.
+func (cr *CaseResource) fire(ctx *agentops.AppContext, ev hooks.Event) error {
+	outcomes, err := cr.hooks.Fire(ev)
+	if recErr := cr.RecordHooks(ctx, ev.CaseDir, outcomes); recErr != nil && err == nil {
+		err = recErr
+	}
+	return err
+}
+
+// newEvent builds an event stamped with the current time and actor. The note
+// comes from the "note" context value, set by the --note flag.
+func (cr *CaseResource) newEvent(ctx *agentops.AppContext, action, from, to string) Event {
+	ev := Event{
+		At:     time.Now().UTC(),
+		Actor:  cr.actor(ctx),
+		Action: action,
+		From:   from,
+		To:     to,
+	}
+	if ctx != nil {
+		if note, ok := ctx.Values["note"].(string); ok {
+			ev.Note = note
+		}
+	}
+	return ev
+}
+
+// NoteContext returns a copy of ctx with its note replaced, so the history
+// event of the next mutation records note.
+func NoteContext(ctx *agentops.AppContext, note string) *agentops.AppContext {
+	c := *ctx
+	c.Values = make(map[string]any, len(ctx.Values)+1)
+	for k, v := range ctx.Values {
+		c.Values[k] = v
+	}
+	c.Values["note"] = note
+	return &c
+}
+
+// actor identifies who made a change: an explicit "actor" context value, the
+// current slot, or the OS user.
+func (cr *CaseResource) actor(ctx *agentops.AppContext) string {
+	if ctx != nil {
+		if v, ok := ctx.Values["actor"].(string); ok && v != "" {
+			return v
+		}
+	}
+	if slot := cr.currentSlot(ctx); slot != "" {
+		return "slot:" + slot
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return "user:" + u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return "user:" + name
+	}
+	return "unknown"
+}
+
+// appendEvent adds ev to the end of the case's history log.
+func (cr *CaseResource) appendEvent(caseDir string, ev Event) error {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(caseDir, EventsFile)
+	existing, _ := cr.fs.ReadFile(path)
+	if err := cr.fs.WriteFile(path, append(existing, append(line, '\n')...), 0o644); err != nil {
+		return fmt.Errorf("record %s event: %w", ev.Action, err)
+	}
+	return nil
+}
+
+// readEvents parses a history log. A missing log is an empty history.
+func (cr *CaseResource) readEvents(path string) ([]Event, error) {
+	data, err := cr.fs.ReadFile(path)
+	if err != nil {
+		return nil, nil
+	}
+	var events []Event
+	sc := bufio.NewScanner(strings.NewReader(string(data)))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		ev, err := parseEvent(line)
+		if err != nil {
+			return events, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// parseEvent decodes one history log line.
+func parseEvent(line string) (Event, error) {
+	var ev Event
+	err := json.Unmarshal([]byte(line), &ev)
+	return ev, err
+}
@@ -0,0 +1,123 @@
+package caseresource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func TestCaseResourceHistory(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+	ctx.Values["slot"] = "agent-1"
+
+	created, err := cr.Create(ctx, "tracked", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := cr.Claim(ctx, created.ID); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	// Re-claiming changes nothing and is not recorded.
+	if _, err := cr.Claim(ctx, created.ID); err != nil {
+		t.Fatalf("second Claim: %v", err)
+	}
+	ctx.Values["note"] = "picked up"
+	if _, err := cr.Transition(ctx, created.ID, "start"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	delete(ctx.Values, "note")
+	if _, err := cr.Release(ctx, created.ID); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	events, err := cr.History(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	want := []Event{
+		{Actor: "slot:agent-1", Action: ActionCreate, To: "open"},
+		{Actor: "slot:agent-1", Action: ActionClaim, From: "none", To: "agent-1"},
+		{Actor: "slot:agent-1", Action: "start", From: "open", To: "in_progress", Note: "picked up"},
+		{Actor: "slot:agent-1", Action: ActionRelease, From: "agent-1", To: "none"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, ev := range events {
+		if ev.At.IsZero() {
+			t.Errorf("event %d has no timestamp", i)
+		}
+		ev.At = want[i].At
+		if ev != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, ev, want[i])
+		}
+	}
+}
+
+func TestCaseResourceHistoryFollowsCategoryMove(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+	ctx.Values["actor"] = "user:tester"
+
+	created, err := cr.Create(ctx, "moving", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := cr.Transition(ctx, created.ID, "start"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	resolved, err := cr.Transition(ctx, created.ID, "resolve")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(resolved.RawPath), EventsFile)); err != nil {
+		t.Fatalf("events.jsonl not moved with the case: %v", err)
+	}
+
+	records, err := cr.HistoryRecords(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("HistoryRecords: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	last := records[2].Fields
+	if last["action"] != "resolve" || last["to"] != "resolved" || last["actor"] != "user:tester" {
+		t.Errorf("last record = %+v", last)
+	}
+}
+
+func TestCaseResourceHistoryRecordsHooks(t *testing.T) {
+	tmp, _ := setupTestProject(t)
+	hooksYAML := "on_case_open:\n  - shell: \"true\"\n"
+	if err := os.WriteFile(filepath.Join(tmp, ".agentops", "hooks.yaml"), []byte(hooksYAML), 0o644); err != nil {
+		t.Fatalf("write hooks.yaml: %v", err)
+	}
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	created, err := cr.Create(ctx, "hooked", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	events, err := cr.History(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(events) != 2 || events[1].Action != ActionHook {
+		t.Fatalf("events = %+v, want create then hook", events)
+	}
+	if events[1].Note != `on-case-open shell "true": ok` {
+		t.Errorf("hook note = %q", events[1].Note)
+	}
+}
@@ -0,0 +1,255 @@
+package caseresource
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	agentops "github.com/gh-xj/agentops"
+	slotresource "github.com/gh-xj/agentops/resource/slot"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// unassignedSlot is the slot segment for cases created outside any slot.
+const unassignedSlot = "unassigned"
+
+// ArchiveGroup is the storage group removed cases are moved to. Archived
+// cases keep their slot directory and history but are no longer listed.
+const ArchiveGroup = strategy.ArchiveCategory
+
+// caseLocation is where a case directory lives in the store. Group and Slot
+// are empty for cases still in the legacy flat layout.
+type caseLocation struct {
+	ID    string
+	Dir   string
+	Group string
+	Slot  string
+}
+
+// flat reports whether the case is stored directly under cases/.
+func (l caseLocation) flat() bool {
+	return l.Group == ""
+}
+
+// LayoutMove describes one case directory moved by MigrateLayout.
+type LayoutMove struct {
+	ID    string `json:"id"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Moved bool   `json:"moved"`
+	Error string `json:"error,omitempty"`
+}
+
+// scanCases finds every case in the store, both in the grouped
+// {group}/{slot}/CASE-* layout and in the legacy flat CASE-* layout. Groups
+// and slots are discovered from the directory tree rather than configured.
+// Archived cases are not included. Results are sorted by ID.
+func (cr *CaseResource) scanCases() ([]caseLocation, error) {
+	casesRoot, err := cr.casesDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := cr.fs.ReadDir(casesRoot)
+	if err != nil {
+		return nil, nil
+	}
+
+	var locs []caseLocation
+	for _, group := range entries {
+		if !group.IsDir || strings.HasPrefix(group.Name, ".") || group.Name == ArchiveGroup {
+			continue
+		}
+		if isCaseDir(group.Name) {
+			locs = append(locs, caseLocation{ID: group.Name, Dir: filepath.Join(casesRoot, group.Name)})
+			continue
+		}
+		locs = append(locs, cr.scanGroup(casesRoot, group.Name)...)
+	}
+	sort.Slice(locs, func(i, j int) bool { return locs[i].ID < locs[j].ID })
+	return locs, nil
+}
+
+// scanArchive finds every archived case.
+func (cr *CaseResource) scanArchive() ([]caseLocation, error) {
+	casesRoot, err := cr.casesDir()
+	if err != nil {
+		return nil, err
+	}
+	return cr.scanGroup(casesRoot, ArchiveGroup), nil
+}
+
+// scanGroup finds the cases in every slot directory of one group.
+func (cr *CaseResource) scanGroup(casesRoot, group string) []caseLocation {
+	groupDir := filepath.Join(casesRoot, group)
+	slots, err := cr.fs.ReadDir(groupDir)
+	if err != nil {
+		return nil
+	}
+	var locs []caseLocation
+	for _, slot := range slots {
+		if !slot.IsDir || strings.HasPrefix(slot.Name, ".") || isCaseDir(slot.Name) {
+			continue
+		}
+		slotDir := filepath.Join(groupDir, slot.Name)
+		cases, err := cr.fs.ReadDir(slotDir)
+		if err != nil {
+			continue
+		}
+		for _, c := range cases {
+			if !c.IsDir || !isCaseDir(c.Name) {
+				continue
+			}
+			locs = append(locs, caseLocation{
+				ID:    c.Name,
+				Dir:   filepath.Join(slotDir, c.Name),
+				Group: group,
+				Slot:  slot.Name,
+			})
+		}
+	}
+	return locs
+}
+
+// locate returns the location of the case with the given ID.
+func (cr *CaseResource) locate(id string) (caseLocation, error) {
+	locs, err := cr.scanCases()
+	if err != nil {
+		return caseLocation{}, err
+	}
+	for _, loc := range locs {
+		if loc.ID == id && cr.fs.Exists(filepath.Join(loc.Dir, "case.md")) {
+			return loc, nil
+		}
+	}
+	return caseLocation{}, fmt.Errorf("case %q not found", id)
+}
+
+// groupDir returns the {group}/{slot} directory new cases with the given
+// status are created in.
+func (cr *CaseResource) groupDir(status, slot string) (string, error) {
+	casesRoot, err := cr.casesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(casesRoot, cr.groupFor(status), slot), nil
+}
+
+// groupFor returns the storage group for a status: its category, or the
+// initial status's category for statuses outside every category.
+func (cr *CaseResource) groupFor(status string) string {
+	if cat := cr.sm.CategoryForStatus(status); cat != "" {
+		return cat
+	}
+	if cat := cr.sm.CategoryForStatus(cr.sm.Initial()); cat != "" {
+		return cat
+	}
+	return "active"
+}
+
+// creatorSlot returns the slot a new case is filed under: the current slot,
+// or "unassigned" outside any slot.
+func (cr *CaseResource) creatorSlot(ctx *agentops.AppContext) string {
+	if slot := cr.currentSlot(ctx); slot != "" {
+		return slot
+	}
+	return unassignedSlot
+}
+
+// moveToGroup moves a grouped case into the group for status, preserving its
+// slot. Flat cases are left in place until migrated. It returns the case's
+// directory after the move.
+func (cr *CaseResource) moveToGroup(loc caseLocation, status string) (string, error) {
+	group := cr.groupFor(status)
+	if loc.flat() || loc.Group == group {
+		return loc.Dir, nil
+	}
+	dest, err := cr.groupDir(status, loc.Slot)
+	if err != nil {
+		return "", err
+	}
+	return cr.moveCase(loc, filepath.Join(dest, loc.ID))
+}
+
+// moveCase renames a case directory to dest. The rename is a single atomic
+// filesystem operation so the case is never visible in two places.
+func (cr *CaseResource) moveCase(loc caseLocation, dest string) (string, error) {
+	if cr.fs.Exists(dest) {
+		return "", fmt.Errorf("move case %s: %s already exists", loc.ID, dest)
+	}
+	if err := cr.fs.EnsureDir(filepath.Dir(dest)); err != nil {
+		return "", fmt.Errorf("move case %s: %w", loc.ID, err)
+	}
+	if err := cr.fs.Rename(loc.Dir, dest); err != nil {
+		return "", fmt.Errorf("move case %s: %w", loc.ID, err)
+	}
+	return dest, nil
+}
+
+// MigrateLayout moves cases stored in the legacy flat layout into
+// {group}/{slot}/. The group follows each case's status and the slot its
+// claimed_by field, falling back to "unassigned"; a case whose claimed_by is
+// not a valid slot name is reported and left in place. Nothing is moved
+// unless confirm is true.
+func (cr *CaseResource) MigrateLayout(ctx *agentops.AppContext, confirm bool) ([]LayoutMove, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	locs, err := cr.scanCases()
+	if err != nil {
+		return nil, err
+	}
+
+	var moves []LayoutMove
+	for _, loc := range locs {
+		if !loc.flat() {
+			continue
+		}
+		move := LayoutMove{ID: loc.ID, From: loc.Dir}
+		data, err := cr.fs.ReadFile(filepath.Join(loc.Dir, "case.md"))
+		if err != nil {
+			move.Error = fmt.Sprintf("read case.md: %v", err)
+			moves = append(moves, move)
+			continue
+		}
+		fm, _, err := ParseFrontmatter(string(data))
+		if err != nil {
+			move.Error = fmt.Sprintf("parse frontmatter: %v", err)
+			moves = append(moves, move)
+			continue
+		}
+
+		slot := fm.GetString(keyClaimedBy)
+		if slot == "" || slot == unclaimed {
+			slot = unassignedSlot
+		} else if !slotresource.ValidName(slot) {
+			move.Error = fmt.Sprintf("claimed_by %q is not a valid slot name: must match ^[a-z][a-z0-9-]*$", slot)
+			moves = append(moves, move)
+			continue
+		}
+		dir, err := cr.groupDir(fm.GetString(keyStatus), slot)
+		if err != nil {
+			return nil, err
+		}
+		move.To = filepath.Join(dir, loc.ID)
+		if confirm {
+			if _, err := cr.moveCase(loc, move.To); err != nil {
+				move.Error = err.Error()
+			} else {
+				move.Moved = true
+			}
+		}
+		moves = append(moves, move)
+	}
+	if confirm && len(moves) > 0 {
+		if err := cr.commit("", cr.newEvent(ctx, "migrate-layout", "", "")); err != nil {
+			return moves, err
+		}
+	}
+	return moves, nil
+}
+
+// isCaseDir reports whether a directory name is a case ID.
+func isCaseDir(name string) bool {
+	return strings.HasPrefix(name, "CASE-")
+}
@@ -0,0 +1,172 @@
+package caseresource
+
+import (
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"path/filepath"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/queue"
+	"github.com/gh-xj/agentops/resource"
+	slotresource "github.com/gh-xj/agentops/resource/slot"
+	"github.com/gh-xj/agentops/risk"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// keyPriority is the frontmatter field the queue ranks by first.
+const keyPriority = "priority"
+
+// The queue lock serializes picks so two slots never claim the same case.
+const (
+	queueLockFile    = "agentops-queue.lock"
+	queueLockTimeout = 10 * time.Second
+	queueLockPoll    = 50 * time.Millisecond
+)
+
+// NextResult is the outcome of Next.
+type NextResult struct {
+	Slot    string           `json:"slot"`
+	Claimed bool             `json:"claimed"`
+	Case    *resource.Record `json:"case"`           // nil when the queue is empty
+	Rank    *queue.Ranked    `json:"rank,omitempty"` // why Case was picked
+	Queue   []queue.Ranked   `json:"queue"`          // every eligible case, best first
+}
+
+// Next ranks the unclaimed cases in the strategy's queue statuses by the
+// queue.yaml weights and, when claim is set, claims the best one for the
+// current slot. Ranking and claiming hold an exclusive lock on the case store.
+func (cr *CaseResource) Next(ctx *agentops.AppContext, claim bool) (*NextResult, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	slot := cr.currentSlot(ctx)
+	if claim && slot == "" {
+		return nil, agentops.NewCLIError(agentops.ExitTransitionDenied, "no_slot",
+			"cannot pick a case: not inside a slot (no slot marker found)", nil)
+	}
+
+	if claim {
+		if err := cr.initStore(); err != nil {
+			return nil, err
+		}
+		unlock, err := cr.lockQueue()
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	ranked, err := cr.rankQueue()
+	if err != nil {
+		return nil, err
+	}
+	result := &NextResult{Slot: slot, Queue: ranked}
+	if len(ranked) == 0 {
+		return result, nil
+	}
+	top := ranked[0]
+	result.Rank = &top
+	if !claim {
+		result.Case, err = cr.Get(ctx, top.ID)
+		return result, err
+	}
+	if result.Case, err = cr.claim(ctx, top.ID, slot); err != nil {
+		return nil, err
+	}
+	result.Claimed = true
+	return result, nil
+}
+
+// rankQueue returns the unclaimed cases in the queue statuses, best first.
+func (cr *CaseResource) rankQueue() ([]queue.Ranked, error) {
+	eligible := make(map[string]bool)
+	for _, s := range cr.strat.Queue.EligibleStatuses() {
+		statuses, err := cr.sm.ExpandStatusFilter(s)
+		if err != nil {
+			return nil, fmt.Errorf("queue.yaml statuses: %w", err)
+		}
+		for status := range statuses {
+			eligible[status] = true
+		}
+	}
+	for _, s := range cr.strat.Queue.ExcludedStatuses() {
+		delete(eligible, s)
+	}
+
+	locs, err := cr.scanCases()
+	if err != nil {
+		return nil, err
+	}
+	var candidates []queue.Candidate
+	for _, loc := range locs {
+		data, err := cr.fs.ReadFile(filepath.Join(loc.Dir, "case.md"))
+		if err != nil {
+			continue
+		}
+		fm, _, err := ParseFrontmatter(string(data))
+		if err != nil {
+			continue
+		}
+		if !eligible[fm.GetString(keyStatus)] {
+			continue
+		}
+		if owner := fm.GetString(keyClaimedBy); owner != "" && owner != unclaimed {
+			continue
+		}
+		level := fm.GetString(keyRisk)
+		if level == "" && cr.strat.Risk.Configured() {
+			level = risk.Assess(cr.strat.Risk, riskInput(fm)).Level
+		}
+		candidates = append(candidates, queue.Candidate{
+			ID:        loc.ID,
+			Priority:  fm.Get(keyPriority),
+			RiskLevel: level,
+			Created:   fm.Get(keyCreated),
+		})
+	}
+	return queue.Rank(cr.strat.Queue, cr.strat.Risk, candidates, time.Now().UTC()), nil
+}
+
+// lockQueue takes the queue lock, waiting up to queueLockTimeout for another
+// slot's pick or claim to finish. Every slot must find the same lock, so it
+// lives in the git directory of the shared store: the case repository, or the
+// main repository for in-repo cases. Commits never pick it up there. Without
+// a git directory it falls back to the cases directory.
+func (cr *CaseResource) lockQueue() (func(), error) {
+	var dir string
+	var err error
+	if cr.strat.Storage.Backend == "in-repo" {
+		dir, err = slotresource.FindRepoRoot(cr.fs, cr.strat.Root)
+	} else {
+		dir, err = cr.repoDir()
+	}
+	lockDir := filepath.Join(dir, ".git")
+	if _, statErr := cr.fs.ReadDir(lockDir); err != nil || statErr != nil {
+		if lockDir, err = cr.casesDir(); err != nil {
+			return nil, err
+		}
+		if err := cr.fs.EnsureDir(lockDir); err != nil {
+			return nil, fmt.Errorf("create cases directory: %w", err)
+		}
+	}
+
+	lockPath := filepath.Join(lockDir, queueLockFile)
+	deadline := time.Now().Add(queueLockTimeout)
+	for {
+		err := cr.fs.CreateExclusive(lockPath, 0o600)
+		if err == nil {
+			return func() {
+				_ = cr.fs.Remove(lockPath)
+			}, nil
+		}
+		if !errors.Is(err, iofs.ErrExist) {
+			return nil, fmt.Errorf("create queue lock %q: %w", lockPath, err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("acquire queue lock %q: timeout after %s (remove it if no pick is running)", lockPath, queueLockTimeout)
+		}
+		time.Sleep(queueLockPoll)
+	}
+}
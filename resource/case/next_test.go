@@ -0,0 +1,215 @@
+package caseresource
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gh-xj/agentops/dal"
+	slotresource "github.com/gh-xj/agentops/resource/slot"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func TestCaseResourceNext(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	low, err := cr.Create(ctx, "low", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	high, err := cr.Create(ctx, "high", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	data, err := os.ReadFile(high.RawPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(high.RawPath, []byte(strings.Replace(string(data), "status: open\n", "status: open\npriority: high\n", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Blocked cases are not workable, so the default queue skips them.
+	blocked, err := cr.Create(ctx, "blocked", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	data, err = os.ReadFile(blocked.RawPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(blocked.RawPath, []byte(strings.Replace(string(data), "status: open\n", "status: blocked\npriority: critical\n", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	preview, err := cr.Next(ctx, false)
+	if err != nil {
+		t.Fatalf("Next dry run: %v", err)
+	}
+	if preview.Claimed || preview.Case == nil || preview.Case.ID != high.ID || len(preview.Queue) != 2 {
+		t.Fatalf("preview = %+v", preview)
+	}
+
+	if _, err := cr.Next(ctx, true); err == nil || !strings.Contains(err.Error(), "no_slot") {
+		t.Errorf("Next outside a slot: err = %v, want no_slot", err)
+	}
+
+	ctx.Values["slot"] = "agent-1"
+	got, err := cr.Next(ctx, true)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if !got.Claimed || got.Case.ID != high.ID || got.Case.Fields["claimed_by"] != "agent-1" {
+		t.Fatalf("Next = %+v", got)
+	}
+
+	// The claimed case leaves the queue.
+	got, err = cr.Next(ctx, true)
+	if err != nil {
+		t.Fatalf("second Next: %v", err)
+	}
+	if got.Case == nil || got.Case.ID != low.ID || len(got.Queue) != 1 {
+		t.Fatalf("second Next = %+v", got)
+	}
+	got, err = cr.Next(ctx, true)
+	if err != nil || got.Case != nil || got.Claimed || len(got.Queue) != 0 {
+		t.Fatalf("Next on an empty queue = %+v, %v", got, err)
+	}
+}
+
+func TestCaseResourceNextConcurrentSlots(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	const cases = 8
+	for i := range cases {
+		if _, err := cr.Create(testCtx(), fmt.Sprintf("case-%d", i), nil); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	slots := make([]string, cases+4)
+	for i := range slots {
+		slots[i] = fmt.Sprintf("s%d", i)
+	}
+	picks := make([]*NextResult, len(slots))
+	errs := make([]error, len(slots))
+	var wg sync.WaitGroup
+	for i, slot := range slots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := testCtx()
+			ctx.Values["slot"] = slot
+			picks[i], errs[i] = New(dal.NewFileSystem(), dal.NewExecutor(), strat).Next(ctx, true)
+		}()
+	}
+	wg.Wait()
+
+	owners := make(map[string]string)
+	empty := 0
+	for i, slot := range slots {
+		if errs[i] != nil {
+			t.Fatalf("slot %s: %v", slot, errs[i])
+		}
+		if picks[i].Case == nil {
+			empty++
+			continue
+		}
+		id := picks[i].Case.ID
+		if prev, ok := owners[id]; ok {
+			t.Errorf("case %s picked by both %s and %s", id, prev, slot)
+		}
+		owners[id] = slot
+	}
+	if len(owners) != cases || empty != len(slots)-cases {
+		t.Errorf("owners = %v, empty picks = %d", owners, empty)
+	}
+}
+
+func TestCaseResourceNextWaitsForQueueLock(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	if _, err := cr.Create(testCtx(), "only", nil); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	unlock, err := cr.lockQueue()
+	if err != nil {
+		t.Fatalf("lockQueue: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		ctx := testCtx()
+		ctx.Values["slot"] = "agent-1"
+		_, err := cr.Next(ctx, true)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("Next finished while another pick held the lock (err %v)", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next did not finish after the lock was released")
+	}
+}
+
+func TestCaseResourceNextFromSlotWorktree(t *testing.T) {
+	parent := t.TempDir()
+	mainStrat := setupSeparateRepoProject(t, parent, "proj", "")
+	root := mainStrat.Root
+	gitOut(t, root, "init", "-b", "main")
+	gitOut(t, root, "add", "-A")
+	gitOut(t, root, "-c", "user.name=test", "-c", "user.email=test@test.com", "commit", "-m", "init")
+
+	fs := dal.NewFileSystem()
+	main := New(fs, dal.NewExecutor(), mainStrat)
+	created, err := main.Create(testCtx(), "shared", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	slotCtx := testCtx()
+	slotCtx.Values["project_dir"] = root
+	slot, err := slotresource.New(fs, dal.NewExecutor()).Create(slotCtx, "agent-1", nil)
+	if err != nil {
+		t.Fatalf("create slot: %v", err)
+	}
+	wtStrat, err := strategy.Discover(slot.Fields["path"].(string))
+	if err != nil {
+		t.Fatalf("discover in worktree: %v", err)
+	}
+	wt := New(fs, dal.NewExecutor(), wtStrat)
+
+	// The worktree shares the main checkout's lock and store.
+	unlock, err := wt.lockQueue()
+	if err != nil {
+		t.Fatalf("lockQueue: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "proj-cases", ".git", queueLockFile)); err != nil {
+		t.Errorf("worktree lock is not in the shared case repository: %v", err)
+	}
+	unlock()
+
+	got, err := wt.Next(testCtx(), true)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if got.Slot != "agent-1" || !got.Claimed || got.Case == nil || got.Case.ID != created.ID {
+		t.Fatalf("Next from the worktree = %+v", got)
+	}
+	if rec, err := main.Get(testCtx(), created.ID); err != nil || rec.Fields["claimed_by"] != "agent-1" {
+		t.Errorf("claim not visible from the main checkout: %v, %v", rec, err)
+	}
+}
@@ -0,0 +1,225 @@
+package caseresource
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/reconcile"
+	"github.com/gh-xj/agentops/resource"
+	"github.com/gh-xj/agentops/sidecar"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// ReconcileReportFile is the sidecar Reconcile writes its report to.
+const ReconcileReportFile = "reconcile.json"
+
+// ActionReconcile is the history action of a reconciliation.
+const ActionReconcile = "reconcile"
+
+// FindingsSection is the case.md section reconciled findings are kept in.
+const FindingsSection = "Findings"
+
+// findingLine matches a finding as Reconcile writes it:
+// "- [severity] code: message (source: worker)".
+var findingLine = regexp.MustCompile(`^[-*+] \[([^\]\s]+)\] (\S+?)(?:: (.*))? \(source: ([^()\s]+)\)$`)
+
+// Reconcile merges the findings and status recommendations in the case's
+// worker sidecars into case.md and writes a report to ReconcileReportFile.
+// Findings are deduplicated by code and worker: one already in the Findings
+// section is updated in place, and lines Reconcile did not write are kept.
+// The first reconcile rule in routing.yaml that matches and is allowed from
+// the case's status is then applied. The record is nil when no sidecar holds
+// worker output; nothing is written then. Cases claimed by another slot are
+// refused.
+func (cr *CaseResource) Reconcile(ctx *agentops.AppContext, id string) (*reconcile.Report, *resource.Record, error) {
+	if cr.strat == nil {
+		return nil, nil, strategy.Missing(cr.loadErr)
+	}
+	loc, err := cr.locate(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	rep, err := cr.collectOutputs(loc.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	rep.CaseID = id
+	if len(rep.Sources) == 0 {
+		return rep, nil, nil
+	}
+
+	caseMDPath := filepath.Join(loc.Dir, "case.md")
+	data, err := cr.fs.ReadFile(caseMDPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read case.md: %w", err)
+	}
+	fm, body, err := ParseFrontmatter(string(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+	if err := CheckClaim(id, fm.GetString(keyClaimedBy), cr.currentSlot(ctx)); err != nil {
+		return nil, nil, err
+	}
+	b := ParseBody(body)
+	existing := ""
+	if s, ok := b.Section(FindingsSection); ok {
+		existing = s.Text()
+	}
+	var merged string
+	merged, rep.Added, rep.Updated = mergeFindings(existing, rep.Findings)
+	if rep.Added+rep.Updated > 0 {
+		b.Set(FindingsSection, merged)
+		if err := cr.fs.WriteFile(caseMDPath, []byte(RenderFrontmatter(fm)+b.Render()), 0o644); err != nil {
+			return nil, nil, fmt.Errorf("write case.md: %w", err)
+		}
+	}
+
+	status := fm.GetString(keyStatus)
+	rule, ok := reconcile.Decide(cr.strat.Routing.Reconcile, rep.Findings, rep.Recommendations, func(action string) bool {
+		_, err := cr.sm.target(status, action)
+		return err == nil
+	})
+	if ok {
+		rep.Rule, rep.Transition, rep.From = rule.Name, rule.Transition, status
+		rep.To, _ = cr.sm.target(status, rule.Transition)
+	}
+
+	report, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cr.fs.WriteFile(filepath.Join(loc.Dir, ReconcileReportFile), append(report, '\n'), 0o644); err != nil {
+		return nil, nil, fmt.Errorf("write reconcile report: %w", err)
+	}
+	ev := cr.newEvent(ctx, ActionReconcile, "", "")
+	ev.Note = fmt.Sprintf("%d finding(s) from %s: %d added, %d updated",
+		len(rep.Findings), strings.Join(rep.Sources, ", "), rep.Added, rep.Updated)
+	if err := cr.appendEvent(loc.Dir, ev); err != nil {
+		return nil, nil, err
+	}
+	if err := cr.commit(id, ev); err != nil {
+		return nil, nil, err
+	}
+	rec := cr.recordFromFrontmatter(id, caseMDPath, fm)
+
+	if rep.Transition != "" {
+		rec, err = cr.Transition(NoteContext(ctx, "reconcile: "+rep.Rule), id, rep.Transition)
+		if err != nil {
+			return rep, nil, fmt.Errorf("reconcile rule %s: %w", rep.Rule, err)
+		}
+	}
+	return rep, rec, nil
+}
+
+// collectOutputs reads every worker-owned sidecar in caseDir. Sidecars that
+// are not structured, or hold neither findings nor a recommendation, are
+// passed over; worker output that does not parse fails the reconciliation.
+func (cr *CaseResource) collectOutputs(caseDir string) (*reconcile.Report, error) {
+	owners, err := cr.loadSidecarOwners()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := cr.sidecarPaths(caseDir)
+	if err != nil {
+		return nil, err
+	}
+	rep := &reconcile.Report{
+		ReconciledAt: time.Now().UTC(),
+		Sources:      []string{},
+		Findings:     []reconcile.Finding{},
+	}
+	for _, rel := range paths {
+		w, ok := owners.worker(rel)
+		if !ok {
+			continue
+		}
+		data, err := cr.fs.ReadFile(filepath.Join(caseDir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, fmt.Errorf("read sidecar: %w", err)
+		}
+		v, structured, err := sidecar.Decode(rel, data)
+		if !structured {
+			continue
+		}
+		var out reconcile.Output
+		if err == nil {
+			out, err = reconcile.Parse(w.Name, v)
+		}
+		if err != nil {
+			return nil, agentops.NewCLIError(agentops.ExitValidationFailed, "invalid_sidecar",
+				fmt.Sprintf("sidecar %s of worker %s", rel, w.Name), err)
+		}
+		if len(out.Findings) == 0 && out.Recommendation == nil {
+			continue
+		}
+		rep.Sources = append(rep.Sources, rel)
+		rep.Findings = append(rep.Findings, out.Findings...)
+		if out.Recommendation != nil {
+			rep.Recommendations = append(rep.Recommendations, *out.Recommendation)
+		}
+	}
+	rep.Findings = reconcile.Dedupe(rep.Findings)
+	return rep, nil
+}
+
+// mergeFindings folds findings into the text of the Findings section. A line
+// holding a finding with the same code and worker is rewritten; other lines
+// are kept, and new findings are added at the end.
+func mergeFindings(text string, findings []reconcile.Finding) (merged string, added, updated int) {
+	pending := make(map[reconcile.Key]reconcile.Finding, len(findings))
+	for _, f := range findings {
+		pending[f.Key()] = f
+	}
+	var lines []string
+	if text != "" {
+		lines = strings.Split(text, "\n")
+	}
+	for i, line := range lines {
+		old, ok := parseFindingLine(line)
+		if !ok {
+			continue
+		}
+		f, ok := pending[old.Key()]
+		if !ok {
+			continue
+		}
+		delete(pending, old.Key())
+		if f != old {
+			lines[i] = formatFinding(f)
+			updated++
+		}
+	}
+	for _, f := range findings {
+		if _, ok := pending[f.Key()]; ok {
+			if n := len(lines); n > 0 && lines[n-1] != "" && !isListItem(lines[n-1]) {
+				lines = append(lines, "") // start the list as its own paragraph
+			}
+			lines = append(lines, formatFinding(f))
+			added++
+		}
+	}
+	return strings.Join(lines, "\n"), added, updated
+}
+
+// formatFinding renders a finding as one list item of the Findings section.
+func formatFinding(f reconcile.Finding) string {
+	line := fmt.Sprintf("- [%s] %s", f.Severity, f.Code)
+	if f.Message != "" {
+		line += ": " + f.Message
+	}
+	return line + fmt.Sprintf(" (source: %s)", f.Source)
+}
+
+// parseFindingLine reads a line formatFinding wrote.
+func parseFindingLine(line string) (reconcile.Finding, bool) {
+	m := findingLine.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return reconcile.Finding{}, false
+	}
+	return reconcile.Finding{Severity: m[1], Code: m[2], Message: m[3], Source: m[4]}, true
+}
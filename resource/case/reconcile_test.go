@@ -0,0 +1,94 @@
+package caseresource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func TestCaseResourceReconcile(t *testing.T) {
+	root, strat := setupTestProject(t)
+	writeWorker(t, root, "review", "review.json", "")
+	writeWorker(t, root, "lint", "lint.yaml", "")
+	strat.Routing.Reconcile = strategy.ReconcileConfig{Rules: []strategy.ReconcileRule{
+		{Name: "follow", When: strategy.ReconcileMatch{Recommend: []string{"in_progress"}}, Transition: "start"},
+	}}
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+
+	created, err := cr.Create(testCtx(), "reconcile", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id := created.ID
+	dir := filepath.Dir(created.RawPath)
+
+	rep, rec, err := cr.Reconcile(testCtx(), id)
+	if err != nil || rec != nil || len(rep.Sources) != 0 {
+		t.Fatalf("Reconcile without output = %+v, %v, %v", rep, rec, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ReconcileReportFile)); !os.IsNotExist(err) {
+		t.Errorf("report written without worker output")
+	}
+
+	if _, err := cr.SetSection(testCtx(), id, FindingsSection, "Reproduced locally."); err != nil {
+		t.Fatalf("set findings: %v", err)
+	}
+	write := func(name, data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("review.json", `{"findings":[{"code":"nil-deref","message":"x may be nil","severity":"error"},{"code":"naming"}]}`)
+	write("lint.yaml", "findings:\n  - code: naming\n    severity: warning\nrecommend: in_progress\n")
+
+	rep, rec, err = cr.Reconcile(testCtx(), id)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if rep.Added != 3 || rep.Updated != 0 || len(rep.Sources) != 2 || rep.Rule != "follow" || rep.To != "in_progress" {
+		t.Errorf("report = %+v", rep)
+	}
+	if rec.Fields["status"] != "in_progress" {
+		t.Errorf("status = %v, want in_progress", rec.Fields["status"])
+	}
+	want := "Reproduced locally.\n\n" +
+		"- [warning] naming (source: lint)\n" +
+		"- [error] nil-deref: x may be nil (source: review)\n" +
+		"- [info] naming (source: review)"
+	if got, _ := cr.GetSection(testCtx(), id, FindingsSection); got != want {
+		t.Errorf("findings = %q, want %q", got, want)
+	}
+
+	write("review.json", `{"findings":[{"code":"nil-deref","message":"x is nil on retry","severity":"error"}]}`)
+	rep, _, err = cr.Reconcile(testCtx(), id)
+	if err != nil {
+		t.Fatalf("second Reconcile: %v", err)
+	}
+	if rep.Added != 0 || rep.Updated != 1 || rep.Transition != "" {
+		t.Errorf("second report = %+v", rep)
+	}
+	want = "Reproduced locally.\n\n" +
+		"- [warning] naming (source: lint)\n" +
+		"- [error] nil-deref: x is nil on retry (source: review)\n" +
+		"- [info] naming (source: review)"
+	if got, _ := cr.GetSection(testCtx(), id, FindingsSection); got != want {
+		t.Errorf("findings = %q, want %q", got, want)
+	}
+
+	events, err := cr.History(testCtx(), id)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if last := events[len(events)-1]; last.Action != ActionReconcile {
+		t.Errorf("last event = %+v, want reconcile", last)
+	}
+
+	write("review.json", `{"findings":[{"message":"no code"}]}`)
+	if _, _, err := cr.Reconcile(testCtx(), id); err == nil {
+		t.Error("Reconcile accepted a finding without a code")
+	}
+}
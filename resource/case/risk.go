@@ -0,0 +1,125 @@
+package caseresource
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/resource"
+	"github.com/gh-xj/agentops/risk"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// Frontmatter keys the risk assessment reads and writes.
+const (
+	keyRisk      = "risk"
+	keyRiskScore = "risk_score"
+	keyPaths     = "paths"
+	keyLabels    = "labels"
+)
+
+// ActionAssessRisk is the history action of a risk assessment.
+const ActionAssessRisk = "assess-risk"
+
+// AssessRisk scores the case against the strategy's risk rules and writes the
+// resulting level and score into its frontmatter. When the level escalates to
+// a transition that is allowed from the case's status, the transition is
+// applied. Workers required by the escalation are returned for the caller to
+// run. Cases claimed by another slot are refused.
+func (cr *CaseResource) AssessRisk(ctx *agentops.AppContext, id string) (*risk.Assessment, *resource.Record, error) {
+	if cr.strat == nil {
+		return nil, nil, strategy.Missing(cr.loadErr)
+	}
+	loc, err := cr.locate(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	caseMDPath := filepath.Join(loc.Dir, "case.md")
+	data, err := cr.fs.ReadFile(caseMDPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read case.md: %w", err)
+	}
+	fm, body, err := ParseFrontmatter(string(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+	if err := CheckClaim(id, fm.GetString(keyClaimedBy), cr.currentSlot(ctx)); err != nil {
+		return nil, nil, err
+	}
+
+	a := risk.Assess(cr.strat.Risk, riskInput(fm))
+
+	before := fm.GetString(keyRisk)
+	if before != a.Level || fm.GetString(keyRiskScore) != fmt.Sprint(a.Score) {
+		fm.Set(keyRisk, a.Level)
+		fm.Set(keyRiskScore, a.Score)
+		if err := cr.fs.WriteFile(caseMDPath, []byte(RenderFrontmatter(fm)+body), 0o644); err != nil {
+			return nil, nil, fmt.Errorf("write case.md: %w", err)
+		}
+		// Risk events carry the previous and new level in from/to.
+		ev := cr.newEvent(ctx, ActionAssessRisk, before, a.Level)
+		ev.Note = fmt.Sprintf("score %d", a.Score)
+		if len(a.Matched) > 0 {
+			ev.Note += ": " + strings.Join(a.Matched, ", ")
+		}
+		if err := cr.appendEvent(loc.Dir, ev); err != nil {
+			return nil, nil, err
+		}
+		if err := cr.commit(id, ev); err != nil {
+			return nil, nil, err
+		}
+	}
+	rec := cr.recordFromFrontmatter(id, caseMDPath, fm)
+
+	if a.Transition != "" {
+		if _, err := cr.sm.target(fm.GetString(keyStatus), a.Transition); err != nil {
+			// Already past the escalation (e.g. blocked twice); nothing to force.
+			a.Transition = ""
+			return &a, rec, nil
+		}
+		escCtx := escalationContext(ctx, a.Level)
+		rec, err = cr.Transition(escCtx, id, a.Transition)
+		if err != nil {
+			return &a, nil, fmt.Errorf("risk escalation %s: %w", a.Transition, err)
+		}
+	}
+	return &a, rec, nil
+}
+
+// riskInput collects what the risk rules match against from a case's
+// frontmatter.
+func riskInput(fm *Frontmatter) risk.Input {
+	return risk.Input{
+		Type:   fm.GetString(keyType),
+		Paths:  stringList(fm.Get(keyPaths)),
+		Labels: stringList(fm.Get(keyLabels)),
+		Fields: fm.Map(),
+	}
+}
+
+// escalationContext returns a copy of ctx whose note explains a forced
+// transition, so the caller's own note is not reused for it.
+func escalationContext(ctx *agentops.AppContext, level string) *agentops.AppContext {
+	return NoteContext(ctx, "risk escalation: "+level)
+}
+
+// stringList converts a frontmatter list (or single scalar) to strings.
+func stringList(v any) []string {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, e := range t {
+			out = append(out, fmt.Sprint(e))
+		}
+		return out
+	case string:
+		if t == "" {
+			return nil
+		}
+		return []string{t}
+	}
+	return []string{fmt.Sprint(v)}
+}
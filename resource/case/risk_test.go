@@ -0,0 +1,92 @@
+package caseresource
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func TestCaseResourceAssessRisk(t *testing.T) {
+	tmp, _ := setupTestProject(t)
+	riskYAML := `thresholds: {medium: 3, high: 6}
+rules:
+  - {name: auth, score: 5, match: {paths: ["internal/auth/**"]}}
+  - {name: security, score: 2, match: {labels: [security]}}
+escalation:
+  medium: {workers: [review]}
+  high: {transition: block}
+`
+	if err := os.WriteFile(filepath.Join(tmp, ".agentops", "risk.yaml"), []byte(riskYAML), 0o644); err != nil {
+		t.Fatalf("write risk.yaml: %v", err)
+	}
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	created, err := cr.Create(ctx, "risky", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Nothing matches yet: low, no escalation.
+	a, rec, err := cr.AssessRisk(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("AssessRisk: %v", err)
+	}
+	if a.Level != "low" || rec.Fields["risk"] != "low" || rec.Fields["status"] != "open" {
+		t.Fatalf("assessment = %+v, fields = %+v", a, rec.Fields)
+	}
+
+	data, err := os.ReadFile(rec.RawPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := strings.Replace(string(data), "status: open\n",
+		"status: open\npaths: [internal/auth/login.go]\nlabels: [security]\n", 1)
+	if err := os.WriteFile(rec.RawPath, []byte(updated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a, rec, err = cr.AssessRisk(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("AssessRisk: %v", err)
+	}
+	if a.Level != "high" || a.Score != 7 || a.Transition != "block" {
+		t.Errorf("assessment = %+v", a)
+	}
+	if strings.Join(a.Workers, ",") != "review" {
+		t.Errorf("workers = %v, want [review]", a.Workers)
+	}
+	if rec.Fields["risk"] != "high" || rec.Fields["risk_score"] != 7 || rec.Fields["status"] != "blocked" {
+		t.Errorf("fields = %+v", rec.Fields)
+	}
+
+	events, err := cr.History(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.Action != "block" || last.Note != "risk escalation: high" {
+		t.Errorf("last event = %+v", last)
+	}
+
+	// Re-assessing an already escalated case changes nothing.
+	before := len(events)
+	a, _, err = cr.AssessRisk(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("AssessRisk again: %v", err)
+	}
+	if a.Transition != "" {
+		t.Errorf("transition forced twice: %+v", a)
+	}
+	if events, _ = cr.History(ctx, created.ID); len(events) != before {
+		t.Errorf("unchanged assessment recorded %d new events", len(events)-before)
+	}
+}
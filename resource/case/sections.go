@@ -0,0 +1,283 @@
+package caseresource
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/resource"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// Event actions recorded for section edits. The section name is recorded in
+// the event's to field.
+const (
+	ActionSectionSet    = "section-set"
+	ActionSectionAppend = "section-append"
+)
+
+// Body is a case.md body split at its level-2 ("## ") headings. The text
+// before the first heading and each section are kept as written, so
+// rendering a Body returns its input byte for byte until a section is
+// changed. Headings inside fenced code blocks are not section breaks.
+type Body struct {
+	Preamble string // the title and anything else before the first section
+	Sections []Section
+}
+
+// Section is one "## Name" heading and the text up to the next one.
+type Section struct {
+	Name    string // heading text
+	Heading string // heading line as written, without its trailing newline
+	Content string // everything after the heading line
+}
+
+// Text returns the section content without surrounding blank lines.
+func (s Section) Text() string {
+	return strings.Trim(s.Content, "\n")
+}
+
+// ParseBody splits a case.md body into sections.
+func ParseBody(body string) *Body {
+	b := &Body{}
+	var cur *Section
+	var text strings.Builder
+	flush := func() {
+		if cur == nil {
+			b.Preamble = text.String()
+		} else {
+			cur.Content = text.String()
+			b.Sections = append(b.Sections, *cur)
+		}
+		text.Reset()
+	}
+	fence := ""
+	for _, line := range strings.SplitAfter(body, "\n") {
+		if line == "" {
+			continue
+		}
+		trimmed := strings.TrimRight(line, "\r\n")
+		switch {
+		case fence != "":
+			if strings.HasPrefix(strings.TrimSpace(trimmed), fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(strings.TrimSpace(trimmed), "```"):
+			fence = "```"
+		case strings.HasPrefix(strings.TrimSpace(trimmed), "~~~"):
+			fence = "~~~"
+		case strings.HasPrefix(trimmed, "## "):
+			flush()
+			cur = &Section{Name: strings.TrimSpace(trimmed[3:]), Heading: strings.TrimSuffix(line, "\n")}
+			continue
+		}
+		text.WriteString(line)
+	}
+	flush()
+	return b
+}
+
+// Render reassembles the body.
+func (b *Body) Render() string {
+	var out strings.Builder
+	out.WriteString(b.Preamble)
+	for _, s := range b.Sections {
+		out.WriteString(s.Heading)
+		out.WriteString("\n")
+		out.WriteString(s.Content)
+	}
+	return out.String()
+}
+
+// Section returns the first section named name, compared
+// case-insensitively.
+func (b *Body) Section(name string) (*Section, bool) {
+	for i := range b.Sections {
+		if strings.EqualFold(b.Sections[i].Name, name) {
+			return &b.Sections[i], true
+		}
+	}
+	return nil, false
+}
+
+// Set replaces the text of section name, adding the section at the end of
+// the body when it is missing.
+func (b *Body) Set(name, text string) {
+	s, ok := b.Section(name)
+	if !ok {
+		b.Sections = append(b.Sections, Section{Name: name, Heading: "## " + name})
+		s = &b.Sections[len(b.Sections)-1]
+		b.separateFrom(len(b.Sections) - 1)
+	}
+	text = strings.Trim(text, "\n")
+	switch {
+	case text == "":
+		s.Content = "\n"
+	case s == &b.Sections[len(b.Sections)-1]:
+		s.Content = "\n" + text + "\n"
+	default:
+		s.Content = "\n" + text + "\n\n"
+	}
+}
+
+// Append adds text at the end of section name, creating it when missing.
+// List items join the list they follow; anything else starts a new
+// paragraph.
+func (b *Body) Append(name, text string) {
+	text = strings.Trim(text, "\n")
+	existing := ""
+	if s, ok := b.Section(name); ok {
+		existing = s.Text()
+	}
+	switch {
+	case existing == "":
+		b.Set(name, text)
+	case text == "":
+		return
+	case isListItem(lastLine(existing)) && isListItem(text):
+		b.Set(name, existing+"\n"+text)
+	default:
+		b.Set(name, existing+"\n\n"+text)
+	}
+}
+
+// separateFrom ends whatever precedes section i with a blank line.
+func (b *Body) separateFrom(i int) {
+	prev := &b.Preamble
+	if i > 0 {
+		prev = &b.Sections[i-1].Content
+	}
+	switch {
+	case i == 0 && *prev == "":
+	case strings.HasSuffix(*prev, "\n\n"):
+	case strings.HasSuffix(*prev, "\n"):
+		*prev += "\n"
+	default:
+		*prev += "\n\n"
+	}
+}
+
+func lastLine(s string) string {
+	return s[strings.LastIndex(s, "\n")+1:]
+}
+
+// isListItem reports whether line starts a markdown list item.
+func isListItem(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, marker := range []string{"- ", "* ", "+ "} {
+		if strings.HasPrefix(line, marker) {
+			return true
+		}
+	}
+	digits := len(line) - len(strings.TrimLeft(line, "0123456789"))
+	return digits > 0 && (strings.HasPrefix(line[digits:], ". ") || strings.HasPrefix(line[digits:], ") "))
+}
+
+// GetSection returns the text of a case.md section.
+func (cr *CaseResource) GetSection(ctx *agentops.AppContext, id, name string) (string, error) {
+	if cr.strat == nil {
+		return "", strategy.Missing(cr.loadErr)
+	}
+	caseMDPath, err := cr.findCaseMD(id)
+	if err != nil {
+		return "", err
+	}
+	data, err := cr.fs.ReadFile(caseMDPath)
+	if err != nil {
+		return "", fmt.Errorf("read case.md: %w", err)
+	}
+	_, body, err := ParseFrontmatter(string(data))
+	if err != nil {
+		return "", fmt.Errorf("parse frontmatter: %w", err)
+	}
+	s, ok := ParseBody(body).Section(name)
+	if !ok {
+		return "", agentops.NewCLIError(agentops.ExitUsage, "section_not_found",
+			fmt.Sprintf("case %s has no %q section", id, name), nil)
+	}
+	return s.Text(), nil
+}
+
+// SetSection replaces the text of a case.md section, adding the section when
+// it is missing. Everything else in case.md is kept as written. Text that
+// would start another section is refused (see checkSectionText).
+func (cr *CaseResource) SetSection(ctx *agentops.AppContext, id, name, text string) (*resource.Record, error) {
+	if err := checkSectionText(text); err != nil {
+		return nil, err
+	}
+	return cr.updateSection(ctx, id, ActionSectionSet, name, func(b *Body) { b.Set(name, text) })
+}
+
+// AppendSection adds text to the end of a case.md section, adding the
+// section when it is missing. Text that would start another section is
+// refused (see checkSectionText).
+func (cr *CaseResource) AppendSection(ctx *agentops.AppContext, id, name, text string) (*resource.Record, error) {
+	if err := checkSectionText(text); err != nil {
+		return nil, err
+	}
+	return cr.updateSection(ctx, id, ActionSectionAppend, name, func(b *Body) { b.Append(name, text) })
+}
+
+// checkSectionText rejects section text that would change the body's
+// structure: a "## " line outside a code fence starts a new section, and a
+// fence left open hides every heading after it.
+func checkSectionText(text string) error {
+	// A heading appended to text is its only section unless text adds one
+	// of its own or swallows it in an open fence.
+	switch len(ParseBody(text + "\n## end\n").Sections) {
+	case 1:
+		return nil
+	case 0:
+		return agentops.NewCLIError(agentops.ExitUsage, "usage",
+			"section text leaves a code fence open", nil)
+	default:
+		return agentops.NewCLIError(agentops.ExitUsage, "usage",
+			`section text must not contain a level-2 heading (a line starting with "## "); use "###" or indent it`, nil)
+	}
+}
+
+// updateSection rewrites case.md's body after applying update and records
+// the edit in the case history. Cases claimed by another slot are refused.
+func (cr *CaseResource) updateSection(ctx *agentops.AppContext, id, action, name string, update func(*Body)) (*resource.Record, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Contains(name, "\n") {
+		return nil, agentops.NewCLIError(agentops.ExitUsage, "usage", fmt.Sprintf("invalid section name %q", name), nil)
+	}
+	loc, err := cr.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	caseMDPath := filepath.Join(loc.Dir, "case.md")
+	data, err := cr.fs.ReadFile(caseMDPath)
+	if err != nil {
+		return nil, fmt.Errorf("read case.md: %w", err)
+	}
+	fm, body, err := ParseFrontmatter(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+	if err := CheckClaim(id, fm.GetString(keyClaimedBy), cr.currentSlot(ctx)); err != nil {
+		return nil, err
+	}
+
+	b := ParseBody(body)
+	update(b)
+	if s, ok := b.Section(name); ok {
+		name = s.Name // record the heading as written
+	}
+	if err := cr.fs.WriteFile(caseMDPath, []byte(RenderFrontmatter(fm)+b.Render()), 0o644); err != nil {
+		return nil, fmt.Errorf("write case.md: %w", err)
+	}
+	ev := cr.newEvent(ctx, action, "", name)
+	if err := cr.appendEvent(loc.Dir, ev); err != nil {
+		return nil, err
+	}
+	if err := cr.commit(id, ev); err != nil {
+		return nil, err
+	}
+	return cr.recordFromFrontmatter(id, caseMDPath, fm), nil
+}
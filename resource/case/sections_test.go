@@ -0,0 +1,183 @@
+package caseresource
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/dal"
+)
+
+const sectionsBody = `# Flaky login test
+
+Some intro text.
+
+## Findings
+
+- timeout in CI
+
+` + "```" + `md
+## Not a section
+` + "```" + `
+
+## Next Action
+
+Rerun with -race.
+`
+
+func TestParseBodyRoundTrip(t *testing.T) {
+	b := ParseBody(sectionsBody)
+	if got := b.Render(); got != sectionsBody {
+		t.Errorf("Render changed an unedited body:\n%s", got)
+	}
+	var names []string
+	for _, s := range b.Sections {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, "|") != "Findings|Next Action" {
+		t.Errorf("sections = %v, want Findings and Next Action (fenced headings ignored)", names)
+	}
+	if !strings.HasPrefix(b.Preamble, "# Flaky login test") {
+		t.Errorf("preamble = %q", b.Preamble)
+	}
+}
+
+func TestBodySetAndAppend(t *testing.T) {
+	b := ParseBody(sectionsBody)
+	b.Append("findings", "- retries hide the race")
+	b.Append("Findings", "Root cause still unknown.")
+	b.Set("Next Action", "Add a deadline to the login call.")
+	b.Set("Close Criteria", "Green for a week.")
+
+	want := `# Flaky login test
+
+Some intro text.
+
+## Findings
+
+- timeout in CI
+
+` + "```" + `md
+## Not a section
+` + "```" + `
+
+- retries hide the race
+
+Root cause still unknown.
+
+## Next Action
+
+Add a deadline to the login call.
+
+## Close Criteria
+
+Green for a week.
+`
+	if got := b.Render(); got != want {
+		t.Errorf("Render =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCaseResourceSections(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	owner := testCtx()
+	owner.Values["slot"] = "agent-1"
+
+	rec, err := cr.Create(owner, "sections", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	before, err := os.ReadFile(rec.RawPath)
+	if err != nil {
+		t.Fatalf("read case.md: %v", err)
+	}
+
+	if _, err := cr.AppendSection(owner, rec.ID, "Findings", "- first"); err != nil {
+		t.Fatalf("AppendSection: %v", err)
+	}
+	if _, err := cr.AppendSection(owner, rec.ID, "findings", "- second"); err != nil {
+		t.Fatalf("AppendSection: %v", err)
+	}
+	got, err := cr.GetSection(owner, rec.ID, "Findings")
+	if err != nil {
+		t.Fatalf("GetSection: %v", err)
+	}
+	if got != "- first\n- second" {
+		t.Errorf("Findings = %q", got)
+	}
+
+	after, err := os.ReadFile(rec.RawPath)
+	if err != nil {
+		t.Fatalf("read case.md: %v", err)
+	}
+	if want := strings.Replace(string(before), "## Findings\n", "## Findings\n\n- first\n- second\n", 1); string(after) != want {
+		t.Errorf("case.md after append =\n%s\nwant\n%s", after, want)
+	}
+
+	events, err := cr.History(owner, rec.ID)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.Action != ActionSectionAppend || last.To != "Findings" {
+		t.Errorf("last event = %+v, want section-append to Findings", last)
+	}
+
+	var cliErr *agentops.CLIError
+	if _, err := cr.GetSection(owner, rec.ID, "Missing"); !errors.As(err, &cliErr) || cliErr.Kind != "section_not_found" {
+		t.Errorf("GetSection(Missing) = %v, want section_not_found", err)
+	}
+
+	if _, err := cr.Claim(owner, rec.ID); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	other := testCtx()
+	other.Values["slot"] = "agent-2"
+	if _, err := cr.SetSection(other, rec.ID, "Findings", "overwritten"); !errors.As(err, &cliErr) || cliErr.Kind != "claimed_by_other_slot" {
+		t.Errorf("SetSection from another slot = %v, want claimed_by_other_slot", err)
+	}
+}
+
+func TestCaseResourceSectionsRejectHeadings(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	rec, err := cr.Create(ctx, "headings", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	before, err := os.ReadFile(rec.RawPath)
+	if err != nil {
+		t.Fatalf("read case.md: %v", err)
+	}
+
+	for _, text := range []string{
+		"- first\n## Injected\n- second",
+		"## Findings",
+		"```\nunclosed fence",
+	} {
+		var cliErr *agentops.CLIError
+		if _, err := cr.SetSection(ctx, rec.ID, "Findings", text); !errors.As(err, &cliErr) || cliErr.Code != agentops.ExitUsage {
+			t.Errorf("SetSection(%q) = %v, want a usage error", text, err)
+		}
+		if _, err := cr.AppendSection(ctx, rec.ID, "Findings", text); !errors.As(err, &cliErr) || cliErr.Code != agentops.ExitUsage {
+			t.Errorf("AppendSection(%q) = %v, want a usage error", text, err)
+		}
+	}
+	if after, _ := os.ReadFile(rec.RawPath); string(after) != string(before) {
+		t.Errorf("refused edits changed case.md:\n%s", after)
+	}
+
+	// Headings inside a fence and deeper headings are section text.
+	text := "### Detail\n```\n## not a section\n```"
+	if _, err := cr.AppendSection(ctx, rec.ID, "Findings", text); err != nil {
+		t.Fatalf("AppendSection: %v", err)
+	}
+	if got, _ := cr.GetSection(ctx, rec.ID, "Findings"); got != text {
+		t.Errorf("Findings = %q, want %q", got, text)
+	}
+}
@@ -0,0 +1,300 @@
+package caseresource
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/hooks"
+	"github.com/gh-xj/agentops/resource"
+	workerresource "github.com/gh-xj/agentops/resource/worker"
+	"github.com/gh-xj/agentops/sidecar"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// DispatchReportFile is the sidecar the dispatcher writes each cycle's report
+// to.
+const DispatchReportFile = "dispatch.json"
+
+// ActionSidecarWrite is the event action recorded when WriteSidecar writes a
+// sidecar. The sidecar path is recorded in the event's to field.
+const ActionSidecarWrite = "sidecar-write"
+
+// Sidecar owners besides workers, which own theirs as "worker:<name>".
+const (
+	OwnerAgentops = "agentops" // the hook log, dispatch and reconcile reports, and budget
+	OwnerGuard    = "guard"    // named by a transition guard
+)
+
+// Sidecar is a file in a case directory other than case.md and the history
+// log.
+type Sidecar struct {
+	Path  string `json:"path"`  // relative to the case directory, slash-separated
+	Owner string `json:"owner"` // "" when nothing owns it
+}
+
+// SidecarSchema describes sidecars for rendering.
+var SidecarSchema = resource.ResourceSchema{
+	Kind:        "sidecar",
+	Description: "A file written next to case.md",
+	Fields: []resource.FieldDef{
+		{Name: "path", Type: "string", Required: true},
+		{Name: "owner", Type: "string"},
+	},
+}
+
+// sidecarOwners maps each case-relative path with a known owner to it.
+type sidecarOwners struct {
+	owners  map[string]string
+	workers *workerresource.Registry
+}
+
+// loadSidecarOwners collects the sidecars agentops writes itself, the paths
+// transition guards name, and every registered worker's sidecar-path.
+func (cr *CaseResource) loadSidecarOwners() (*sidecarOwners, error) {
+	o := &sidecarOwners{owners: map[string]string{
+		hooks.LogFile:       OwnerAgentops,
+		DispatchReportFile:  OwnerAgentops,
+		ReconcileReportFile: OwnerAgentops,
+		path.Clean(filepath.ToSlash(cr.strat.Budget.Tracking.SidecarPath())): OwnerAgentops,
+	}}
+	for _, t := range cr.strat.Transitions.Transitions {
+		for _, p := range t.Guards.Sidecars {
+			o.owners[path.Clean(filepath.ToSlash(p))] = OwnerGuard
+		}
+	}
+	reg, err := workerresource.Load(cr.fs, cr.strat.Root)
+	if err != nil {
+		return nil, err
+	}
+	o.workers = reg
+	for _, w := range reg.All() {
+		if w.SidecarPath != "" {
+			o.owners[path.Clean(filepath.ToSlash(w.SidecarPath))] = "worker:" + w.Name
+		}
+	}
+	return o, nil
+}
+
+// worker returns the worker that owns rel, if any.
+func (o *sidecarOwners) worker(rel string) (workerresource.Worker, bool) {
+	name, ok := strings.CutPrefix(o.owners[rel], "worker:")
+	if !ok {
+		return workerresource.Worker{}, false
+	}
+	return o.workers.Get(name)
+}
+
+// Sidecars lists the sidecars in a case directory with their owners.
+func (cr *CaseResource) Sidecars(ctx *agentops.AppContext, id string) ([]Sidecar, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	loc, err := cr.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := cr.loadSidecarOwners()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := cr.sidecarPaths(loc.Dir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Sidecar, len(paths))
+	for i, p := range paths {
+		out[i] = Sidecar{Path: p, Owner: owners.owners[p]}
+	}
+	return out, nil
+}
+
+// ReadSidecar returns the contents of a sidecar.
+func (cr *CaseResource) ReadSidecar(ctx *agentops.AppContext, id, rel string) ([]byte, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	rel, err := cleanSidecarPath(rel)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := cr.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	p := filepath.Join(loc.Dir, filepath.FromSlash(rel))
+	if !cr.fs.Exists(p) {
+		return nil, agentops.NewCLIError(agentops.ExitUsage, "sidecar_not_found",
+			fmt.Sprintf("case %s has no sidecar %s", id, rel), nil)
+	}
+	return cr.fs.ReadFile(p)
+}
+
+// WriteSidecar writes a sidecar on behalf of the worker named by the "worker"
+// context value. A worker's sidecar may only be written by that worker, and
+// sidecars agentops keeps itself are refused. Data must parse by its file
+// extension and satisfy the owning worker's sidecar-schema.
+func (cr *CaseResource) WriteSidecar(ctx *agentops.AppContext, id, rel string, data []byte) (*Sidecar, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	rel, err := cleanSidecarPath(rel)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := cr.locate(id)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := cr.loadSidecarOwners()
+	if err != nil {
+		return nil, err
+	}
+
+	writer := ""
+	if ctx != nil {
+		writer, _ = ctx.Values["worker"].(string)
+	}
+	owner := owners.owners[rel]
+	switch {
+	case owner == OwnerAgentops:
+		return nil, agentops.NewCLIError(agentops.ExitTransitionDenied, "sidecar_owned",
+			fmt.Sprintf("sidecar %s is written by agentops itself", rel), nil)
+	case strings.HasPrefix(owner, "worker:") && owner != "worker:"+writer:
+		as := "no worker; pass --worker"
+		if writer != "" {
+			as = "worker " + writer
+		}
+		return nil, agentops.NewCLIError(agentops.ExitTransitionDenied, "sidecar_owned_by_other",
+			fmt.Sprintf("sidecar %s belongs to %s (writing as %s)", rel, owner, as), nil)
+	}
+
+	if problems := cr.checkSidecar(owners, rel, data); len(problems) > 0 {
+		return nil, agentops.NewCLIError(agentops.ExitValidationFailed, "invalid_sidecar",
+			fmt.Sprintf("sidecar %s: %s", rel, strings.Join(problems, "; ")), nil)
+	}
+
+	p := filepath.Join(loc.Dir, filepath.FromSlash(rel))
+	if err := cr.fs.EnsureDir(filepath.Dir(p)); err != nil {
+		return nil, fmt.Errorf("create sidecar directory: %w", err)
+	}
+	if err := cr.fs.WriteFile(p, data, 0o644); err != nil {
+		return nil, fmt.Errorf("write sidecar: %w", err)
+	}
+	ev := cr.newEvent(ctx, ActionSidecarWrite, "", rel)
+	if writer != "" {
+		ev.Actor = "worker:" + writer
+	}
+	if err := cr.appendEvent(loc.Dir, ev); err != nil {
+		return nil, err
+	}
+	if err := cr.commit(id, ev); err != nil {
+		return nil, err
+	}
+	return &Sidecar{Path: rel, Owner: owner}, nil
+}
+
+// checkSidecar returns why data is not a valid sidecar at rel: it does not
+// parse by its extension, or it breaks the owning worker's schema.
+func (cr *CaseResource) checkSidecar(owners *sidecarOwners, rel string, data []byte) []string {
+	if w, ok := owners.worker(rel); ok {
+		problems, _ := w.CheckSidecar(cr.fs, data) // a broken schema is reported by `worker validate`
+		return problems
+	}
+	if _, _, err := sidecar.Decode(rel, data); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+// validateSidecars reports orphaned and malformed sidecars in a case
+// directory.
+func (cr *CaseResource) validateSidecars(caseDir string) ([]agentops.DoctorFinding, error) {
+	owners, err := cr.loadSidecarOwners()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := cr.sidecarPaths(caseDir)
+	if err != nil {
+		return nil, err
+	}
+	var findings []agentops.DoctorFinding
+	for _, rel := range paths {
+		p := filepath.Join(caseDir, filepath.FromSlash(rel))
+		if owners.owners[rel] == "" {
+			findings = append(findings, agentops.DoctorFinding{
+				Code:    "orphaned_sidecar",
+				Path:    p,
+				Message: fmt.Sprintf("sidecar %s is not written by any worker or named by a guard", rel),
+			})
+			continue
+		}
+		data, err := cr.fs.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("read sidecar: %w", err)
+		}
+		if problems := cr.checkSidecar(owners, rel, data); len(problems) > 0 {
+			findings = append(findings, agentops.DoctorFinding{
+				Code:    "malformed_sidecar",
+				Path:    p,
+				Message: fmt.Sprintf("sidecar %s: %s", rel, strings.Join(problems, "; ")),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// sidecarPaths returns every file under caseDir except case.md, the history
+// log and dotfiles, as sorted slash-separated relative paths.
+func (cr *CaseResource) sidecarPaths(caseDir string) ([]string, error) {
+	var out []string
+	var walk func(dir, prefix string) error
+	walk = func(dir, prefix string) error {
+		entries, err := cr.fs.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if strings.HasPrefix(e.Name, ".") {
+				continue
+			}
+			rel := prefix + e.Name
+			if e.IsDir {
+				if err := walk(filepath.Join(dir, e.Name), rel+"/"); err != nil {
+					return err
+				}
+				continue
+			}
+			if rel == "case.md" || rel == EventsFile {
+				continue
+			}
+			out = append(out, rel)
+		}
+		return nil
+	}
+	if err := walk(caseDir, ""); err != nil {
+		return nil, fmt.Errorf("list sidecars: %w", err)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// cleanSidecarPath normalizes a case-relative sidecar path and refuses paths
+// outside the case directory, case.md and the history log.
+func cleanSidecarPath(rel string) (string, error) {
+	clean := path.Clean(filepath.ToSlash(rel))
+	switch {
+	case rel == "" || clean == ".":
+		return "", agentops.NewCLIError(agentops.ExitUsage, "invalid_sidecar_path", "sidecar path is empty", nil)
+	case path.IsAbs(clean) || filepath.IsAbs(rel) || clean == ".." || strings.HasPrefix(clean, "../"):
+		return "", agentops.NewCLIError(agentops.ExitUsage, "invalid_sidecar_path",
+			fmt.Sprintf("sidecar path %q must be inside the case directory", rel), nil)
+	case clean == "case.md" || clean == EventsFile:
+		return "", agentops.NewCLIError(agentops.ExitUsage, "invalid_sidecar_path",
+			fmt.Sprintf("%s is not a sidecar", clean), nil)
+	}
+	return clean, nil
+}
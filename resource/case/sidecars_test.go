@@ -0,0 +1,131 @@
+package caseresource
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/dal"
+)
+
+// writeWorker declares a worker skill, with an optional sidecar schema, in
+// the project at root.
+func writeWorker(t *testing.T, root, name, sidecarPath, schema string) {
+	t.Helper()
+	skillDir := filepath.Join(root, ".claude", "skills", name)
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	skill := "---\nworker-type: review\nsidecar-path: " + sidecarPath + "\n"
+	if schema != "" {
+		skill += "sidecar-schema: schema.json\n"
+		if err := os.WriteFile(filepath.Join(skillDir, "schema.json"), []byte(schema), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	skill += "---\n# " + name + "\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skill), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCaseResourceSidecars(t *testing.T) {
+	root, strat := setupTestProject(t)
+	writeWorker(t, root, "review", "review.json", `{"type": "object", "required": ["verdict"]}`)
+	writeWorker(t, root, "lint", "lint/report.json", "")
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+
+	created, err := cr.Create(testCtx(), "sidecars", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id := created.ID
+	as := func(worker string) *agentops.AppContext {
+		ctx := testCtx()
+		if worker != "" {
+			ctx.Values["worker"] = worker
+		}
+		return ctx
+	}
+
+	denied := []struct {
+		name, worker, path, data, kind string
+	}{
+		{"other worker", "lint", "review.json", `{"verdict":"pass"}`, "sidecar_owned_by_other"},
+		{"no worker", "", "review.json", `{"verdict":"pass"}`, "sidecar_owned_by_other"},
+		{"agentops sidecar", "review", "hooks.jsonl", "{}", "sidecar_owned"},
+		{"schema", "review", "review.json", `{"score":1}`, "invalid_sidecar"},
+		{"malformed", "lint", "lint/report.json", `{`, "invalid_sidecar"},
+		{"outside case", "review", "../escape.json", "{}", "invalid_sidecar_path"},
+		{"case.md", "review", "case.md", "x", "invalid_sidecar_path"},
+	}
+	for _, tt := range denied {
+		_, err := cr.WriteSidecar(as(tt.worker), id, tt.path, []byte(tt.data))
+		var cliErr *agentops.CLIError
+		if !errors.As(err, &cliErr) || cliErr.Kind != tt.kind {
+			t.Errorf("%s: err = %v, want %s", tt.name, err, tt.kind)
+		}
+	}
+
+	if _, err := cr.WriteSidecar(as("review"), id, "review.json", []byte(`{"verdict":"pass"}`)); err != nil {
+		t.Fatalf("write review.json: %v", err)
+	}
+	if _, err := cr.WriteSidecar(as("lint"), id, "lint/report.json", []byte(`{}`)); err != nil {
+		t.Fatalf("write lint/report.json: %v", err)
+	}
+	data, err := cr.ReadSidecar(testCtx(), id, "./review.json")
+	if err != nil || string(data) != `{"verdict":"pass"}` {
+		t.Errorf("ReadSidecar = %q, %v", data, err)
+	}
+	events, err := cr.History(testCtx(), id)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if last := events[len(events)-1]; last.Action != ActionSidecarWrite || last.To != "lint/report.json" || last.Actor != "worker:lint" {
+		t.Errorf("last event = %+v", last)
+	}
+
+	caseDir := filepath.Dir(created.RawPath)
+	if err := os.WriteFile(filepath.Join(caseDir, "stray.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(caseDir, "review.json"), []byte(`{"score":1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sidecars, err := cr.Sidecars(testCtx(), id)
+	if err != nil {
+		t.Fatalf("Sidecars: %v", err)
+	}
+	want := []Sidecar{
+		{Path: "lint/report.json", Owner: "worker:lint"},
+		{Path: "review.json", Owner: "worker:review"},
+		{Path: "stray.txt", Owner: ""},
+	}
+	if !slices.Equal(sidecars, want) {
+		t.Errorf("Sidecars = %+v, want %+v", sidecars, want)
+	}
+
+	rec, err := cr.Get(testCtx(), id)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got, _ := rec.Fields["sidecars"].([]string); !slices.Equal(got, []string{"lint/report.json", "review.json", "stray.txt"}) {
+		t.Errorf("sidecars field = %v", rec.Fields["sidecars"])
+	}
+
+	report, err := cr.Validate(testCtx(), id)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	codes := map[string]string{}
+	for _, f := range report.Findings {
+		codes[filepath.Base(f.Path)] = f.Code
+	}
+	if report.OK || codes["stray.txt"] != "orphaned_sidecar" || codes["review.json"] != "malformed_sidecar" || len(codes) != 2 {
+		t.Errorf("Validate findings = %+v", report.Findings)
+	}
+}
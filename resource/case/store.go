@@ -0,0 +1,655 @@
+package caseresource
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/budget"
+	"github.com/gh-xj/agentops/hooks"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// Defaults for the git repository behind the separate-repo backend.
+const (
+	caseRepoBranch = "main"
+	caseRepoRemote = "origin"
+	commitName     = "agentops"
+	commitEmail    = "agentops@localhost"
+)
+
+// SyncReport describes the outcome of a case sync.
+type SyncReport struct {
+	Remote   string   `json:"remote"`
+	Branch   string   `json:"branch"`
+	Pushed   bool     `json:"pushed"`
+	Resolved []string `json:"resolved,omitempty"` // paths whose conflicts were merged
+}
+
+// repoDir returns the root of the case repository: the parent of the cases
+// directory.
+func (cr *CaseResource) repoDir() (string, error) {
+	casesDir, err := cr.casesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(casesDir), nil
+}
+
+// versioned reports whether case changes are committed by agentops. Only the
+// separate-repo backend is; in-repo cases are committed with the project.
+func (cr *CaseResource) versioned() bool {
+	if cr.strat == nil || cr.strat.Storage.Backend == "in-repo" {
+		return false
+	}
+	return cr.exec != nil && cr.exec.Which("git")
+}
+
+// git runs a git command in the case repository.
+func (cr *CaseResource) git(dir string, args ...string) (string, error) {
+	out, err := cr.exec.RunInDir(dir, "git", args...)
+	if err != nil {
+		return out, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// ensureRepo initializes the case repository on first use. When the strategy
+// names a remote the repository is cloned from it, otherwise a fresh one is
+// created on the main branch. A clone needs an empty directory, so callers
+// set up the repository before writing to the cases directory.
+func (cr *CaseResource) ensureRepo() (string, error) {
+	dir, err := cr.repoDir()
+	if err != nil {
+		return "", err
+	}
+	if cr.fs.Exists(filepath.Join(dir, ".git")) {
+		return dir, nil
+	}
+	if err := cr.fs.EnsureDir(dir); err != nil {
+		return "", fmt.Errorf("create case repo: %w", err)
+	}
+
+	remote := cr.strat.Storage.Remote
+	if remote != "" {
+		if _, err := cr.git(dir, "clone", "--quiet", "--origin", caseRepoRemote, cr.resolveRemote(remote), "."); err != nil {
+			return "", fmt.Errorf("clone case repo from %s: %w", remote, err)
+		}
+	} else if _, err := cr.git(dir, "init", "--quiet"); err != nil {
+		return "", err
+	}
+	// The remote's HEAD may name another branch (or none), so check out the
+	// case branch explicitly.
+	upstream := caseRepoRemote + "/" + caseRepoBranch
+	if _, err := cr.git(dir, "rev-parse", "--verify", "--quiet", upstream); remote != "" && err == nil {
+		if _, err := cr.git(dir, "checkout", "--quiet", "-B", caseRepoBranch, upstream); err != nil {
+			return "", err
+		}
+	} else if _, err := cr.git(dir, "symbolic-ref", "HEAD", "refs/heads/"+caseRepoBranch); err != nil {
+		return "", err
+	}
+
+	// Commits must not fail on machines without a git identity.
+	if out, _ := cr.exec.RunInDir(dir, "git", "config", "user.email"); strings.TrimSpace(out) == "" {
+		if _, err := cr.git(dir, "config", "user.name", commitName); err != nil {
+			return "", err
+		}
+		if _, err := cr.git(dir, "config", "user.email", commitEmail); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// initStore sets up the case repository of a versioned backend. It runs
+// before the first write to the cases directory.
+func (cr *CaseResource) initStore() error {
+	if !cr.versioned() {
+		return nil
+	}
+	_, err := cr.ensureRepo()
+	return err
+}
+
+// resolveRemote makes a relative local remote path absolute against the
+// project root in the main checkout. URLs and absolute paths are returned
+// unchanged.
+func (cr *CaseResource) resolveRemote(remote string) string {
+	if strings.Contains(remote, "://") || filepath.IsAbs(remote) {
+		return remote
+	}
+	return filepath.Join(cr.sharedRoot(), remote)
+}
+
+// commit records the pending changes to case id as one commit describing ev.
+// Only the case's directory is staged, wherever it lives now or lived before
+// a move, so other cases' changes stay out of the commit. id "" commits every
+// pending change, for repository-wide operations such as layout migration
+// and sync. It is a no-op for unversioned backends and when nothing changed.
+func (cr *CaseResource) commit(id string, ev Event) error {
+	_, err := cr.commitChanges(id, ev)
+	return err
+}
+
+// commitChanges is commit, also reporting whether a commit was made.
+func (cr *CaseResource) commitChanges(id string, ev Event) (bool, error) {
+	if !cr.versioned() {
+		return false, nil
+	}
+	dir, err := cr.ensureRepo()
+	if err != nil {
+		return false, err
+	}
+	add := []string{"add", "-A"}
+	if id != "" {
+		add = append(add, "--", ":(glob)**/"+id+"/**")
+	}
+	if _, err := cr.git(dir, add...); err != nil {
+		return false, err
+	}
+	staged, err := cr.git(dir, "diff", "--cached", "--name-only")
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(staged) == "" {
+		return false, nil
+	}
+	if _, err := cr.git(dir, "commit", "--quiet", "-m", commitMessage(id, ev)); err != nil {
+		return false, fmt.Errorf("commit case %s: %w", id, err)
+	}
+	return true, nil
+}
+
+// Commit records pending changes to a case, such as worker sidecars written
+// during a dispatch cycle, as one commit for action. It reports false when
+// there was nothing to commit.
+func (cr *CaseResource) Commit(ctx *agentops.AppContext, id, action string) (bool, error) {
+	if cr.strat == nil {
+		return false, strategy.Missing(cr.loadErr)
+	}
+	return cr.commitChanges(id, cr.newEvent(ctx, action, "", ""))
+}
+
+// Versioned reports whether case changes are committed to a case repository.
+func (cr *CaseResource) Versioned() bool {
+	return cr.versioned()
+}
+
+// commitMessage renders the structured commit message for one mutation: a
+// summary line followed by trailers that tools can parse.
+func commitMessage(id string, ev Event) string {
+	var b strings.Builder
+	subject := "cases: " + ev.Action
+	if id != "" {
+		subject = fmt.Sprintf("case(%s): %s", id, ev.Action)
+	}
+	if ev.From != "" || ev.To != "" {
+		subject += fmt.Sprintf(" %s -> %s", orNone(ev.From), orNone(ev.To))
+	}
+	b.WriteString(subject + "\n\n")
+	if id != "" {
+		fmt.Fprintf(&b, "Case-Id: %s\n", id)
+	}
+	fmt.Fprintf(&b, "Action: %s\n", ev.Action)
+	fmt.Fprintf(&b, "Actor: %s\n", ev.Actor)
+	if ev.From != "" {
+		fmt.Fprintf(&b, "From: %s\n", ev.From)
+	}
+	if ev.To != "" {
+		fmt.Fprintf(&b, "To: %s\n", ev.To)
+	}
+	if ev.Note != "" {
+		fmt.Fprintf(&b, "Note: %s\n", strings.ReplaceAll(ev.Note, "\n", " "))
+	}
+	return b.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// Sync commits pending changes, fetches the remote, rebases local commits onto
+// it and pushes the result. remote overrides the strategy's storage remote.
+// Conflicts in case.md, the history and hook logs and the sidecars agentops
+// writes are resolved deterministically (see mergeRule); any other conflict
+// aborts the rebase and fails the sync.
+func (cr *CaseResource) Sync(ctx *agentops.AppContext, remote string) (*SyncReport, error) {
+	if cr.strat == nil {
+		return nil, strategy.Missing(cr.loadErr)
+	}
+	if cr.strat.Storage.Backend == "in-repo" {
+		return nil, agentops.NewCLIError(agentops.ExitValidationFailed, "sync_unsupported",
+			"case sync requires the separate-repo storage backend", nil)
+	}
+	if !cr.exec.Which("git") {
+		return nil, fmt.Errorf("case sync requires git on PATH")
+	}
+	if remote == "" {
+		remote = cr.strat.Storage.Remote
+	}
+	if remote == "" {
+		return nil, agentops.NewCLIError(agentops.ExitValidationFailed, "no_remote",
+			"no remote to sync with: set remote in storage.yaml or pass --remote", nil)
+	}
+	remote = cr.resolveRemote(remote)
+
+	dir, err := cr.ensureRepo()
+	if err != nil {
+		return nil, err
+	}
+	if err := cr.commit("", cr.newEvent(ctx, "sync", "", "")); err != nil {
+		return nil, err
+	}
+	if err := cr.setRemote(dir, remote); err != nil {
+		return nil, err
+	}
+	report := &SyncReport{Remote: remote, Branch: caseRepoBranch}
+	if out, err := cr.git(dir, "symbolic-ref", "--short", "HEAD"); err == nil {
+		report.Branch = strings.TrimSpace(out)
+	}
+
+	if _, err := cr.git(dir, "fetch", "--quiet", caseRepoRemote); err != nil {
+		return nil, err
+	}
+	upstream := caseRepoRemote + "/" + report.Branch
+	_, upstreamErr := cr.git(dir, "rev-parse", "--verify", "--quiet", upstream)
+	_, headErr := cr.git(dir, "rev-parse", "--verify", "--quiet", "HEAD")
+	switch {
+	case upstreamErr != nil:
+		// Nothing published yet; the push below creates the branch.
+	case headErr != nil:
+		// No local commits; take the remote branch as is.
+		if _, err := cr.git(dir, "reset", "--quiet", "--hard", upstream); err != nil {
+			return nil, err
+		}
+		return report, nil
+	default:
+		resolved, err := cr.rebase(dir, upstream)
+		if err != nil {
+			return nil, err
+		}
+		report.Resolved = resolved
+	}
+
+	if headErr == nil {
+		if _, err := cr.git(dir, "push", "--quiet", caseRepoRemote, "HEAD:refs/heads/"+report.Branch); err != nil {
+			return nil, err
+		}
+		report.Pushed = true
+	}
+	return report, nil
+}
+
+// setRemote points the sync remote at url, adding it if needed.
+func (cr *CaseResource) setRemote(dir, url string) error {
+	if _, err := cr.git(dir, "remote", "get-url", caseRepoRemote); err != nil {
+		_, err = cr.git(dir, "remote", "add", caseRepoRemote, url)
+		return err
+	}
+	_, err := cr.git(dir, "remote", "set-url", caseRepoRemote, url)
+	return err
+}
+
+// rebase replays local commits onto upstream, resolving conflicts commit by
+// commit. It returns the paths it merged.
+func (cr *CaseResource) rebase(dir, upstream string) ([]string, error) {
+	var resolved []string
+	_, err := cr.git(dir, "rebase", "--quiet", upstream)
+	for err != nil {
+		out, diffErr := cr.git(dir, "diff", "--name-only", "--diff-filter=U")
+		conflicts := strings.Fields(out)
+		if diffErr != nil || len(conflicts) == 0 {
+			_, _ = cr.git(dir, "rebase", "--abort")
+			return nil, agentops.NewCLIError(agentops.ExitValidationFailed, "sync_conflict",
+				"rebase onto "+upstream+" failed", err)
+		}
+		for _, path := range conflicts {
+			if mergeErr := cr.resolveConflict(dir, path); mergeErr != nil {
+				_, _ = cr.git(dir, "rebase", "--abort")
+				return nil, agentops.NewCLIError(agentops.ExitValidationFailed, "sync_conflict",
+					fmt.Sprintf("cannot resolve conflict in %s", path), mergeErr)
+			}
+			resolved = append(resolved, path)
+		}
+		if _, addErr := cr.git(dir, "add", "--", "."); addErr != nil {
+			_, _ = cr.git(dir, "rebase", "--abort")
+			return nil, agentops.NewCLIError(agentops.ExitValidationFailed, "sync_conflict",
+				"stage resolved conflicts", addErr)
+		}
+		_, err = cr.git(dir, "-c", "core.editor=true", "rebase", "--continue")
+	}
+	sort.Strings(resolved)
+	return dedupe(resolved), nil
+}
+
+// resolveConflict writes the merged content of a conflicted path. During a
+// rebase stage 2 is the upstream version and stage 3 the local commit being
+// replayed.
+func (cr *CaseResource) resolveConflict(dir, path string) error {
+	merge := cr.mergeRule(path)
+	if merge == nil {
+		return fmt.Errorf("no merge rule for %s", filepath.Base(path))
+	}
+
+	base, _ := cr.git(dir, "show", ":1:"+path)
+	upstream, err := cr.git(dir, "show", ":2:"+path)
+	if err != nil {
+		return err
+	}
+	local, err := cr.git(dir, "show", ":3:"+path)
+	if err != nil {
+		return err
+	}
+	merged, err := merge(base, upstream, local)
+	if err != nil {
+		return err
+	}
+	return cr.fs.WriteFile(filepath.Join(dir, path), []byte(merged), 0o644)
+}
+
+// mergeRule returns how conflicting versions of path are merged, or nil when
+// the file has no merge rule. Append-only logs are unioned. Of the sidecars
+// agentops rewrites, budget usage is summed and the other reports keep the
+// newest version.
+func (cr *CaseResource) mergeRule(path string) func(base, upstream, local string) (string, error) {
+	name := filepath.Base(path)
+	switch name {
+	case "case.md":
+		return mergeCaseMD
+	case EventsFile, hooks.LogFile:
+		return func(base, upstream, local string) (string, error) {
+			return mergeLog(upstream, local), nil
+		}
+	case DispatchReportFile:
+		return newestReport("finished_at")
+	case ReconcileReportFile:
+		return newestReport("reconciled_at")
+	}
+	if sidecar := filepath.ToSlash(cr.strat.Budget.Tracking.SidecarPath()); strings.HasSuffix(filepath.ToSlash(path), "/"+sidecar) {
+		return mergeBudget
+	}
+	return nil
+}
+
+// mergeCaseMD merges two versions of a case.md against their common base.
+// Frontmatter keys changed on one side take that side's value. Keys changed on
+// both sides take the upstream value, so the first change to be published
+// wins, which keeps a claim from being held by two slots. Keys follow the
+// upstream order with local additions appended. The body is merged section by
+// section (see mergeBody).
+func mergeCaseMD(base, upstream, local string) (string, error) {
+	upFM, upBody, err := ParseFrontmatter(upstream)
+	if err != nil {
+		return "", fmt.Errorf("upstream: %w", err)
+	}
+	localFM, localBody, err := ParseFrontmatter(local)
+	if err != nil {
+		return "", fmt.Errorf("local: %w", err)
+	}
+	baseFM, baseBody, err := ParseFrontmatter(base)
+	if err != nil {
+		baseFM, baseBody = NewFrontmatter(), ""
+	}
+
+	merged := NewFrontmatter()
+	for _, k := range upFM.Keys() {
+		merged.keys = append(merged.keys, k)
+		merged.nodes[k] = upFM.nodes[k]
+		if localChanged(baseFM, localFM, upFM, k) {
+			if localFM.Has(k) {
+				merged.nodes[k] = localFM.nodes[k]
+			} else {
+				merged.Delete(k)
+			}
+		}
+	}
+	for _, k := range localFM.Keys() {
+		if !upFM.Has(k) && !baseFM.Has(k) {
+			merged.keys = append(merged.keys, k)
+			merged.nodes[k] = localFM.nodes[k]
+		}
+	}
+
+	body, err := mergeBody(baseBody, upBody, localBody)
+	if err != nil {
+		return "", err
+	}
+	return RenderFrontmatter(merged) + body, nil
+}
+
+// mergeBody merges two versions of a case.md body section by section. A
+// section changed on one side takes that side's text, and sections added on
+// either side are kept, local ones after upstream's. When both sides only
+// appended to a section, upstream's text is kept and the local addition
+// appended to it. Any other change made on both sides, including the text
+// before the first section, is a conflict.
+func mergeBody(base, upstream, local string) (string, error) {
+	if body, ok := pick(base, upstream, local); ok {
+		return body, nil
+	}
+	baseB, upB, localB := ParseBody(base), ParseBody(upstream), ParseBody(local)
+
+	merged := &Body{}
+	preamble, ok := pick(baseB.Preamble, upB.Preamble, localB.Preamble)
+	if !ok {
+		return "", fmt.Errorf("the text before the first section changed on both sides")
+	}
+	merged.Preamble = preamble
+
+	var additions [][2]string // local text appended to sections upstream also appended to
+	for _, up := range upB.Sections {
+		b, inBase := baseB.Section(up.Name)
+		l, inLocal := localB.Section(up.Name)
+		switch {
+		case !inBase && !inLocal:
+			merged.Sections = append(merged.Sections, up)
+		case !inBase:
+			if l.Content != up.Content {
+				return "", fmt.Errorf("section %q added on both sides", up.Name)
+			}
+			merged.Sections = append(merged.Sections, up)
+		case !inLocal:
+			// Removed locally; keep it only if upstream changed it.
+			if up.Content != b.Content {
+				return "", fmt.Errorf("section %q removed locally and changed upstream", up.Name)
+			}
+		default:
+			s := up
+			if content, ok := pick(b.Content, up.Content, l.Content); ok {
+				s.Content = content
+				merged.Sections = append(merged.Sections, s)
+				continue
+			}
+			added, ok := appended(b.Text(), l.Text())
+			if _, upAppended := appended(b.Text(), up.Text()); !ok || !upAppended {
+				return "", fmt.Errorf("section %q changed on both sides", up.Name)
+			}
+			merged.Sections = append(merged.Sections, s)
+			additions = append(additions, [2]string{up.Name, added})
+		}
+	}
+	for _, l := range localB.Sections {
+		if _, ok := upB.Section(l.Name); ok {
+			continue
+		}
+		if b, inBase := baseB.Section(l.Name); inBase {
+			// Removed upstream; keep it only if it changed locally.
+			if l.Content != b.Content {
+				return "", fmt.Errorf("section %q removed upstream and changed locally", l.Name)
+			}
+			continue
+		}
+		merged.Sections = append(merged.Sections, l)
+		merged.separateFrom(len(merged.Sections) - 1)
+	}
+	for _, a := range additions {
+		merged.Append(a[0], a[1])
+	}
+	return merged.Render(), nil
+}
+
+// pick returns the side that changed base, or either when both made the
+// same change. It reports false when both sides changed it differently.
+func pick(base, upstream, local string) (string, bool) {
+	switch {
+	case upstream == base || upstream == local:
+		return local, true
+	case local == base:
+		return upstream, true
+	}
+	return "", false
+}
+
+// appended returns what changed adds after base when it only appends to it.
+func appended(base, changed string) (string, bool) {
+	rest, ok := strings.CutPrefix(changed, base)
+	if !ok || (base != "" && rest != "" && !strings.HasPrefix(rest, "\n")) {
+		return "", false
+	}
+	return strings.Trim(rest, "\n"), true
+}
+
+// localChanged reports whether key should take the local value: it changed
+// locally and upstream left it as it was in base.
+func localChanged(base, local, upstream *Frontmatter, key string) bool {
+	return !sameValue(base, local, key) && sameValue(base, upstream, key)
+}
+
+// sameValue reports whether key has the same value in a and b.
+func sameValue(a, b *Frontmatter, key string) bool {
+	if a.Has(key) != b.Has(key) {
+		return false
+	}
+	if !a.Has(key) {
+		return true
+	}
+	return fmt.Sprint(a.Get(key)) == fmt.Sprint(b.Get(key))
+}
+
+// mergeLog unions two JSON lines logs such as events.jsonl and hooks.jsonl.
+// Lines are ordered by their "at" timestamp with upstream first on ties, and
+// identical lines are kept once.
+func mergeLog(upstream, local string) string {
+	type line struct {
+		text string
+		at   string
+	}
+	seen := make(map[string]bool)
+	var lines []line
+	for _, content := range []string{upstream, local} {
+		for _, text := range strings.Split(content, "\n") {
+			text = strings.TrimSpace(text)
+			if text == "" || seen[text] {
+				continue
+			}
+			seen[text] = true
+			var at string
+			var entry struct {
+				At time.Time `json:"at"`
+			}
+			if err := json.Unmarshal([]byte(text), &entry); err == nil {
+				at = entry.At.UTC().Format("2006-01-02T15:04:05.000000000Z")
+			}
+			lines = append(lines, line{text: text, at: at})
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].at < lines[j].at })
+
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(l.text + "\n")
+	}
+	return b.String()
+}
+
+// newestReport returns a merge that keeps whichever version of a JSON report
+// has the later timestamp in field, upstream on ties.
+func newestReport(field string) func(base, upstream, local string) (string, error) {
+	return func(base, upstream, local string) (string, error) {
+		upAt, err := reportTime(upstream, field)
+		if err != nil {
+			return "", fmt.Errorf("upstream: %w", err)
+		}
+		localAt, err := reportTime(local, field)
+		if err != nil {
+			return "", fmt.Errorf("local: %w", err)
+		}
+		if localAt.After(upAt) {
+			return local, nil
+		}
+		return upstream, nil
+	}
+}
+
+// reportTime reads the timestamp in field of a JSON report.
+func reportTime(content, field string) (time.Time, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &fields); err != nil {
+		return time.Time{}, err
+	}
+	var at time.Time
+	if raw, ok := fields[field]; ok {
+		if err := json.Unmarshal(raw, &at); err != nil {
+			return time.Time{}, fmt.Errorf("%s: %w", field, err)
+		}
+	}
+	return at, nil
+}
+
+// mergeBudget merges two versions of the budget sidecar. Each side's
+// consumption since the base is added, so work done on both sides counts, and
+// the limits either side reached are kept.
+func mergeBudget(base, upstream, local string) (string, error) {
+	var baseU, upU, localU budget.Usage
+	if err := json.Unmarshal([]byte(upstream), &upU); err != nil {
+		return "", fmt.Errorf("upstream: %w", err)
+	}
+	if err := json.Unmarshal([]byte(local), &localU); err != nil {
+		return "", fmt.Errorf("local: %w", err)
+	}
+	if base != "" {
+		if err := json.Unmarshal([]byte(base), &baseU); err != nil {
+			baseU = budget.Usage{}
+		}
+	}
+
+	merged := upU
+	merged.Workers += localU.Workers - baseU.Workers
+	merged.Iterations += localU.Iterations - baseU.Iterations
+	merged.Commands += localU.Commands - baseU.Commands
+	merged.WorkerMs += localU.WorkerMs - baseU.WorkerMs
+	merged.Exceeded = append([]budget.Exceeded{}, upU.Exceeded...)
+	for _, e := range localU.Exceeded {
+		if !slices.Contains(merged.Exceeded, e) {
+			merged.Exceeded = append(merged.Exceeded, e)
+		}
+	}
+	sort.SliceStable(merged.Exceeded, func(i, j int) bool { return merged.Exceeded[i].At.Before(merged.Exceeded[j].At) })
+	if localU.UpdatedAt.After(merged.UpdatedAt) {
+		merged.UpdatedAt = localU.UpdatedAt
+	}
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// dedupe removes adjacent duplicates from a sorted slice.
+func dedupe(s []string) []string {
+	out := s[:0]
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
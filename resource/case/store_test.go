@@ -0,0 +1,338 @@
+package caseresource
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gh-xj/agentops/budget"
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// setupSeparateRepoProject bootstraps a project under parent using the
+// separate-repo backend, optionally syncing with remote.
+func setupSeparateRepoProject(t *testing.T, parent, name, remote string) *strategy.Strategy {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := filepath.Join(parent, name)
+	if err := strategy.Bootstrap(root); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	storage := "backend: separate-repo\n"
+	if remote != "" {
+		storage += "remote: " + remote + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(root, ".agentops", "storage.yaml"), []byte(storage), 0o644); err != nil {
+		t.Fatalf("write storage.yaml: %v", err)
+	}
+	strat, err := strategy.Discover(root)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	return strat
+}
+
+func gitOut(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := dal.NewExecutor().RunInDir(dir, "git", args...)
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return strings.TrimSpace(out)
+}
+
+func TestCaseResourceSeparateRepoCommits(t *testing.T) {
+	parent := t.TempDir()
+	strat := setupSeparateRepoProject(t, parent, "proj", "")
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+	ctx.Values["slot"] = "agent-1"
+
+	created, err := cr.Create(ctx, "versioned", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := cr.Claim(ctx, created.ID); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+
+	// A stray file in another case stays out of this case's commits.
+	other, err := cr.Create(ctx, "other", nil)
+	if err != nil {
+		t.Fatalf("create other: %v", err)
+	}
+	stray := filepath.Join(filepath.Dir(other.RawPath), "dispatch.json")
+	if err := os.WriteFile(stray, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cr.Transition(ctx, created.ID, "start"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	repo := filepath.Join(parent, "proj-cases")
+	if files := gitOut(t, repo, "show", "--name-only", "--format=", "HEAD"); strings.Contains(files, other.ID) {
+		t.Errorf("start commit includes another case's files:\n%s", files)
+	}
+	if status := gitOut(t, repo, "status", "--porcelain"); !strings.Contains(status, "dispatch.json") {
+		t.Errorf("stray file was committed; status:\n%s", status)
+	}
+	if err := os.Remove(stray); err != nil {
+		t.Fatal(err)
+	}
+	log := gitOut(t, repo, "log", "--format=%s")
+	want := strings.Join([]string{
+		"case(" + created.ID + "): start open -> in_progress",
+		"case(" + other.ID + "): create none -> open",
+		"case(" + created.ID + "): claim none -> agent-1",
+		"case(" + created.ID + "): create none -> open",
+	}, "\n")
+	if log != want {
+		t.Errorf("git log:\n%s\nwant:\n%s", log, want)
+	}
+	if body := gitOut(t, repo, "log", "-1", "--format=%b"); !strings.Contains(body, "Actor: slot:agent-1") {
+		t.Errorf("commit trailers missing actor:\n%s", body)
+	}
+	if status := gitOut(t, repo, "status", "--porcelain"); status != "" {
+		t.Errorf("case repo left dirty:\n%s", status)
+	}
+	if branch := gitOut(t, repo, "symbolic-ref", "--short", "HEAD"); branch != "main" {
+		t.Errorf("branch = %q, want main", branch)
+	}
+}
+
+func TestCaseResourceSync(t *testing.T) {
+	parent := t.TempDir()
+	remote := filepath.Join(parent, "remote.git")
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", remote).CombinedOutput(); err != nil {
+		t.Skipf("git init --bare: %v: %s", err, out)
+	}
+	stratA := setupSeparateRepoProject(t, parent, "a", "../remote.git")
+	stratB := setupSeparateRepoProject(t, parent, "b", "file://"+remote)
+	a := New(dal.NewFileSystem(), dal.NewExecutor(), stratA)
+	b := New(dal.NewFileSystem(), dal.NewExecutor(), stratB)
+	ctx := testCtx()
+
+	created, err := a.Create(ctx, "shared", nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := a.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync a: %v", err)
+	}
+	if _, err := b.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync b: %v", err)
+	}
+	if _, err := b.Get(ctx, created.ID); err != nil {
+		t.Fatalf("case not pulled into b: %v", err)
+	}
+
+	// Both sides change the same case before syncing.
+	if _, err := a.Transition(ctx, created.ID, "start"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := a.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync a: %v", err)
+	}
+	if _, err := b.Transition(ctx, created.ID, "block"); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	report, err := b.Sync(ctx, "")
+	if err != nil {
+		t.Fatalf("sync b with conflict: %v", err)
+	}
+	if len(report.Resolved) != 2 || !report.Pushed {
+		t.Errorf("report = %+v, want case.md and events.jsonl merged and pushed", report)
+	}
+
+	// The status a published first wins; both histories are kept.
+	got, err := b.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Fields["status"] != "in_progress" {
+		t.Errorf("status = %v, want in_progress", got.Fields["status"])
+	}
+	events, err := b.History(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	var actions []string
+	for _, ev := range events {
+		actions = append(actions, ev.Action)
+	}
+	if strings.Join(actions, ",") != "create,start,block" {
+		t.Errorf("actions = %v", actions)
+	}
+
+	// Both sides append findings; neither addition is lost.
+	if _, err := a.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync a: %v", err)
+	}
+	if _, err := a.AppendSection(ctx, created.ID, "Findings", "- from a"); err != nil {
+		t.Fatalf("append a: %v", err)
+	}
+	if _, err := a.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync a: %v", err)
+	}
+	if _, err := b.AppendSection(ctx, created.ID, "Findings", "- from b"); err != nil {
+		t.Fatalf("append b: %v", err)
+	}
+	if _, err := b.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync b with body conflict: %v", err)
+	}
+	if findings, _ := b.GetSection(ctx, created.ID, "Findings"); !strings.HasSuffix(findings, "- from a\n- from b") {
+		t.Errorf("findings = %q, want both additions", findings)
+	}
+}
+
+func TestCaseResourceCreateClonesRemoteFirst(t *testing.T) {
+	parent := t.TempDir()
+	remote := filepath.Join(parent, "remote.git")
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", remote).CombinedOutput(); err != nil {
+		t.Skipf("git init --bare: %v: %s", err, out)
+	}
+	a := New(dal.NewFileSystem(), dal.NewExecutor(), setupSeparateRepoProject(t, parent, "a", "../remote.git"))
+	b := New(dal.NewFileSystem(), dal.NewExecutor(), setupSeparateRepoProject(t, parent, "b", "../remote.git"))
+	ctx := testCtx()
+
+	fromA, err := a.Create(ctx, "from-a", nil)
+	if err != nil {
+		t.Fatalf("create a: %v", err)
+	}
+	if _, err := a.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync a: %v", err)
+	}
+
+	// b's first write clones the remote rather than starting a new history.
+	fromB, err := b.Create(ctx, "from-b", nil)
+	if err != nil {
+		t.Fatalf("create b: %v", err)
+	}
+	if _, err := b.Get(ctx, fromA.ID); err != nil {
+		t.Errorf("a's case not cloned into b: %v", err)
+	}
+	if _, err := b.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync b: %v", err)
+	}
+	if _, err := a.Sync(ctx, ""); err != nil {
+		t.Fatalf("sync a: %v", err)
+	}
+	if _, err := a.Get(ctx, fromB.ID); err != nil {
+		t.Errorf("b's case not pulled into a: %v", err)
+	}
+
+	// An unreachable remote is an error, not a silent fresh repository.
+	c := New(dal.NewFileSystem(), dal.NewExecutor(), setupSeparateRepoProject(t, parent, "c", "../missing.git"))
+	if _, err := c.Create(ctx, "lost", nil); err == nil || !strings.Contains(err.Error(), "clone case repo") {
+		t.Errorf("create with unreachable remote: err = %v", err)
+	}
+}
+
+func TestCaseResourceSyncInRepoUnsupported(t *testing.T) {
+	_, strat := setupTestProject(t)
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	if _, err := cr.Sync(testCtx(), "/tmp/remote.git"); err == nil {
+		t.Fatal("expected sync to fail for the in-repo backend")
+	}
+}
+
+func TestMergeCaseMD(t *testing.T) {
+	base := "---\ntype: intake\nstatus: open\nclaimed_by: none\n---\n# Case\n"
+	upstream := "---\ntype: intake\nstatus: in_progress\nclaimed_by: agent-1\n---\n# Case\n"
+	local := "---\ntype: pr\nstatus: blocked\nclaimed_by: none\nrisk: high\n---\n# Case\n\nMore notes.\n"
+
+	merged, err := mergeCaseMD(base, upstream, local)
+	if err != nil {
+		t.Fatalf("mergeCaseMD: %v", err)
+	}
+	want := "---\ntype: pr\nstatus: in_progress\nclaimed_by: agent-1\nrisk: high\n---\n# Case\n\nMore notes.\n"
+	if merged != want {
+		t.Errorf("merged:\n%s\nwant:\n%s", merged, want)
+	}
+}
+
+func TestMergeBody(t *testing.T) {
+	base := "# Case\n\n## Findings\n\n- a\n\n## Next Action\n\nTriage.\n"
+	tests := []struct {
+		name, upstream, local, want, err string
+	}{
+		{
+			name:     "both append to a section",
+			upstream: "# Case\n\n## Findings\n\n- a\n- b\n\n## Next Action\n\nTriage.\n",
+			local:    "# Case\n\n## Findings\n\n- a\n- c\n\n## Next Action\n\nTriage.\n",
+			want:     "# Case\n\n## Findings\n\n- a\n- b\n- c\n\n## Next Action\n\nTriage.\n",
+		},
+		{
+			name:     "different sections and a new one",
+			upstream: "# Case\n\n## Findings\n\n- a\n- b\n\n## Next Action\n\nTriage.\n",
+			local:    "# Case\n\n## Findings\n\n- a\n\n## Next Action\n\nFix it.\n\n## Notes\n\nSee logs.\n",
+			want:     "# Case\n\n## Findings\n\n- a\n- b\n\n## Next Action\n\nFix it.\n\n## Notes\n\nSee logs.\n",
+		},
+		{
+			name:     "both rewrite a section",
+			upstream: "# Case\n\n## Findings\n\n- a\n\n## Next Action\n\nShip.\n",
+			local:    "# Case\n\n## Findings\n\n- a\n\n## Next Action\n\nFix it.\n",
+			err:      `section "Next Action" changed on both sides`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeBody(base, tt.upstream, tt.local)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergeBody: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("merged:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeLog(t *testing.T) {
+	create := `{"at":"2026-01-01T00:00:00Z","actor":"a","action":"create","to":"open"}`
+	start := `{"at":"2026-01-01T00:01:00Z","actor":"a","action":"start","from":"open","to":"in_progress"}`
+	block := `{"at":"2026-01-01T00:00:30Z","actor":"b","action":"block","from":"open","to":"blocked"}`
+
+	got := mergeLog(create+"\n"+start+"\n", create+"\n"+block+"\n")
+	want := create + "\n" + block + "\n" + start + "\n"
+	if got != want {
+		t.Errorf("mergeLog:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMergeBudget(t *testing.T) {
+	base := `{"workers":1,"iterations":1,"commands":2,"worker_ms":100,"updated_at":"2026-01-01T00:00:00Z"}`
+	upstream := `{"workers":2,"iterations":2,"commands":3,"worker_ms":150,"updated_at":"2026-01-01T00:02:00Z"}`
+	local := `{"workers":1,"iterations":2,"commands":4,"worker_ms":130,"exceeded":[{"budget":"max_commands","message":"over","at":"2026-01-01T00:01:00Z"}],"updated_at":"2026-01-01T00:01:00Z"}`
+
+	merged, err := mergeBudget(base, upstream, local)
+	if err != nil {
+		t.Fatalf("mergeBudget: %v", err)
+	}
+	var got budget.Usage
+	if err := json.Unmarshal([]byte(merged), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Workers != 2 || got.Iterations != 3 || got.Commands != 5 || got.WorkerMs != 180 {
+		t.Errorf("usage = %+v, want both sides' consumption added", got)
+	}
+	if len(got.Exceeded) != 1 || got.Exceeded[0].Budget != "max_commands" {
+		t.Errorf("exceeded = %+v", got.Exceeded)
+	}
+	if want := "2026-01-01T00:02:00Z"; got.UpdatedAt.Format(time.RFC3339) != want {
+		t.Errorf("updated_at = %v, want %s", got.UpdatedAt, want)
+	}
+}
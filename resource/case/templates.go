@@ -0,0 +1,197 @@
+package caseresource
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/resource"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// Create options handled here rather than passed to a template.
+const (
+	optType     = "type"
+	optSet      = "set"
+	optClassify = "classify"
+)
+
+// managedFields are set by agentops and cannot be given with --set.
+var managedFields = []string{keyStatus, keyClaimedBy, keyClaimedAt, keyCreated}
+
+// createArgs returns the arguments of case create: the slug, the built-in
+// options and every input declared by a case template.
+func (cr *CaseResource) createArgs() []resource.ArgDef {
+	args := []resource.ArgDef{
+		{Name: "slug", Description: "URL-safe case identifier", Required: true},
+		{Name: optClassify, Description: "classify the new case with routing.yaml cues", Type: "bool"},
+		{Name: optType, Description: "case type; picks templates/<type>.md when the strategy has one"},
+		{Name: optSet, Description: "set a frontmatter field, key=value", Type: "list"},
+	}
+	if cr.strat == nil {
+		return args
+	}
+	for _, in := range cr.strat.TemplateInputs() {
+		desc := in.Description
+		if desc == "" {
+			desc = "template input"
+		}
+		desc += " (" + strings.Join(in.Types, ", ") + " template"
+		if in.Required {
+			desc += "; required"
+		}
+		if in.Default != "" {
+			desc += "; default " + strconv.Quote(in.Default)
+		}
+		args = append(args, resource.ArgDef{Name: in.Name, Description: desc + ")"})
+	}
+	return args
+}
+
+// createInput is what case create was asked for beyond the slug.
+type createInput struct {
+	typ    string            // --type, empty for the default type
+	sets   []fieldValue      // --set pairs, in order
+	inputs map[string]string // template input flags
+}
+
+type fieldValue struct{ key, value string }
+
+// parseCreateOpts reads the options of Create.
+func parseCreateOpts(opts map[string]string) (createInput, error) {
+	in := createInput{typ: opts[optType], inputs: make(map[string]string)}
+	if in.typ != "" && opts[optClassify] == "true" {
+		return in, agentops.NewCLIError(agentops.ExitUsage, "usage", "--type and --classify cannot be combined", nil)
+	}
+	for _, pair := range strings.Split(opts[optSet], "\n") {
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		switch {
+		case !ok || key == "":
+			return in, agentops.NewCLIError(agentops.ExitUsage, "usage", fmt.Sprintf("--set %q: want key=value", pair), nil)
+		case key == keyType:
+			return in, agentops.NewCLIError(agentops.ExitUsage, "usage", "use --type to set the case type", nil)
+		case slices.Contains(managedFields, key):
+			return in, agentops.NewCLIError(agentops.ExitUsage, "usage", fmt.Sprintf("--set %s: field is managed by agentops", key), nil)
+		}
+		in.sets = append(in.sets, fieldValue{key, value})
+	}
+	for name, value := range opts {
+		switch name {
+		case optType, optSet, optClassify:
+		default:
+			in.inputs[name] = value
+		}
+	}
+	return in, nil
+}
+
+// caseTemplate returns the template for typ, or nil when the strategy has
+// none and schema.md is used. Input flags the template does not declare are
+// refused, and required inputs must be set.
+func (cr *CaseResource) caseTemplate(typ string, in createInput) (*strategy.CaseTemplate, map[string]string, error) {
+	values := make(map[string]string)
+	tmpl := cr.strat.Templates[typ]
+	declared := make(map[string]bool)
+	if tmpl != nil {
+		for _, input := range tmpl.Inputs {
+			declared[input.Name] = true
+			v, ok := in.inputs[input.Name]
+			if !ok {
+				v = input.Default
+			}
+			if v == "" && input.Required {
+				return nil, nil, agentops.NewCLIError(agentops.ExitUsage, "missing_input",
+					fmt.Sprintf("the %s template requires --%s", typ, inputFlag(input.Name)), nil)
+			}
+			values[input.Name] = v
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(in.inputs)) {
+		if !declared[name] {
+			return nil, nil, agentops.NewCLIError(agentops.ExitUsage, "unknown_input",
+				fmt.Sprintf("--%s is not an input of the %s case template", inputFlag(name), typ), nil)
+		}
+	}
+	for _, kv := range in.sets {
+		values[kv.key] = kv.value
+	}
+	return tmpl, values, nil
+}
+
+// renderTemplate executes a case template for a new case and splits the
+// result into frontmatter and body. The inputs declaration is dropped.
+func (cr *CaseResource) renderTemplate(ctx *agentops.AppContext, tmpl *strategy.CaseTemplate, id, slug string, now time.Time, values map[string]string) (*Frontmatter, string, error) {
+	out, err := tmpl.Execute(strategy.TemplateData{
+		ID:       id,
+		Slug:     slug,
+		Title:    caseTitle(id),
+		Date:     now.Format("2006-01-02"),
+		Type:     tmpl.Type,
+		Status:   cr.sm.Initial(),
+		Slot:     cr.creatorSlot(ctx),
+		Values:   values,
+		Strategy: cr.strat.TemplateValues(),
+	})
+	if err != nil {
+		return nil, "", agentops.NewCLIError(agentops.ExitValidationFailed, "template_failed", "render case template", err)
+	}
+	fm, body, err := ParseFrontmatter(out)
+	if err != nil {
+		return nil, "", agentops.NewCLIError(agentops.ExitValidationFailed, "template_failed", tmpl.Source+" rendered invalid frontmatter", err)
+	}
+	fm.Delete(strategy.TemplateInputsKey)
+	fm.Delete(strategy.SchemaFieldsKey)
+	return fm, body, nil
+}
+
+// applySets sets the --set fields on fm, typed by their schema.md
+// declaration: int and bool fields are parsed, list fields split on commas.
+func (cr *CaseResource) applySets(fm *Frontmatter, sets []fieldValue) error {
+	specs := make(map[string]strategy.FieldSpec)
+	for _, spec := range cr.fieldSpecs() {
+		specs[spec.Name] = spec
+	}
+	for _, kv := range sets {
+		spec, declared := specs[kv.key]
+		var value any = kv.value
+		switch spec.Type {
+		case strategy.FieldInt:
+			if n, err := strconv.Atoi(kv.value); err == nil {
+				value = n
+			}
+		case strategy.FieldBool:
+			if b, err := strconv.ParseBool(kv.value); err == nil {
+				value = b
+			}
+		case strategy.FieldList:
+			var items []any
+			for _, item := range strings.Split(kv.value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			value = items
+		}
+		fm.Set(kv.key, value)
+		if !declared {
+			continue
+		}
+		if msg := checkField(spec, fm); msg != "" {
+			return agentops.NewCLIError(agentops.ExitUsage, "invalid_field", "--set "+msg, nil)
+		}
+	}
+	return nil
+}
+
+// inputFlag returns the create flag of a template input, as cobrax names it.
+func inputFlag(name string) string {
+	return strings.ReplaceAll(name, "_", "-")
+}
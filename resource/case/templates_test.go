@@ -0,0 +1,111 @@
+package caseresource
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func TestCaseResourceCreateFromTemplate(t *testing.T) {
+	root, _ := setupTestProject(t)
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(root, ".agentops", "schema.md"),
+		"---\nfields:\n  - {name: priority, type: int}\n  - {name: labels, type: list}\n---\n# Case Title\n")
+	write(filepath.Join(root, ".agentops", strategy.TemplatesDir, "pr.md"), `---
+inputs:
+  - {name: pr_url, description: Pull request URL, required: true}
+  - {name: base, default: main}
+pr_url: {{.Values.pr_url}}
+---
+# {{.Title}}
+
+Opened {{.Date}} as {{.Type}} ({{.Status}}) against {{.Values.base}} in {{.Slot}}.
+{{with .Values.priority}}Priority {{.}}.{{end}}
+`)
+	strat, err := strategy.Discover(root)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	cr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+
+	var names []string
+	for _, arg := range cr.Schema().CreateArgs {
+		names = append(names, arg.Name)
+	}
+	if got := strings.Join(names, ","); got != "slug,classify,type,set,base,pr_url" {
+		t.Errorf("create args = %s", got)
+	}
+
+	rec, err := cr.Create(testCtx(), "fix-login", map[string]string{
+		"type":   "pr",
+		"pr_url": "https://example.com/pr/1",
+		"set":    "priority=2\nlabels=auth, ui",
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if rec.Fields["type"] != "pr" || rec.Fields["pr_url"] != "https://example.com/pr/1" || rec.Fields["priority"] != 2 {
+		t.Errorf("fields = %v", rec.Fields)
+	}
+	data, err := os.ReadFile(rec.RawPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{"# fix login\n", "as pr (open) against main in unassigned.", "Priority 2.", "- auth\n", "- ui\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("case.md lacks %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "inputs:") {
+		t.Errorf("inputs declaration copied into case.md:\n%s", content)
+	}
+
+	// The default type has no template, so schema.md is used.
+	plain, err := cr.Create(testCtx(), "plain", nil)
+	if err != nil {
+		t.Fatalf("create without type: %v", err)
+	}
+	if data, _ := os.ReadFile(plain.RawPath); !strings.Contains(string(data), "# "+plain.ID+"\n") {
+		t.Errorf("schema.md case.md = %s", data)
+	}
+
+	tests := []struct {
+		name string
+		opts map[string]string
+		kind string
+	}{
+		{"missing input", map[string]string{"type": "pr"}, "missing_input"},
+		{"input of another type", map[string]string{"pr_url": "x"}, "unknown_input"},
+		{"type and classify", map[string]string{"type": "pr", "classify": "true"}, "usage"},
+		{"set without value", map[string]string{"set": "priority"}, "usage"},
+		{"set managed field", map[string]string{"set": "status=resolved"}, "usage"},
+		{"set wrong type", map[string]string{"set": "priority=high"}, "invalid_field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cr.Create(testCtx(), "refused", tt.opts)
+			var cliErr *agentops.CLIError
+			if !errors.As(err, &cliErr) || cliErr.Kind != tt.kind {
+				t.Fatalf("err = %v, want kind %s", err, tt.kind)
+			}
+		})
+	}
+	if matches, _ := filepath.Glob(filepath.Join(root, "cases", "*", "*", "*-refused*")); len(matches) != 0 {
+		t.Errorf("refused create left %v", matches)
+	}
+}
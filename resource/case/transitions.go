@@ -2,6 +2,8 @@ package caseresource
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/gh-xj/agentops/strategy"
 )
@@ -22,7 +24,24 @@ func (sm *StateMachine) Initial() string {
 }
 
 // Apply applies an action to the current status and returns the new status.
-func (sm *StateMachine) Apply(currentStatus, action string) (string, error) {
+// The action must be allowed from the current status and its guards met by
+// subject; unmet guards are reported as a *GuardError listing every unmet
+// condition.
+func (sm *StateMachine) Apply(currentStatus, action string, subject GuardSubject) (string, error) {
+	to, err := sm.target(currentStatus, action)
+	if err != nil {
+		return "", err
+	}
+	if err := sm.checkGuards(action, subject); err != nil {
+		return "", err
+	}
+	return to, nil
+}
+
+// target returns the status action leads to from currentStatus without
+// checking guards. It answers whether an action is possible at all; use Apply
+// to transition.
+func (sm *StateMachine) target(currentStatus, action string) (string, error) {
 	def, ok := sm.config.Transitions[action]
 	if !ok {
 		return "", fmt.Errorf("unknown action %q", action)
@@ -38,6 +57,87 @@ func (sm *StateMachine) Apply(currentStatus, action string) (string, error) {
 	return "", fmt.Errorf("action %q not allowed from status %q (allowed from: %v)", action, currentStatus, fromStates)
 }
 
+// GuardSubject exposes the case state transition guards inspect.
+type GuardSubject interface {
+	// FieldSet reports whether a frontmatter field is present and non-empty.
+	FieldSet(name string) bool
+	// SidecarExists reports whether a file relative to the case directory exists.
+	SidecarExists(path string) bool
+	// WorkerCompleted reports whether a worker completed on the case: its
+	// sidecar is present and valid. registered is false for unknown workers;
+	// otherwise why explains an incomplete worker.
+	WorkerCompleted(name string) (registered bool, why string)
+	// Note returns the note given with the transition.
+	Note() string
+}
+
+// GuardError lists the guard conditions a transition did not meet.
+type GuardError struct {
+	Action string
+	Unmet  []string
+}
+
+func (e *GuardError) Error() string {
+	return fmt.Sprintf("action %q guard not met: %s", e.Action, strings.Join(e.Unmet, "; "))
+}
+
+// checkGuards evaluates the guards of action against subject. It returns a
+// *GuardError listing every unmet condition, or nil when all are met.
+func (sm *StateMachine) checkGuards(action string, subject GuardSubject) error {
+	g := sm.config.Transitions[action].Guards
+
+	var unmet []string
+	for _, f := range g.Fields {
+		if !subject.FieldSet(f) {
+			unmet = append(unmet, fmt.Sprintf("field %s is empty", f))
+		}
+	}
+	for _, p := range g.Sidecars {
+		if !subject.SidecarExists(p) {
+			unmet = append(unmet, fmt.Sprintf("sidecar %s is missing", p))
+		}
+	}
+	for _, w := range g.Workers {
+		registered, why := subject.WorkerCompleted(w)
+		switch {
+		case !registered:
+			unmet = append(unmet, fmt.Sprintf("worker %s is not registered", w))
+		case why != "":
+			unmet = append(unmet, fmt.Sprintf("worker %s has not completed (%s)", w, why))
+		}
+	}
+	if g.Note && strings.TrimSpace(subject.Note()) == "" {
+		unmet = append(unmet, "a note is required (--note or --reason)")
+	}
+
+	if len(unmet) > 0 {
+		return &GuardError{Action: action, Unmet: unmet}
+	}
+	return nil
+}
+
+// ActionTo returns an action that moves a case from currentStatus to target.
+// Actions are checked in name order so the choice is deterministic.
+func (sm *StateMachine) ActionTo(currentStatus, target string) (string, bool) {
+	names := make([]string, 0, len(sm.config.Transitions))
+	for name := range sm.config.Transitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def := sm.config.Transitions[name]
+		if def.To != target {
+			continue
+		}
+		for _, s := range def.FromStates() {
+			if s == currentStatus {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
 // AllStatuses returns all known statuses from the categories config.
 func (sm *StateMachine) AllStatuses() []string {
 	var statuses []string
@@ -1,7 +1,9 @@
 package caseresource
 
 import (
+	"errors"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/gh-xj/agentops/strategy"
@@ -51,7 +53,7 @@ func TestStateMachineApplyValid(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.current+"_"+tt.action, func(t *testing.T) {
-			got, err := sm.Apply(tt.current, tt.action)
+			got, err := sm.Apply(tt.current, tt.action, fakeSubject{})
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -65,7 +67,7 @@ func TestStateMachineApplyValid(t *testing.T) {
 func TestStateMachineApplyInvalidAction(t *testing.T) {
 	sm := NewStateMachine(defaultTransitionsConfig())
 
-	_, err := sm.Apply("open", "nonexistent")
+	_, err := sm.Apply("open", "nonexistent", fakeSubject{})
 	if err == nil {
 		t.Fatal("expected error for unknown action")
 	}
@@ -75,7 +77,7 @@ func TestStateMachineApplyInvalidFromState(t *testing.T) {
 	sm := NewStateMachine(defaultTransitionsConfig())
 
 	// "start" only allows from "open", not "blocked"
-	_, err := sm.Apply("blocked", "start")
+	_, err := sm.Apply("blocked", "start", fakeSubject{})
 	if err == nil {
 		t.Fatal("expected error for invalid from state")
 	}
@@ -156,3 +158,81 @@ func TestStateMachineExpandStatusFilter(t *testing.T) {
 		t.Fatal("expected error for unknown filter")
 	}
 }
+
+func TestStateMachineActionTo(t *testing.T) {
+	sm := NewStateMachine(defaultTransitionsConfig())
+
+	action, ok := sm.ActionTo("in_progress", "blocked")
+	if !ok || action != "block" {
+		t.Errorf("ActionTo(in_progress, blocked) = %q, %v; want block, true", action, ok)
+	}
+
+	if _, ok := sm.ActionTo("resolved", "blocked"); ok {
+		t.Error("expected no action from resolved to blocked")
+	}
+}
+
+// fakeSubject is a GuardSubject backed by fixed data.
+type fakeSubject struct {
+	fields   map[string]bool
+	sidecars map[string]bool
+	workers  map[string]string
+	note     string
+}
+
+func (f fakeSubject) FieldSet(name string) bool      { return f.fields[name] }
+func (f fakeSubject) SidecarExists(path string) bool { return f.sidecars[path] }
+func (f fakeSubject) Note() string                   { return f.note }
+func (f fakeSubject) WorkerCompleted(name string) (bool, string) {
+	p, ok := f.workers[name]
+	if ok && !f.sidecars[p] {
+		return true, "no " + p
+	}
+	return ok, ""
+}
+
+func TestStateMachineApplyGuards(t *testing.T) {
+	cfg := defaultTransitionsConfig()
+	cfg.Transitions["resolve"] = strategy.TransitionDef{
+		From: "in_progress",
+		To:   "resolved",
+		Guards: strategy.TransitionGuards{
+			Fields:   []string{"resolution"},
+			Sidecars: []string{"findings.md"},
+			Workers:  []string{"review", "ghost"},
+			Note:     true,
+		},
+	}
+	sm := NewStateMachine(cfg)
+
+	_, err := sm.Apply("in_progress", "resolve", fakeSubject{workers: map[string]string{"review": "review.md"}})
+	var guardErr *GuardError
+	if !errors.As(err, &guardErr) {
+		t.Fatalf("Apply error = %v, want *GuardError", err)
+	}
+	want := []string{
+		"field resolution is empty",
+		"sidecar findings.md is missing",
+		"worker review has not completed (no review.md)",
+		"worker ghost is not registered",
+		"a note is required (--note or --reason)",
+	}
+	if strings.Join(guardErr.Unmet, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unmet:\n%s\nwant:\n%s", strings.Join(guardErr.Unmet, "\n"), strings.Join(want, "\n"))
+	}
+
+	met := fakeSubject{
+		fields:   map[string]bool{"resolution": true},
+		sidecars: map[string]bool{"findings.md": true, "review.md": true, "ghost.json": true},
+		workers:  map[string]string{"review": "review.md", "ghost": "ghost.json"},
+		note:     "fixed upstream",
+	}
+	if to, err := sm.Apply("in_progress", "resolve", met); err != nil || to != "resolved" {
+		t.Errorf("Apply with all guards met = %q, %v", to, err)
+	}
+
+	// Transitions without guards always pass.
+	if _, err := sm.Apply("open", "start", fakeSubject{}); err != nil {
+		t.Errorf("Apply(start): %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package resource
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Filter constrains which records are returned by List. Status and Slot are
+// shortcuts each resource interprets itself (a case status may name a
+// category); Where, Sort and Limit apply to every resource alike through
+// Match, Order and Apply.
+type Filter struct {
+	Status string
+	Slot   string
+	Where  []Predicate
+	Sort   []SortKey
+	Limit  int // 0 means no limit
+}
+
+// Predicate operators, longest first so ParsePredicate finds ">=" before ">".
+var predicateOps = []string{"!=", ">=", "<=", "=", ">", "<", "~"}
+
+// Predicate is one field comparison in a filter expression, such as type=pr,
+// created>=20260101 or body~"timeout".
+//
+// = and != compare exactly (a list field matches when any element does).
+// <, <=, > and >= compare numerically when both sides are numbers and as
+// strings otherwise, so 20060102 and ISO dates order correctly. ~ matches a
+// case-insensitive substring. A missing field compares as "".
+type Predicate struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	Value string `json:"value"`
+}
+
+// ParsePredicate parses a "<field><op><value>" expression. The value may be
+// wrapped in double quotes.
+func ParsePredicate(expr string) (Predicate, error) {
+	at, op := -1, ""
+	for _, candidate := range predicateOps {
+		if i := strings.Index(expr, candidate); i >= 0 && (at < 0 || i < at) {
+			at, op = i, candidate
+		}
+	}
+	if at < 0 {
+		return Predicate{}, fmt.Errorf("filter %q: expected <field><op><value> with op one of %s", expr, strings.Join(predicateOps, " "))
+	}
+	field := strings.TrimSpace(expr[:at])
+	if field == "" {
+		return Predicate{}, fmt.Errorf("filter %q: missing field name", expr)
+	}
+	value := strings.TrimSpace(expr[at+len(op):])
+	if unquoted, err := strconv.Unquote(value); err == nil && strings.HasPrefix(value, `"`) {
+		value = unquoted
+	}
+	return Predicate{Field: field, Op: op, Value: value}, nil
+}
+
+// String returns the expression p was parsed from.
+func (p Predicate) String() string {
+	return p.Field + p.Op + p.Value
+}
+
+// Match reports whether fields satisfy p.
+func (p Predicate) Match(fields map[string]any) bool {
+	values := fieldStrings(fields[p.Field])
+	switch p.Op {
+	case "=":
+		return slices.Contains(values, p.Value)
+	case "!=":
+		return !slices.Contains(values, p.Value)
+	case "~":
+		needle := strings.ToLower(p.Value)
+		return slices.ContainsFunc(values, func(v string) bool {
+			return strings.Contains(strings.ToLower(v), needle)
+		})
+	}
+	return slices.ContainsFunc(values, func(v string) bool {
+		c := compareValues(v, p.Value)
+		switch p.Op {
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		case ">=":
+			return c >= 0
+		}
+		return false
+	})
+}
+
+// SortKey orders records by one field.
+type SortKey struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// ParseSort parses a comma-separated list of fields; a leading "-" sorts a
+// field in descending order, e.g. "-priority,created".
+func ParseSort(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key := SortKey{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if key.Field == "" {
+			return nil, fmt.Errorf("sort %q: missing field name", spec)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Match reports whether fields satisfy every Where predicate.
+func (f Filter) Match(fields map[string]any) bool {
+	for _, p := range f.Where {
+		if !p.Match(fields) {
+			return false
+		}
+	}
+	return true
+}
+
+// References reports whether a Where predicate or sort key names field.
+// Resources use it to compute costly fields, such as a case body, only when
+// asked.
+func (f Filter) References(field string) bool {
+	return slices.ContainsFunc(f.Where, func(p Predicate) bool { return p.Field == field }) ||
+		slices.ContainsFunc(f.Sort, func(k SortKey) bool { return k.Field == field })
+}
+
+// Order sorts records by Sort, keeping the original order among equals and
+// putting records without the field last, then applies Limit.
+func (f Filter) Order(records []Record) []Record {
+	if len(f.Sort) > 0 {
+		slices.SortStableFunc(records, func(a, b Record) int {
+			for _, key := range f.Sort {
+				av, bv := sortValue(a, key.Field), sortValue(b, key.Field)
+				switch {
+				case av == bv:
+					continue
+				case av == "":
+					return 1
+				case bv == "":
+					return -1
+				}
+				c := compareValues(av, bv)
+				if key.Desc {
+					c = -c
+				}
+				if c != 0 {
+					return c
+				}
+			}
+			return 0
+		})
+	}
+	if f.Limit > 0 && len(records) > f.Limit {
+		records = records[:f.Limit]
+	}
+	return records
+}
+
+// Apply keeps the records whose fields match every Where predicate, then
+// orders and limits them.
+func (f Filter) Apply(records []Record) []Record {
+	kept := records[:0]
+	for _, rec := range records {
+		if f.Match(recordFields(rec)) {
+			kept = append(kept, rec)
+		}
+	}
+	return f.Order(kept)
+}
+
+// recordFields returns rec's fields with its ID available as "id".
+func recordFields(rec Record) map[string]any {
+	if _, ok := rec.Fields["id"]; ok {
+		return rec.Fields
+	}
+	fields := make(map[string]any, len(rec.Fields)+1)
+	for k, v := range rec.Fields {
+		fields[k] = v
+	}
+	fields["id"] = rec.ID
+	return fields
+}
+
+// sortValue returns the first value of a record field as a string.
+func sortValue(rec Record, field string) string {
+	values := fieldStrings(recordFields(rec)[field])
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// fieldStrings renders a field value as strings: one per list element, or
+// a single one for a scalar. A missing field is "".
+func fieldStrings(v any) []string {
+	switch t := v.(type) {
+	case nil:
+		return []string{""}
+	case []string:
+		return t
+	case []any:
+		out := make([]string, len(t))
+		for i, e := range t {
+			out[i] = fmt.Sprint(e)
+		}
+		return out
+	}
+	return []string{fmt.Sprint(v)}
+}
+
+// compareValues compares numerically when both sides parse as numbers and
+// as strings otherwise.
+func compareValues(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
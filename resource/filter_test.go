@@ -0,0 +1,112 @@
+package resource
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePredicate(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Predicate
+	}{
+		{"type=pr", Predicate{"type", "=", "pr"}},
+		{"claimed_by!=none", Predicate{"claimed_by", "!=", "none"}},
+		{"created>=20260101", Predicate{"created", ">=", "20260101"}},
+		{"created<20260101", Predicate{"created", "<", "20260101"}},
+		{`body~"timeout = 30s"`, Predicate{"body", "~", "timeout = 30s"}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePredicate(tt.expr)
+		if err != nil {
+			t.Fatalf("ParsePredicate(%q): %v", tt.expr, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParsePredicate(%q) = %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"type", "=pr"} {
+		if _, err := ParsePredicate(bad); err == nil {
+			t.Errorf("ParsePredicate(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestPredicateMatch(t *testing.T) {
+	fields := map[string]any{
+		"type":       "pr",
+		"created":    "20260315",
+		"priority":   2,
+		"claimed_by": "none",
+		"labels":     []any{"auth", "db"},
+		"body":       "The job hit a Timeout after 30s.",
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"type=pr", true},
+		{"type!=pr", false},
+		{"created>=20260101", true},
+		{"created<20260101", false},
+		{"priority>10", false}, // numeric, not string, comparison
+		{"claimed_by!=none", false},
+		{"labels=db", true},
+		{"labels=ui", false},
+		{"body~timeout", true},
+		{"missing=", true},
+		{"missing!=x", true},
+		{"missing>0", false},
+	}
+	for _, tt := range tests {
+		p, err := ParsePredicate(tt.expr)
+		if err != nil {
+			t.Fatalf("ParsePredicate(%q): %v", tt.expr, err)
+		}
+		if got := p.Match(fields); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	rec := func(id, typ string, priority any) Record {
+		fields := map[string]any{"type": typ}
+		if priority != nil {
+			fields["priority"] = priority
+		}
+		return Record{ID: id, Fields: fields}
+	}
+	records := []Record{
+		rec("a", "bug", 1),
+		rec("b", "pr", 3),
+		rec("c", "bug", nil),
+		rec("d", "bug", 3),
+		rec("e", "bug", 10),
+	}
+	sortKeys, err := ParseSort("-priority,id")
+	if err != nil {
+		t.Fatalf("ParseSort: %v", err)
+	}
+	f := Filter{
+		Where: []Predicate{{Field: "type", Op: "=", Value: "bug"}},
+		Sort:  sortKeys,
+		Limit: 3,
+	}
+	var ids []string
+	for _, r := range f.Apply(records) {
+		ids = append(ids, r.ID)
+	}
+	if want := []string{"e", "d", "a"}; !slices.Equal(ids, want) {
+		t.Errorf("Apply = %v, want %v", ids, want)
+	}
+
+	// Records without the sort field go last, even in descending order.
+	ids = nil
+	for _, r := range (Filter{Sort: sortKeys}).Order([]Record{rec("x", "bug", nil), rec("y", "bug", 1)}) {
+		ids = append(ids, r.ID)
+	}
+	if want := []string{"y", "x"}; !slices.Equal(ids, want) {
+		t.Errorf("Order = %v, want %v", ids, want)
+	}
+}
@@ -45,10 +45,26 @@ func (f *realFS) ReadDir(path string) ([]dal.DirEntry, error) {
 	return result, nil
 }
 
+func (f *realFS) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
 func (f *realFS) BaseName(path string) string {
 	return filepath.Base(path)
 }
 
+func (f *realFS) CreateExclusive(path string, perm int) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, os.FileMode(perm))
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func (f *realFS) Remove(path string) error {
+	return os.Remove(path)
+}
+
 // realExec implements dal.Executor using real os/exec.
 type realExec struct{}
 
@@ -216,7 +232,7 @@ func TestProjectCreateDirExists(t *testing.T) {
 func TestProjectListEmpty(t *testing.T) {
 	pr, ctx := newTestResource(t)
 
-	records, err := pr.List(ctx, nil)
+	records, err := pr.List(ctx, resource.Filter{})
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
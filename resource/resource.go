@@ -10,9 +10,6 @@ type Record struct {
 	RawPath string         `json:"raw_path,omitempty"`
 }
 
-// Filter constrains which records are returned by List.
-type Filter map[string]string
-
 // ResourceSchema describes the shape and rules of a resource kind.
 type ResourceSchema struct {
 	Kind        string
@@ -29,11 +26,13 @@ type FieldDef struct {
 	Required bool
 }
 
-// ArgDef describes one argument accepted by Create.
+// ArgDef describes one argument accepted by Create. Required arguments are
+// positional; optional ones become flags and reach Create through opts.
 type ArgDef struct {
 	Name        string
 	Description string
 	Required    bool
+	Type        string // "string" (default), "bool" or "list"; bool options are passed as "true", list values joined by newlines
 }
 
 // Resource is the core interface every agentops resource kind must implement.
@@ -86,6 +85,6 @@ type DoctorCheck struct {
 type PruneResult struct {
 	Name   string `json:"name"`
 	Path   string `json:"path"`
-	Action string `json:"action"` // removed, would_remove, skipped
+	Action string `json:"action"` // removed, would_remove, archived, would_archive, skipped
 	Reason string `json:"reason"`
 }
@@ -0,0 +1,26 @@
+package slotresource_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	slotresource "github.com/gh-xj/agentops/resource/slot"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// TestSlotSchemaUpToDate guards schemas/strategy-slot.schema.json; regenerate
+// it with `agentops strategy schema --out schemas`.
+func TestSlotSchemaUpToDate(t *testing.T) {
+	want, err := strategy.GenerateSchema("slot.yaml", slotresource.SlotConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join("..", "..", "schemas", strategy.SchemaFileName("slot.yaml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Error("schemas/strategy-slot.schema.json is stale")
+	}
+}
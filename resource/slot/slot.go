@@ -14,6 +14,11 @@ import (
 
 var slotNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
 
+// ValidName reports whether name is an acceptable slot name.
+func ValidName(name string) bool {
+	return slotNamePattern.MatchString(name)
+}
+
 // SlotResource implements Resource, Deleter, Syncer, Doctor, and Pruner for git worktree slots.
 type SlotResource struct {
 	fs   dal.FileSystem
@@ -110,7 +115,7 @@ func (s *SlotResource) Create(ctx *agentops.AppContext, slug string, opts map[st
 	return infoToRecord(info), nil
 }
 
-// List returns all slots for the project.
+// List returns the project's slots matching filter.
 func (s *SlotResource) List(ctx *agentops.AppContext, filter resource.Filter) ([]resource.Record, error) {
 	projectDir, cfg, err := s.loadConfig(ctx)
 	if err != nil {
@@ -126,7 +131,7 @@ func (s *SlotResource) List(ctx *agentops.AppContext, filter resource.Filter) ([
 	for _, info := range infos {
 		records = append(records, *infoToRecord(info))
 	}
-	return records, nil
+	return filter.Apply(records), nil
 }
 
 // Get returns a single slot by name.
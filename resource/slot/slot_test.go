@@ -77,10 +77,26 @@ func (f *realFS) ReadDir(path string) ([]dal.DirEntry, error) {
 	return result, nil
 }
 
+func (f *realFS) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
 func (f *realFS) BaseName(path string) string {
 	return filepath.Base(path)
 }
 
+func (f *realFS) CreateExclusive(path string, perm int) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, os.FileMode(perm))
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func (f *realFS) Remove(path string) error {
+	return os.Remove(path)
+}
+
 // realExec implements dal.Executor using real os/exec.
 type realExec struct{}
 
@@ -210,7 +226,7 @@ func TestSlotList(t *testing.T) {
 		t.Fatalf("Create beta: %v", err)
 	}
 
-	records, err := sr.List(ctx, nil)
+	records, err := sr.List(ctx, resource.Filter{})
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
@@ -277,7 +293,7 @@ func TestSlotDelete(t *testing.T) {
 	}
 
 	// Verify it's not in list anymore
-	records, err := sr.List(ctx, nil)
+	records, err := sr.List(ctx, resource.Filter{})
 	if err != nil {
 		t.Fatalf("List after delete: %v", err)
 	}
@@ -774,7 +790,7 @@ func TestPruneDryRun(t *testing.T) {
 	}
 
 	// Verify worktree still exists (dry-run)
-	records, err := sr.List(ctx, nil)
+	records, err := sr.List(ctx, resource.Filter{})
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
@@ -809,7 +825,7 @@ func TestPruneConfirm(t *testing.T) {
 	}
 
 	// Verify worktree is gone
-	records, err := sr.List(ctx, nil)
+	records, err := sr.List(ctx, resource.Filter{})
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
@@ -847,7 +863,7 @@ func TestPruneSkipsDirty(t *testing.T) {
 	}
 
 	// Verify worktree still exists
-	records, err := sr.List(ctx, nil)
+	records, err := sr.List(ctx, resource.Filter{})
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
@@ -876,6 +892,43 @@ func TestReadMarker(t *testing.T) {
 	}
 }
 
+func TestCurrentSlotCustomMarker(t *testing.T) {
+	dir := t.TempDir()
+	fs := &realFS{}
+
+	if got := CurrentSlot(nil, fs, dir); got != "" {
+		t.Errorf("CurrentSlot (no marker) = %q, want empty", got)
+	}
+
+	os.MkdirAll(filepath.Join(dir, ".agentops"), 0o755)
+	os.WriteFile(filepath.Join(dir, ".agentops", "slot.yaml"), []byte("marker_file: .worktree\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, ".worktree"), []byte("beta\n"), 0o644)
+	if got := CurrentSlot(nil, fs, dir); got != "beta" {
+		t.Errorf("CurrentSlot = %q, want %q", got, "beta")
+	}
+}
+
+func TestCurrentSlotFromWorktreeSubdir(t *testing.T) {
+	repoDir := setupGitRepo(t)
+	sr, ctx := newTestResource(t, repoDir)
+
+	rec, err := sr.Create(ctx, "gamma", nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	wtPath := rec.Fields["path"].(string)
+	sub := filepath.Join(wtPath, "nested", "dir")
+	os.MkdirAll(sub, 0o755)
+
+	fs := &realFS{}
+	if got := CurrentSlot(nil, fs, sub); got != "gamma" {
+		t.Errorf("CurrentSlot(worktree subdir) = %q, want %q", got, "gamma")
+	}
+	if got := CurrentSlot(nil, fs, repoDir); got != "" {
+		t.Errorf("CurrentSlot(main repo) = %q, want empty", got)
+	}
+}
+
 // currentBranch returns the current branch name in the repo.
 func currentBranch(t *testing.T, repoDir string) string {
 	t.Helper()
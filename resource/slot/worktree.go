@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	agentops "github.com/gh-xj/agentops"
 	"github.com/gh-xj/agentops/dal"
 )
 
@@ -374,3 +375,72 @@ func ReadMarker(fs dal.FileSystem, path, markerFile string) string {
 	s := strings.TrimSpace(string(data))
 	return s
 }
+
+// ContextKey is the AppContext value that names the slot a command acts as,
+// overriding the slot marker.
+const ContextKey = "slot"
+
+// CurrentSlot returns the slot a command acts as: the slot named by ctx's
+// ContextKey value, otherwise the slot dir belongs to, or "" when dir is not
+// inside a slot worktree. ctx may be nil. The marker is read from the root of
+// the worktree containing dir; its filename comes from the slot.yaml of the
+// main repository, located with FindRepoRoot. Outside any git repository dir
+// itself is treated as the worktree root.
+func CurrentSlot(ctx *agentops.AppContext, fs dal.FileSystem, dir string) string {
+	if ctx != nil {
+		if v, ok := ctx.Values[ContextKey].(string); ok && v != "" {
+			return v
+		}
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	wt := worktreeRoot(fs, absDir)
+	repoRoot, err := FindRepoRoot(fs, wt)
+	if err != nil {
+		repoRoot = wt
+	}
+	cfg, err := LoadSlotConfig(fs, filepath.Join(repoRoot, ".agentops"), repoRoot)
+	if err != nil {
+		return ""
+	}
+	return ReadMarker(fs, wt, cfg.MarkerFile)
+}
+
+// MainPath maps dir inside a slot worktree to the same path in the main
+// repository's checkout, so that state every slot shares, such as the case
+// store, is found from one place. Outside a worktree it returns dir, made
+// absolute.
+func MainPath(fs dal.FileSystem, dir string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	wt := worktreeRoot(fs, absDir)
+	repoRoot, err := FindRepoRoot(fs, wt)
+	if err != nil || repoRoot == wt {
+		return absDir
+	}
+	rel, err := filepath.Rel(wt, absDir)
+	if err != nil {
+		return absDir
+	}
+	return filepath.Join(repoRoot, rel)
+}
+
+// worktreeRoot returns the root of the git worktree containing absDir: the
+// nearest directory with a .git entry. Outside any repository it returns
+// absDir.
+func worktreeRoot(fs dal.FileSystem, absDir string) string {
+	for d := absDir; ; {
+		if fs.Exists(filepath.Join(d, ".git")) {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return absDir
+		}
+		d = parent
+	}
+}
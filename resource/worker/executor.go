@@ -0,0 +1,212 @@
+package workerresource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gh-xj/agentops/dal"
+)
+
+// maxOutput bounds the captured worker output kept in a Result.
+const maxOutput = 800
+
+// Result records the outcome of running one worker against a case.
+type Result struct {
+	Worker      string `json:"worker"`
+	Blocking    bool   `json:"blocking"`
+	OK          bool   `json:"ok"`
+	Skipped     bool   `json:"skipped,omitempty"`
+	TimedOut    bool   `json:"timed_out,omitempty"`
+	ExitCode    int    `json:"exit_code"`
+	DurationMs  int64  `json:"duration_ms"`
+	SidecarPath string `json:"sidecar_path"`
+	Sidecar     string `json:"sidecar,omitempty"`
+	Output      string `json:"output,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Executor runs workers against a case directory.
+type Executor struct {
+	fs          dal.FileSystem
+	registry    *Registry
+	skillRunner string
+
+	// Timeout stops a worker that runs longer; zero means no limit.
+	Timeout time.Duration
+	// Admit, when set, is called before each worker runs. An error stops
+	// the run: the worker is reported as skipped and the error is returned.
+	Admit func(w Worker) error
+}
+
+// NewExecutor creates an Executor. skillRunner is the command prefix used for
+// workers that do not declare a command (the skill_runner from hooks.yaml).
+func NewExecutor(fs dal.FileSystem, registry *Registry, skillRunner string) *Executor {
+	return &Executor{fs: fs, registry: registry, skillRunner: skillRunner}
+}
+
+// Run executes the named workers, plus anything they require, in dependency
+// order. A worker whose requirement did not complete is skipped, and one the
+// registry reports findings for, such as a sidecar-path outside the case
+// directory, fails without running. Workers must write a sidecar that parses
+// and matches their sidecar-schema, and must not touch case.md; any violation
+// fails the worker, and a case.md change is reverted.
+func (x *Executor) Run(caseDir string, names []string) ([]Result, error) {
+	ordered, err := x.registry.Order(names)
+	if err != nil {
+		return nil, err
+	}
+
+	completed := make(map[string]bool)
+	results := make([]Result, 0, len(ordered))
+	for _, w := range ordered {
+		res := Result{Worker: w.Name, Blocking: w.Blocking, SidecarPath: w.SidecarPath}
+		if missing := firstIncomplete(w.Requires, completed); missing != "" {
+			res.Skipped = true
+			res.Error = fmt.Sprintf("required worker %q did not complete", missing)
+			results = append(results, res)
+			continue
+		}
+		if findings := x.registry.ValidateWorker(w.Name); len(findings) > 0 {
+			msgs := make([]string, len(findings))
+			for i, f := range findings {
+				msgs[i] = f.Message
+			}
+			res.Error = "invalid worker: " + strings.Join(msgs, "; ")
+			res.ExitCode = -1
+			results = append(results, res)
+			continue
+		}
+		if x.Admit != nil {
+			if err := x.Admit(w); err != nil {
+				res.Skipped = true
+				res.Error = err.Error()
+				return append(results, res), err
+			}
+		}
+		x.runOne(caseDir, w, &res)
+		completed[w.Name] = res.OK
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// runOne executes a single worker and fills in res.
+func (x *Executor) runOne(caseDir string, w Worker, res *Result) {
+	caseMD := filepath.Join(caseDir, "case.md")
+	before, _ := x.fs.ReadFile(caseMD)
+	sidecar := filepath.Join(caseDir, w.SidecarPath)
+	if err := x.fs.EnsureDir(filepath.Dir(sidecar)); err != nil {
+		res.Error = err.Error()
+		res.ExitCode = -1
+		return
+	}
+
+	ctx := context.Background()
+	if x.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, x.Timeout)
+		defer cancel()
+	}
+	cmd, err := x.command(ctx, w)
+	if err != nil {
+		res.Error = err.Error()
+		res.ExitCode = -1
+		return
+	}
+	cmd.Dir = caseDir
+	cmd.Env = append(os.Environ(),
+		"AGENTOPS_CASE_DIR="+caseDir,
+		"AGENTOPS_WORKER="+w.Name,
+		"AGENTOPS_WORKER_TYPE="+w.Type,
+		"AGENTOPS_SIDECAR_PATH="+sidecar,
+		"AGENTOPS_SKILL_PATH="+w.Path,
+	)
+	var buf bytes.Buffer
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+	// Children of sh may keep the output open after a timeout kill.
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	runErr := cmd.Run()
+	res.DurationMs = time.Since(start).Milliseconds()
+	res.Output = tail(strings.TrimSpace(buf.String()), maxOutput)
+
+	if after, _ := x.fs.ReadFile(caseMD); !bytes.Equal(before, after) {
+		_ = x.fs.WriteFile(caseMD, before, 0o644)
+		res.Error = "worker modified case.md (change reverted)"
+		res.ExitCode = -1
+		return
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		res.TimedOut = true
+		res.Error = fmt.Sprintf("worker timed out after %s", x.Timeout)
+		res.ExitCode = -1
+		return
+	}
+	if runErr != nil {
+		res.Error = runErr.Error()
+		res.ExitCode = 1
+		if exitErr, ok := runErr.(*exec.ExitError); ok {
+			res.ExitCode = exitErr.ExitCode()
+		}
+		return
+	}
+	data, err := x.fs.ReadFile(sidecar)
+	if err != nil {
+		res.Error = fmt.Sprintf("worker did not write sidecar %s", w.SidecarPath)
+		return
+	}
+	res.Sidecar = string(data)
+	problems, err := w.CheckSidecar(x.fs, data)
+	if err != nil {
+		res.Error = fmt.Sprintf("sidecar %s: %s", w.SidecarPath, err)
+		return
+	}
+	if len(problems) > 0 {
+		res.Error = fmt.Sprintf("sidecar %s is invalid: %s", w.SidecarPath, strings.Join(problems, "; "))
+		return
+	}
+	res.OK = true
+}
+
+// command builds the process for a worker.
+func (x *Executor) command(ctx context.Context, w Worker) (*exec.Cmd, error) {
+	if w.Command != "" {
+		return exec.CommandContext(ctx, "sh", "-c", w.Command), nil
+	}
+	if strings.TrimSpace(x.skillRunner) == "" {
+		return nil, fmt.Errorf("worker %q has no command and no skill_runner is configured", w.Name)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", x.skillRunner+" "+shellQuote(w.Path)), nil
+}
+
+// firstIncomplete returns the first requirement that has not completed.
+func firstIncomplete(requires []string, completed map[string]bool) string {
+	for _, dep := range requires {
+		if !completed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
+
+// shellQuote single-quotes s for safe use in a sh -c command line.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// tail keeps the last max bytes of s.
+func tail(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[len(s)-max:]
+}
@@ -0,0 +1,420 @@
+package workerresource
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/sidecar"
+	"gopkg.in/yaml.v3"
+)
+
+// Worker sources, in discovery order.
+const (
+	SourceAgentops = "agentops" // legacy .agentops/workers/<name>/SKILL.md
+	SourceClaude   = "claude"   // project-local .claude/skills/<name>/SKILL.md
+)
+
+// validWorkerTypes lists the worker-type values from protocol/worker.md.
+var validWorkerTypes = map[string]bool{
+	"review": true, "verify": true, "challenge": true,
+	"reflect": true, "triage": true, "custom": true,
+}
+
+// validCapabilities lists the capability values from protocol/worker.md.
+var validCapabilities = map[string]bool{
+	"read-only": true, "can-edit": true, "can-run-commands": true,
+}
+
+// Worker is a worker declared by SKILL.md frontmatter.
+type Worker struct {
+	Name          string   `yaml:"-"`
+	Type          string   `yaml:"worker-type"`
+	SidecarPath   string   `yaml:"sidecar-path"`
+	SidecarSchema string   `yaml:"sidecar-schema"` // optional JSON Schema, relative to the SKILL.md directory
+	Blocking      bool     `yaml:"blocking"`
+	Requires      []string `yaml:"requires"`
+	Capabilities  []string `yaml:"capabilities"`
+	Command       string   `yaml:"command"` // optional shell command; otherwise run through skill_runner
+	Path          string   `yaml:"-"`       // absolute path to SKILL.md
+	Source        string   `yaml:"-"`
+}
+
+// Registry holds the workers discovered for a project.
+type Registry struct {
+	workers    map[string]Worker
+	names      []string
+	duplicates []Worker
+}
+
+// workerDirs returns the directories scanned for worker skills, in order.
+func workerDirs(root string) []struct{ dir, source string } {
+	return []struct{ dir, source string }{
+		{filepath.Join(root, ".agentops", "workers"), SourceAgentops},
+		{filepath.Join(root, ".claude", "skills"), SourceClaude},
+	}
+}
+
+// SkillPath resolves the SKILL.md of a named skill under root, looking in the
+// worker directories in the same order Load does. It returns "" if none exists.
+func SkillPath(fs dal.FileSystem, root, name string) string {
+	for _, loc := range workerDirs(root) {
+		p := filepath.Join(loc.dir, name, "SKILL.md")
+		if fs.Exists(p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// Load discovers workers under root. Skills without a worker-type are not
+// workers and are ignored. When a name is declared in both locations the
+// first one found wins and the other is reported by Validate.
+func Load(fs dal.FileSystem, root string) (*Registry, error) {
+	reg := &Registry{workers: make(map[string]Worker)}
+	for _, loc := range workerDirs(root) {
+		entries, err := fs.ReadDir(loc.dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir {
+				continue
+			}
+			path := filepath.Join(loc.dir, entry.Name, "SKILL.md")
+			data, err := fs.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			w, ok, err := parseWorker(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			if !ok {
+				continue
+			}
+			w.Name = entry.Name
+			w.Path = path
+			w.Source = loc.source
+			if _, exists := reg.workers[w.Name]; exists {
+				reg.duplicates = append(reg.duplicates, w)
+				continue
+			}
+			reg.workers[w.Name] = w
+			reg.names = append(reg.names, w.Name)
+		}
+	}
+	sort.Strings(reg.names)
+	return reg, nil
+}
+
+// parseWorker reads worker frontmatter from SKILL.md content. ok is false when
+// the file has no frontmatter or does not declare a worker-type.
+func parseWorker(content string) (Worker, bool, error) {
+	var w Worker
+	if !strings.HasPrefix(content, "---\n") {
+		return w, false, nil
+	}
+	rest := content[4:]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return w, false, fmt.Errorf("unterminated YAML frontmatter")
+	}
+	if err := yaml.Unmarshal([]byte(rest[:end]), &w); err != nil {
+		return w, false, fmt.Errorf("parse frontmatter: %w", err)
+	}
+	return w, w.Type != "", nil
+}
+
+// SchemaPath returns the absolute path of the worker's sidecar schema, or ""
+// when it declares none.
+func (w Worker) SchemaPath() string {
+	if w.SidecarSchema == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(w.Path), w.SidecarSchema)
+}
+
+// LoadSchema reads the worker's sidecar schema. It returns nil when the
+// worker declares none.
+func (w Worker) LoadSchema(fs dal.FileSystem) (sidecar.Schema, error) {
+	path := w.SchemaPath()
+	if path == "" {
+		return nil, nil
+	}
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read sidecar schema: %w", err)
+	}
+	s, err := sidecar.ParseSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", w.SidecarSchema, err)
+	}
+	return s, nil
+}
+
+// CheckSidecar returns why data is not a valid sidecar for the worker: it
+// does not parse by the sidecar-path extension, or it breaks the worker's
+// sidecar-schema. err is set when the schema itself cannot be loaded.
+func (w Worker) CheckSidecar(fs dal.FileSystem, data []byte) ([]string, error) {
+	v, structured, err := sidecar.Decode(w.SidecarPath, data)
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+	schema, err := w.LoadSchema(fs)
+	if err != nil || schema == nil || !structured {
+		return nil, err
+	}
+	return schema.Validate(v), nil
+}
+
+// Get returns the worker with the given name.
+func (r *Registry) Get(name string) (Worker, bool) {
+	w, ok := r.workers[name]
+	return w, ok
+}
+
+// All returns every worker, sorted by name.
+func (r *Registry) All() []Worker {
+	out := make([]Worker, 0, len(r.names))
+	for _, name := range r.names {
+		out = append(out, r.workers[name])
+	}
+	return out
+}
+
+// scopedFinding is a finding together with the workers it concerns.
+type scopedFinding struct {
+	workers []string
+	finding agentops.DoctorFinding
+}
+
+// Validate checks every worker and the requires graph. Findings are returned
+// in a deterministic order.
+func (r *Registry) Validate() []agentops.DoctorFinding {
+	var findings []agentops.DoctorFinding
+	for _, sf := range r.findings() {
+		findings = append(findings, sf.finding)
+	}
+	return findings
+}
+
+// ValidateWorker returns the findings that concern the named worker,
+// including registry-wide checks such as cycles it takes part in.
+func (r *Registry) ValidateWorker(name string) []agentops.DoctorFinding {
+	var findings []agentops.DoctorFinding
+	for _, sf := range r.findings() {
+		for _, w := range sf.workers {
+			if w == name {
+				findings = append(findings, sf.finding)
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// findings runs every check and tags each finding with the workers involved.
+func (r *Registry) findings() []scopedFinding {
+	var out []scopedFinding
+	for _, name := range r.names {
+		for _, f := range r.validateWorker(r.workers[name]) {
+			out = append(out, scopedFinding{[]string{name}, f})
+		}
+	}
+	for _, dup := range r.duplicates {
+		out = append(out, scopedFinding{[]string{dup.Name}, agentops.DoctorFinding{
+			Code:    "duplicate_worker",
+			Path:    dup.Path,
+			Message: fmt.Sprintf("worker %q is already declared at %s", dup.Name, r.workers[dup.Name].Path),
+		}})
+	}
+	out = append(out, r.sidecarConflicts()...)
+	for _, cycle := range r.cycles() {
+		out = append(out, scopedFinding{cycle[:len(cycle)-1], agentops.DoctorFinding{
+			Code:    "requires_cycle",
+			Path:    r.workers[cycle[0]].Path,
+			Message: "circular requires: " + strings.Join(cycle, " -> "),
+		}})
+	}
+	return out
+}
+
+// validateWorker checks a single worker's frontmatter.
+func (r *Registry) validateWorker(w Worker) []agentops.DoctorFinding {
+	var findings []agentops.DoctorFinding
+	add := func(code, msg string) {
+		findings = append(findings, agentops.DoctorFinding{Code: code, Path: w.Path, Message: msg})
+	}
+	if !validWorkerTypes[w.Type] {
+		add("invalid_worker_type", fmt.Sprintf("worker %q has unknown worker-type %q", w.Name, w.Type))
+	}
+	if w.SidecarPath == "" {
+		add("missing_field", fmt.Sprintf("worker %q is missing sidecar-path", w.Name))
+	} else if filepath.IsAbs(w.SidecarPath) || strings.HasPrefix(filepath.Clean(w.SidecarPath), "..") {
+		add("invalid_sidecar_path", fmt.Sprintf("worker %q sidecar-path %q must be relative to the case directory", w.Name, w.SidecarPath))
+	} else if filepath.Clean(w.SidecarPath) == "case.md" {
+		add("invalid_sidecar_path", fmt.Sprintf("worker %q must not write to case.md", w.Name))
+	}
+	if filepath.IsAbs(w.SidecarSchema) {
+		add("invalid_sidecar_schema", fmt.Sprintf("worker %q sidecar-schema %q must be relative to its SKILL.md", w.Name, w.SidecarSchema))
+	}
+	for _, c := range w.Capabilities {
+		if !validCapabilities[c] {
+			add("invalid_capability", fmt.Sprintf("worker %q has unknown capability %q", w.Name, c))
+		}
+	}
+	for _, dep := range w.Requires {
+		if _, ok := r.workers[dep]; !ok {
+			add("unknown_requires", fmt.Sprintf("worker %q requires unknown worker %q", w.Name, dep))
+		}
+	}
+	return findings
+}
+
+// sidecarConflicts reports workers that share a sidecar-path.
+func (r *Registry) sidecarConflicts() []scopedFinding {
+	var findings []scopedFinding
+	owners := make(map[string]string)
+	for _, name := range r.names {
+		w := r.workers[name]
+		if w.SidecarPath == "" {
+			continue
+		}
+		key := filepath.Clean(w.SidecarPath)
+		if owner, ok := owners[key]; ok {
+			findings = append(findings, scopedFinding{[]string{owner, w.Name}, agentops.DoctorFinding{
+				Code:    "duplicate_sidecar_path",
+				Path:    w.Path,
+				Message: fmt.Sprintf("workers %q and %q share sidecar-path %q", owner, w.Name, w.SidecarPath),
+			}})
+			continue
+		}
+		owners[key] = name
+	}
+	return findings
+}
+
+// cycles returns each distinct cycle in the requires graph, starting from its
+// alphabetically first member.
+func (r *Registry) cycles() [][]string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	seen := make(map[string]bool)
+	var stack []string
+	var found [][]string
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range r.workers[name].Requires {
+			if _, ok := r.workers[dep]; !ok {
+				continue
+			}
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				start := 0
+				for i, n := range stack {
+					if n == dep {
+						start = i
+					}
+				}
+				cycle := canonicalCycle(stack[start:])
+				key := strings.Join(cycle, ",")
+				if !seen[key] {
+					seen[key] = true
+					found = append(found, append(cycle, cycle[0]))
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+	}
+	for _, name := range r.names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+	return found
+}
+
+// canonicalCycle rotates a cycle so it starts at its smallest member.
+func canonicalCycle(cycle []string) []string {
+	min := 0
+	for i, n := range cycle {
+		if n < cycle[min] {
+			min = i
+		}
+	}
+	out := make([]string, 0, len(cycle))
+	out = append(out, cycle[min:]...)
+	return append(out, cycle[:min]...)
+}
+
+// Order resolves names plus their transitive requires into dependency order.
+// Ties are broken alphabetically so the order is deterministic.
+func (r *Registry) Order(names []string) ([]Worker, error) {
+	need := make(map[string]bool)
+	var collect func(name string) error
+	collect = func(name string) error {
+		if need[name] {
+			return nil
+		}
+		w, ok := r.workers[name]
+		if !ok {
+			return fmt.Errorf("unknown worker %q", name)
+		}
+		need[name] = true
+		for _, dep := range w.Requires {
+			if err := collect(dep); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		return nil
+	}
+	for _, name := range names {
+		if err := collect(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var ordered []Worker
+	placed := make(map[string]bool)
+	for len(placed) < len(need) {
+		var ready []string
+		for name := range need {
+			if placed[name] {
+				continue
+			}
+			ok := true
+			for _, dep := range r.workers[name].Requires {
+				if !placed[dep] {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				ready = append(ready, name)
+			}
+		}
+		if len(ready) == 0 {
+			return nil, fmt.Errorf("circular requires among workers")
+		}
+		sort.Strings(ready)
+		for _, name := range ready {
+			placed[name] = true
+			ordered = append(ordered, r.workers[name])
+		}
+	}
+	return ordered, nil
+}
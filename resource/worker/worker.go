@@ -0,0 +1,241 @@
+package workerresource
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/resource"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+var workerNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// WorkerResource implements the Resource and Validator interfaces for worker skills.
+type WorkerResource struct {
+	fs    dal.FileSystem
+	exec  dal.Executor
+	strat *strategy.Strategy
+
+	loadErr error // why strat is nil, reported by commands that need it
+}
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource  = (*WorkerResource)(nil)
+	_ resource.Validator = (*WorkerResource)(nil)
+	_ resource.Doctor    = (*WorkerResource)(nil)
+)
+
+// New creates a new WorkerResource.
+func New(fs dal.FileSystem, exec dal.Executor, strat *strategy.Strategy) *WorkerResource {
+	return &WorkerResource{fs: fs, exec: exec, strat: strat}
+}
+
+// SetLoadError records why the strategy could not be loaded. Commands that
+// need a strategy return it instead of a generic strategy_missing error.
+func (wr *WorkerResource) SetLoadError(err error) {
+	wr.loadErr = err
+}
+
+// Schema returns the resource schema for workers.
+func (wr *WorkerResource) Schema() resource.ResourceSchema {
+	return resource.ResourceSchema{
+		Kind:        "worker",
+		Description: "A case worker declared by SKILL.md frontmatter",
+		Fields: []resource.FieldDef{
+			{Name: "name", Type: "string", Required: true},
+			{Name: "worker_type", Type: "string", Required: true},
+			{Name: "sidecar_path", Type: "string", Required: true},
+			{Name: "sidecar_schema", Type: "string", Required: false},
+			{Name: "blocking", Type: "bool", Required: false},
+			{Name: "requires", Type: "list", Required: false},
+			{Name: "capabilities", Type: "list", Required: false},
+			{Name: "source", Type: "string", Required: false},
+		},
+		CreateArgs: []resource.ArgDef{
+			{Name: "name", Description: "Worker name (lowercase alphanumeric with hyphens)", Required: true},
+			{Name: "worker_type", Description: "review|verify|challenge|reflect|triage|custom (default custom)", Required: false},
+			{Name: "sidecar_path", Description: "Sidecar path relative to the case directory (default <name>.json)", Required: false},
+		},
+	}
+}
+
+// Registry loads the worker registry for the current strategy.
+func (wr *WorkerResource) Registry() (*Registry, error) {
+	if wr.strat == nil {
+		return nil, strategy.Missing(wr.loadErr)
+	}
+	return Load(wr.fs, wr.strat.Root)
+}
+
+// Executor returns an Executor for the current strategy's workers.
+func (wr *WorkerResource) Executor() (*Executor, error) {
+	reg, err := wr.Registry()
+	if err != nil {
+		return nil, err
+	}
+	return NewExecutor(wr.fs, reg, wr.strat.Hooks.SkillRunner), nil
+}
+
+// Create scaffolds .claude/skills/<name>/SKILL.md with worker frontmatter.
+func (wr *WorkerResource) Create(ctx *agentops.AppContext, slug string, opts map[string]string) (*resource.Record, error) {
+	if wr.strat == nil {
+		return nil, strategy.Missing(wr.loadErr)
+	}
+	if !workerNamePattern.MatchString(slug) {
+		return nil, fmt.Errorf("invalid worker name %q: must match ^[a-z][a-z0-9-]*$", slug)
+	}
+	reg, err := wr.Registry()
+	if err != nil {
+		return nil, err
+	}
+	if existing, ok := reg.Get(slug); ok {
+		return nil, fmt.Errorf("worker %q already exists at %s", slug, existing.Path)
+	}
+
+	w := Worker{
+		Name:        slug,
+		Type:        opts["worker_type"],
+		SidecarPath: opts["sidecar_path"],
+		Source:      SourceClaude,
+	}
+	if w.Type == "" {
+		w.Type = "custom"
+	}
+	if w.SidecarPath == "" {
+		w.SidecarPath = slug + ".json"
+	}
+	if !validWorkerTypes[w.Type] {
+		return nil, fmt.Errorf("invalid worker-type %q", w.Type)
+	}
+
+	dir := filepath.Join(wr.strat.Root, ".claude", "skills", slug)
+	if err := wr.fs.EnsureDir(dir); err != nil {
+		return nil, fmt.Errorf("create worker dir: %w", err)
+	}
+	w.Path = filepath.Join(dir, "SKILL.md")
+	content := fmt.Sprintf(`---
+worker-type: %s
+sidecar-path: %s
+blocking: false
+requires: []
+capabilities: [read-only]
+---
+# %s
+
+Describe what this worker checks. Write results to the sidecar path
+($AGENTOPS_SIDECAR_PATH); never edit case.md.
+`, w.Type, w.SidecarPath, slug)
+	if err := wr.fs.WriteFile(w.Path, []byte(content), 0o644); err != nil {
+		return nil, fmt.Errorf("write SKILL.md: %w", err)
+	}
+	w.Capabilities = []string{"read-only"}
+	return workerToRecord(w), nil
+}
+
+// List returns the discovered workers matching filter.
+func (wr *WorkerResource) List(ctx *agentops.AppContext, filter resource.Filter) ([]resource.Record, error) {
+	reg, err := wr.Registry()
+	if err != nil {
+		return nil, err
+	}
+	workers := reg.All()
+	records := make([]resource.Record, 0, len(workers))
+	for _, w := range workers {
+		records = append(records, *workerToRecord(w))
+	}
+	return filter.Apply(records), nil
+}
+
+// Get returns a single worker by name.
+func (wr *WorkerResource) Get(ctx *agentops.AppContext, id string) (*resource.Record, error) {
+	reg, err := wr.Registry()
+	if err != nil {
+		return nil, err
+	}
+	w, ok := reg.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("worker %q not found", id)
+	}
+	return workerToRecord(w), nil
+}
+
+// Validate checks a worker's frontmatter and the parts of the registry-wide
+// checks (requires cycles, sidecar collisions) that involve it.
+func (wr *WorkerResource) Validate(ctx *agentops.AppContext, id string) (*agentops.DoctorReport, error) {
+	reg, err := wr.Registry()
+	if err != nil {
+		return nil, err
+	}
+	w, ok := reg.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("worker %q not found", id)
+	}
+
+	report := &agentops.DoctorReport{
+		SchemaVersion: "1.0",
+		OK:            true,
+	}
+	if findings := reg.ValidateWorker(w.Name); len(findings) > 0 {
+		report.OK = false
+		report.Findings = findings
+	}
+	if _, err := w.LoadSchema(wr.fs); err != nil {
+		report.OK = false
+		report.Findings = append(report.Findings, agentops.DoctorFinding{
+			Code:    "invalid_sidecar_schema",
+			Path:    w.Path,
+			Message: fmt.Sprintf("worker %q: %s", w.Name, err),
+		})
+	}
+	return report, nil
+}
+
+// Doctor runs the registry-wide checks: every worker's frontmatter, duplicate
+// declarations, shared sidecar paths and requires cycles.
+func (wr *WorkerResource) Doctor(ctx *agentops.AppContext) ([]resource.DoctorCheck, error) {
+	reg, err := wr.Registry()
+	if err != nil {
+		return nil, err
+	}
+	var checks []resource.DoctorCheck
+	for _, f := range reg.Validate() {
+		checks = append(checks, resource.DoctorCheck{
+			Name:     f.Path,
+			Status:   f.Code,
+			Message:  f.Message,
+			Severity: "err",
+		})
+	}
+	return checks, nil
+}
+
+// workerToRecord converts a Worker to a resource.Record.
+func workerToRecord(w Worker) *resource.Record {
+	requires := w.Requires
+	if requires == nil {
+		requires = []string{}
+	}
+	capabilities := w.Capabilities
+	if capabilities == nil {
+		capabilities = []string{}
+	}
+	return &resource.Record{
+		Kind: "worker",
+		ID:   w.Name,
+		Fields: map[string]any{
+			"name":           w.Name,
+			"worker_type":    w.Type,
+			"sidecar_path":   w.SidecarPath,
+			"sidecar_schema": w.SidecarSchema,
+			"blocking":       w.Blocking,
+			"requires":       requires,
+			"capabilities":   capabilities,
+			"source":         w.Source,
+		},
+		RawPath: w.Path,
+	}
+}
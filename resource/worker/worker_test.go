@@ -0,0 +1,337 @@
+package workerresource
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/dal"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// setupTestProject creates a temp dir with .agentops/ bootstrapped.
+func setupTestProject(t *testing.T) (string, *strategy.Strategy) {
+	t.Helper()
+	tmp := t.TempDir()
+	if err := strategy.Bootstrap(tmp); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	return tmp, strat
+}
+
+// writeSkill writes a SKILL.md with the given frontmatter under dir/<name>.
+func writeSkill(t *testing.T, dir, name, frontmatter string) {
+	t.Helper()
+	skillDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\n" + frontmatter + "---\n# " + name + "\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testCtx() *agentops.AppContext {
+	return agentops.NewAppContext(context.Background())
+}
+
+func TestLoadDiscoversBothLocations(t *testing.T) {
+	root, _ := setupTestProject(t)
+	legacy := filepath.Join(root, ".agentops", "workers")
+	skills := filepath.Join(root, ".claude", "skills")
+	writeSkill(t, legacy, "lint", "worker-type: verify\nsidecar-path: lint.json\n")
+	writeSkill(t, skills, "review", "worker-type: review\nsidecar-path: review.md\nrequires: [lint]\n")
+	writeSkill(t, skills, "notes", "description: not a worker\n")
+
+	reg, err := Load(dal.NewFileSystem(), root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	all := reg.All()
+	if len(all) != 2 {
+		t.Fatalf("workers = %d, want 2 (non-worker skill ignored)", len(all))
+	}
+	lint, ok := reg.Get("lint")
+	if !ok || lint.Source != SourceAgentops {
+		t.Errorf("lint = %+v, want source %q", lint, SourceAgentops)
+	}
+	review, _ := reg.Get("review")
+	if review.Source != SourceClaude || len(review.Requires) != 1 {
+		t.Errorf("review = %+v", review)
+	}
+	if findings := reg.Validate(); len(findings) != 0 {
+		t.Errorf("unexpected findings: %+v", findings)
+	}
+}
+
+func TestRegistryValidateFindings(t *testing.T) {
+	root, _ := setupTestProject(t)
+	skills := filepath.Join(root, ".claude", "skills")
+	writeSkill(t, skills, "a", "worker-type: review\nsidecar-path: out.json\nrequires: [b]\n")
+	writeSkill(t, skills, "b", "worker-type: verify\nsidecar-path: out.json\nrequires: [a]\n")
+	writeSkill(t, skills, "c", "worker-type: bogus\nsidecar-path: c.json\nrequires: [missing]\n")
+
+	reg, err := Load(dal.NewFileSystem(), root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	codes := make(map[string]bool)
+	for _, f := range reg.Validate() {
+		codes[f.Code] = true
+	}
+	for _, want := range []string{"requires_cycle", "duplicate_sidecar_path", "invalid_worker_type", "unknown_requires"} {
+		if !codes[want] {
+			t.Errorf("missing finding %q in %v", want, codes)
+		}
+	}
+
+	for _, f := range reg.ValidateWorker("c") {
+		if f.Code == "requires_cycle" || f.Code == "duplicate_sidecar_path" {
+			t.Errorf("worker c should not report %q", f.Code)
+		}
+	}
+	if _, err := reg.Order([]string{"a"}); err == nil {
+		t.Error("Order should fail on a requires cycle")
+	}
+}
+
+func TestRegistryOrder(t *testing.T) {
+	root, _ := setupTestProject(t)
+	skills := filepath.Join(root, ".claude", "skills")
+	writeSkill(t, skills, "reflect", "worker-type: reflect\nsidecar-path: reflect.md\nrequires: [review, verify]\n")
+	writeSkill(t, skills, "review", "worker-type: review\nsidecar-path: review.md\n")
+	writeSkill(t, skills, "verify", "worker-type: verify\nsidecar-path: verify.json\nrequires: [review]\n")
+
+	reg, err := Load(dal.NewFileSystem(), root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	ordered, err := reg.Order([]string{"reflect"})
+	if err != nil {
+		t.Fatalf("Order: %v", err)
+	}
+	var names []string
+	for _, w := range ordered {
+		names = append(names, w.Name)
+	}
+	if got := strings.Join(names, ","); got != "review,verify,reflect" {
+		t.Errorf("order = %s, want review,verify,reflect", got)
+	}
+}
+
+func TestExecutorRun(t *testing.T) {
+	root, _ := setupTestProject(t)
+	skills := filepath.Join(root, ".claude", "skills")
+	writeSkill(t, skills, "ok", "worker-type: verify\nsidecar-path: ok.json\ncommand: echo '{\"pass\":true}' > \"$AGENTOPS_SIDECAR_PATH\"\n")
+	writeSkill(t, skills, "fail", "worker-type: review\nsidecar-path: fail.md\nblocking: true\ncommand: exit 3\n")
+	writeSkill(t, skills, "after", "worker-type: reflect\nsidecar-path: after.md\nrequires: [fail]\ncommand: touch after.md\n")
+	writeSkill(t, skills, "editor", "worker-type: custom\nsidecar-path: editor.md\ncommand: echo x >> case.md && touch editor.md\n")
+
+	caseDir := filepath.Join(root, "case")
+	if err := os.MkdirAll(caseDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	caseMD := filepath.Join(caseDir, "case.md")
+	if err := os.WriteFile(caseMD, []byte("original\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := dal.NewFileSystem()
+	reg, err := Load(fs, root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	results, err := NewExecutor(fs, reg, "").Run(caseDir, []string{"ok", "after", "editor"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	byName := make(map[string]Result)
+	for _, r := range results {
+		byName[r.Worker] = r
+	}
+	if r := byName["ok"]; !r.OK || !strings.Contains(r.Sidecar, `"pass":true`) {
+		t.Errorf("ok = %+v", r)
+	}
+	if r := byName["fail"]; r.OK || r.ExitCode != 3 || !r.Blocking {
+		t.Errorf("fail = %+v", r)
+	}
+	if r := byName["after"]; !r.Skipped {
+		t.Errorf("after should be skipped when its requirement failed: %+v", r)
+	}
+	if r := byName["editor"]; r.OK || !strings.Contains(r.Error, "case.md") {
+		t.Errorf("editor = %+v", r)
+	}
+	if data, _ := os.ReadFile(caseMD); string(data) != "original\n" {
+		t.Errorf("case.md = %q, want change reverted", data)
+	}
+}
+
+func TestExecutorRunChecksSidecarSchema(t *testing.T) {
+	root, _ := setupTestProject(t)
+	skills := filepath.Join(root, ".claude", "skills")
+	writeSkill(t, skills, "good", "worker-type: verify\nsidecar-path: good.json\nsidecar-schema: schema.json\ncommand: echo '{\"verdict\":\"pass\"}' > \"$AGENTOPS_SIDECAR_PATH\"\n")
+	writeSkill(t, skills, "bad", "worker-type: verify\nsidecar-path: bad.json\nsidecar-schema: schema.json\ncommand: echo '{\"verdict\":1}' > \"$AGENTOPS_SIDECAR_PATH\"\n")
+	schema := `{"type": "object", "required": ["verdict"], "properties": {"verdict": {"type": "string"}}}`
+	for _, name := range []string{"good", "bad"} {
+		if err := os.WriteFile(filepath.Join(skills, name, "schema.json"), []byte(schema), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	caseDir := filepath.Join(root, "case")
+	if err := os.MkdirAll(caseDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := dal.NewFileSystem()
+	reg, err := Load(fs, root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	results, err := NewExecutor(fs, reg, "").Run(caseDir, []string{"good", "bad"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, r := range results {
+		switch r.Worker {
+		case "good":
+			if !r.OK {
+				t.Errorf("good = %+v", r)
+			}
+		case "bad":
+			if r.OK || !strings.Contains(r.Error, "/verdict: expected string") {
+				t.Errorf("bad = %+v, want a schema error", r)
+			}
+		}
+	}
+}
+
+func TestExecutorRunRefusesInvalidWorkers(t *testing.T) {
+	root, _ := setupTestProject(t)
+	skills := filepath.Join(root, ".claude", "skills")
+	writeSkill(t, skills, "escape", "worker-type: verify\nsidecar-path: ../escape.json\ncommand: touch ran && echo '{}' > \"$AGENTOPS_SIDECAR_PATH\"\n")
+	writeSkill(t, skills, "after", "worker-type: review\nsidecar-path: after.json\nrequires: [escape]\ncommand: echo '{}' > after.json\n")
+	caseDir := filepath.Join(root, "case")
+	if err := os.MkdirAll(caseDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := dal.NewFileSystem()
+	reg, err := Load(fs, root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	admitted := 0
+	x := NewExecutor(fs, reg, "")
+	x.Admit = func(Worker) error {
+		admitted++
+		return nil
+	}
+	results, err := x.Run(caseDir, []string{"after"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %+v", results)
+	}
+	if r := results[0]; r.Worker != "escape" || r.OK || r.Skipped || !strings.Contains(r.Error, "must be relative to the case directory") {
+		t.Errorf("escape = %+v, want refused as invalid", r)
+	}
+	if r := results[1]; r.Worker != "after" || !r.Skipped {
+		t.Errorf("after = %+v, want skipped", r)
+	}
+	if admitted != 0 {
+		t.Errorf("invalid worker was admitted")
+	}
+	for _, path := range []string{filepath.Join(caseDir, "ran"), filepath.Join(root, "escape.json")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("invalid worker ran: %s exists", path)
+		}
+	}
+}
+
+func TestExecutorTimeoutAndAdmit(t *testing.T) {
+	root, _ := setupTestProject(t)
+	skills := filepath.Join(root, ".claude", "skills")
+	writeSkill(t, skills, "hang", "worker-type: verify\nsidecar-path: hang.json\ncommand: sleep 5 && touch hang.json\n")
+	writeSkill(t, skills, "next", "worker-type: review\nsidecar-path: next.json\ncommand: touch next.json\n")
+	caseDir := filepath.Join(root, "case")
+	if err := os.MkdirAll(caseDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := dal.NewFileSystem()
+	reg, err := Load(fs, root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	x := NewExecutor(fs, reg, "")
+	x.Timeout = 100 * time.Millisecond
+	admitted := 0
+	x.Admit = func(w Worker) error {
+		if admitted == 1 {
+			return errors.New("no budget left")
+		}
+		admitted++
+		return nil
+	}
+
+	start := time.Now()
+	results, err := x.Run(caseDir, []string{"hang", "next"})
+	if err == nil || err.Error() != "no budget left" {
+		t.Fatalf("Run error = %v, want admit error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("timed out worker ran for %s", elapsed)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %+v", results)
+	}
+	if r := results[0]; r.Worker != "hang" || r.OK || !r.TimedOut {
+		t.Errorf("hang = %+v", r)
+	}
+	if r := results[1]; r.Worker != "next" || !r.Skipped || r.Error != "no budget left" {
+		t.Errorf("next = %+v", r)
+	}
+}
+
+func TestWorkerResourceCreateAndGet(t *testing.T) {
+	_, strat := setupTestProject(t)
+	wr := New(dal.NewFileSystem(), dal.NewExecutor(), strat)
+	ctx := testCtx()
+
+	rec, err := wr.Create(ctx, "security-review", map[string]string{"worker_type": "review"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if rec.Fields["sidecar_path"] != "security-review.json" {
+		t.Errorf("sidecar_path = %v", rec.Fields["sidecar_path"])
+	}
+	if _, err := wr.Create(ctx, "security-review", nil); err == nil {
+		t.Error("expected error creating a duplicate worker")
+	}
+
+	got, err := wr.Get(ctx, "security-review")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Fields["worker_type"] != "review" || got.Fields["source"] != SourceClaude {
+		t.Errorf("Get fields = %+v", got.Fields)
+	}
+	report, err := wr.Validate(ctx, "security-review")
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !report.OK {
+		t.Errorf("Validate findings = %+v", report.Findings)
+	}
+}
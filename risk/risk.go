@@ -0,0 +1,172 @@
+// Package risk scores cases against the rules a strategy declares in
+// risk.yaml and maps the score to a risk level and its escalation.
+package risk
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// Input is the case data risk rules match against.
+type Input struct {
+	Type   string
+	Paths  []string       // paths the case touches
+	Labels []string       // case labels
+	Fields map[string]any // case frontmatter
+}
+
+// Assessment is the outcome of scoring a case.
+type Assessment struct {
+	Level      string   `json:"level"`
+	Score      int      `json:"score"`
+	Matched    []string `json:"matched,omitempty"`    // names of the rules that matched
+	Transition string   `json:"transition,omitempty"` // action forced by escalation
+	Workers    []string `json:"workers,omitempty"`    // workers required by escalation
+}
+
+// Assess scores in against cfg's rules and resolves the level and the
+// escalation it triggers. Escalations of every level up to and including the
+// assessed one apply; the highest level's transition wins.
+func Assess(cfg strategy.RiskConfig, in Input) Assessment {
+	var a Assessment
+	for i, rule := range cfg.Rules {
+		if !matches(rule.Match, in) {
+			continue
+		}
+		a.Score += rule.Score
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rule-%d", i+1)
+		}
+		a.Matched = append(a.Matched, name)
+	}
+
+	levels := cfg.LevelNames()
+	a.Level = levels[0]
+	for _, level := range levels[1:] {
+		if min, ok := cfg.Thresholds[level]; ok && a.Score >= min {
+			a.Level = level
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, level := range levels {
+		esc, ok := cfg.Escalation[level]
+		if ok {
+			if esc.Transition != "" {
+				a.Transition = esc.Transition
+			}
+			for _, w := range esc.Workers {
+				if !seen[w] {
+					seen[w] = true
+					a.Workers = append(a.Workers, w)
+				}
+			}
+		}
+		if level == a.Level {
+			break
+		}
+	}
+	sort.Strings(a.Workers)
+	return a
+}
+
+// matches reports whether every non-empty criterion of m holds for in.
+func matches(m strategy.RiskMatch, in Input) bool {
+	if len(m.Types) > 0 && !contains(m.Types, in.Type) {
+		return false
+	}
+	if len(m.Paths) > 0 && !anyGlob(m.Paths, in.Paths) {
+		return false
+	}
+	if len(m.Labels) > 0 && !anyEqual(m.Labels, in.Labels) {
+		return false
+	}
+	for key, pattern := range m.Fields {
+		if !anyGlob([]string{pattern}, values(in.Fields[key])) {
+			return false
+		}
+	}
+	return true
+}
+
+// values flattens a frontmatter value into the strings a pattern is tried
+// against: each entry of a list, or the value itself.
+func values(v any) []string {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, e := range t {
+			out = append(out, fmt.Sprint(e))
+		}
+		return out
+	}
+	return []string{fmt.Sprint(v)}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func anyEqual(want, have []string) bool {
+	for _, h := range have {
+		if contains(want, h) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyGlob(patterns, have []string) bool {
+	for _, p := range patterns {
+		for _, h := range have {
+			if Glob(p, h) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Glob reports whether value matches pattern. * and ? stay within a path
+// segment; ** matches across segments, so "internal/**" matches every path
+// below internal/ and "**/*.sql" every .sql file.
+func Glob(pattern, value string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				// "**/" also matches no directory at all.
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	return err == nil && re.MatchString(value)
+}
@@ -0,0 +1,134 @@
+package risk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func testConfig() strategy.RiskConfig {
+	return strategy.RiskConfig{
+		Thresholds: map[string]int{"medium": 3, "high": 6, "critical": 10},
+		Rules: []strategy.RiskRule{
+			{Name: "auth", Score: 5, Match: strategy.RiskMatch{Types: []string{"pr"}, Paths: []string{"internal/auth/**"}}},
+			{Name: "migration", Score: 3, Match: strategy.RiskMatch{Paths: []string{"**/*.sql"}}},
+			{Name: "security", Score: 2, Match: strategy.RiskMatch{Labels: []string{"security"}}},
+			{Name: "linked", Score: 1, Match: strategy.RiskMatch{Fields: map[string]string{"linear_ref": "SEC-*"}}},
+		},
+		Escalation: map[string]strategy.RiskEscalation{
+			"medium":   {Workers: []string{"review"}},
+			"high":     {Workers: []string{"security-review", "review"}, Transition: "block"},
+			"critical": {Transition: "escalate"},
+		},
+	}
+}
+
+func TestAssess(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         Input
+		level      string
+		score      int
+		matched    string
+		transition string
+		workers    string
+	}{
+		{
+			name:  "no match",
+			in:    Input{Type: "intake", Paths: []string{"README.md"}},
+			level: "low",
+		},
+		{
+			name:    "type and path must both match",
+			in:      Input{Type: "intake", Paths: []string{"internal/auth/login.go"}},
+			level:   "low",
+			matched: "",
+		},
+		{
+			name:    "medium",
+			in:      Input{Type: "intake", Paths: []string{"db/001_init.sql"}},
+			level:   "medium",
+			score:   3,
+			matched: "migration",
+			workers: "review",
+		},
+		{
+			name:       "high escalates cumulatively",
+			in:         Input{Type: "pr", Paths: []string{"internal/auth/token/jwt.go"}, Labels: []string{"security"}},
+			level:      "high",
+			score:      7,
+			matched:    "auth,security",
+			transition: "block",
+			workers:    "review,security-review",
+		},
+		{
+			name: "critical takes the highest transition",
+			in: Input{
+				Type:   "pr",
+				Paths:  []string{"internal/auth/a.go", "schema.sql"},
+				Labels: []string{"security"},
+				Fields: map[string]any{"linear_ref": "SEC-12"},
+			},
+			level:      "critical",
+			score:      11,
+			matched:    "auth,migration,security,linked",
+			transition: "escalate",
+			workers:    "review,security-review",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Assess(testConfig(), tt.in)
+			if a.Level != tt.level || a.Score != tt.score {
+				t.Errorf("level/score = %s/%d, want %s/%d", a.Level, a.Score, tt.level, tt.score)
+			}
+			if got := strings.Join(a.Matched, ","); got != tt.matched {
+				t.Errorf("matched = %q, want %q", got, tt.matched)
+			}
+			if a.Transition != tt.transition {
+				t.Errorf("transition = %q, want %q", a.Transition, tt.transition)
+			}
+			if got := strings.Join(a.Workers, ","); got != tt.workers {
+				t.Errorf("workers = %q, want %q", got, tt.workers)
+			}
+		})
+	}
+}
+
+func TestAssessCustomLevels(t *testing.T) {
+	cfg := strategy.RiskConfig{
+		Levels:     []string{"green", "red"},
+		Thresholds: map[string]int{"red": 1},
+		Rules:      []strategy.RiskRule{{Score: 1, Match: strategy.RiskMatch{Types: []string{"pr"}}}},
+	}
+	if a := Assess(cfg, Input{Type: "intake"}); a.Level != "green" {
+		t.Errorf("level = %q, want green", a.Level)
+	}
+	a := Assess(cfg, Input{Type: "pr"})
+	if a.Level != "red" || strings.Join(a.Matched, ",") != "rule-1" {
+		t.Errorf("assessment = %+v, want red matched by rule-1", a)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"internal/auth/**", "internal/auth/login.go", true},
+		{"internal/auth/**", "internal/auth/token/jwt.go", true},
+		{"internal/auth/**", "internal/authz/x.go", false},
+		{"**/*.sql", "schema.sql", true},
+		{"**/*.sql", "db/migrations/001.sql", true},
+		{"*.sql", "db/001.sql", false},
+		{"cmd/?/main.go", "cmd/a/main.go", true},
+		{"SEC-*", "SEC-12", true},
+		{"a.b", "axb", false},
+	}
+	for _, tt := range tests {
+		if got := Glob(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("Glob(%q, %q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package routing classifies cases into types using the cues a strategy
+// declares in routing.yaml and maps a case's type and risk level to the
+// workers that should run.
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gh-xj/agentops/risk"
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// Cue kinds reported in a Match.
+const (
+	CueKeyword = "keyword"
+	CuePath    = "path"
+	CueLabel   = "label"
+)
+
+// Input is the case data cues match against.
+type Input struct {
+	Text   string   // case title and body
+	Paths  []string // paths the case touches
+	Labels []string // case labels
+}
+
+// Match is one cue entry that matched a case.
+type Match struct {
+	Type    string `json:"type"`
+	Cue     string `json:"cue"`     // keyword, path or label
+	Pattern string `json:"pattern"` // the cue entry from routing.yaml
+	Value   string `json:"value"`   // what it matched; empty for keywords
+}
+
+// Classification is the outcome of classifying a case.
+type Classification struct {
+	Type    string  `json:"type"`
+	Default bool    `json:"default"` // no cue matched; Type is the default type
+	Score   int     `json:"score"`   // matched cue entries for Type
+	Matches []Match `json:"matches,omitempty"`
+}
+
+// Classify picks the type whose cues match the most entries. Ties go to the
+// higher priority, then to the type name in sort order. Matches lists every
+// matched entry, for every type, so callers can explain the choice.
+func Classify(cfg strategy.RoutingConfig, in Input) Classification {
+	text := strings.ToLower(in.Text)
+	types := make([]string, 0, len(cfg.Cues))
+	for t := range cfg.Cues {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	c := Classification{Type: cfg.DefaultType(), Default: true}
+	bestPriority := 0
+	for _, t := range types {
+		cue := cfg.Cues[t]
+		var matches []Match
+		for _, kw := range cue.Keywords {
+			if kw != "" && strings.Contains(text, strings.ToLower(kw)) {
+				matches = append(matches, Match{Type: t, Cue: CueKeyword, Pattern: kw})
+			}
+		}
+		for _, pattern := range cue.Paths {
+			for _, p := range in.Paths {
+				if risk.Glob(pattern, p) {
+					matches = append(matches, Match{Type: t, Cue: CuePath, Pattern: pattern, Value: p})
+					break
+				}
+			}
+		}
+		for _, label := range cue.Labels {
+			for _, l := range in.Labels {
+				if l == label {
+					matches = append(matches, Match{Type: t, Cue: CueLabel, Pattern: label, Value: l})
+					break
+				}
+			}
+		}
+		c.Matches = append(c.Matches, matches...)
+
+		score := len(matches)
+		if score == 0 {
+			continue
+		}
+		if c.Default || score > c.Score || (score == c.Score && cue.Priority > bestPriority) {
+			c.Type, c.Score, c.Default, bestPriority = t, score, false, cue.Priority
+		}
+	}
+	return c
+}
+
+// Workers returns the workers routed to a case of the given type and risk
+// level, and the routing.yaml entry that chose them: overrides.<type>.<risk>,
+// overrides.<type>.*, or default_route. It returns nil and "" when routing
+// selects nothing, meaning every registered worker runs.
+func Workers(cfg strategy.RoutingConfig, caseType, riskLevel string) ([]string, string) {
+	if byRisk, ok := cfg.Overrides[caseType]; ok {
+		if riskLevel != "" {
+			if workers, ok := byRisk[riskLevel]; ok {
+				return workers, "overrides." + caseType + "." + riskLevel
+			}
+		}
+		if workers, ok := byRisk[strategy.OverrideAnyRisk]; ok {
+			return workers, "overrides." + caseType + "." + strategy.OverrideAnyRisk
+		}
+	}
+	if len(cfg.DefaultRoute.Workers) > 0 {
+		return cfg.DefaultRoute.Workers, "default_route"
+	}
+	return nil, ""
+}
+
+// Explain summarises why Type was chosen, e.g.
+// `pr: keyword "pull request", path "cmd/**" (cmd/main.go)`.
+func (c Classification) Explain() string {
+	if c.Default {
+		return c.Type + ": no cue matched (default type)"
+	}
+	var parts []string
+	for _, m := range c.Matches {
+		if m.Type != c.Type {
+			continue
+		}
+		part := fmt.Sprintf("%s %q", m.Cue, m.Pattern)
+		if m.Value != "" && m.Value != m.Pattern {
+			part += " (" + m.Value + ")"
+		}
+		parts = append(parts, part)
+	}
+	return c.Type + ": " + strings.Join(parts, ", ")
+}
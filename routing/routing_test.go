@@ -0,0 +1,112 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func testConfig() strategy.RoutingConfig {
+	return strategy.RoutingConfig{
+		DefaultRoute: strategy.Route{Type: "intake", Workers: []string{"triage"}},
+		Cues: map[string]strategy.RoutingCue{
+			"pr":       {Keywords: []string{"Pull Request", "review"}, Paths: []string{"cmd/**"}},
+			"incident": {Keywords: []string{"outage", "review"}, Labels: []string{"sev1"}, Priority: 10},
+			"docs":     {Paths: []string{"**/*.md"}},
+		},
+		Overrides: map[string]map[string][]string{
+			"pr":       {"high": {"security-review", "review"}, strategy.OverrideAnyRisk: {"review"}},
+			"incident": {"low": {}},
+		},
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      Input
+		typ     string
+		def     bool
+		score   int
+		explain string
+	}{
+		{
+			name:    "no match falls back to default",
+			in:      Input{Text: "tidy up"},
+			typ:     "intake",
+			def:     true,
+			explain: "intake: no cue matched (default type)",
+		},
+		{
+			name:    "keywords are case-insensitive",
+			in:      Input{Text: "open a pull request", Paths: []string{"cmd/agentops/main.go"}},
+			typ:     "pr",
+			score:   2,
+			explain: `pr: keyword "Pull Request", path "cmd/**" (cmd/agentops/main.go)`,
+		},
+		{
+			name:    "tie goes to priority",
+			in:      Input{Text: "needs review"},
+			typ:     "incident",
+			score:   1,
+			explain: `incident: keyword "review"`,
+		},
+		{
+			name:  "higher score beats priority",
+			in:    Input{Text: "needs review", Paths: []string{"cmd/x.go"}},
+			typ:   "pr",
+			score: 2,
+		},
+		{
+			name:    "labels",
+			in:      Input{Labels: []string{"sev1"}},
+			typ:     "incident",
+			score:   1,
+			explain: `incident: label "sev1"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Classify(testConfig(), tt.in)
+			if c.Type != tt.typ || c.Default != tt.def || c.Score != tt.score {
+				t.Errorf("Classify = %+v, want type %s default %v score %d", c, tt.typ, tt.def, tt.score)
+			}
+			if tt.explain != "" && c.Explain() != tt.explain {
+				t.Errorf("Explain = %q, want %q", c.Explain(), tt.explain)
+			}
+		})
+	}
+}
+
+func TestClassifyDefaultType(t *testing.T) {
+	c := Classify(strategy.RoutingConfig{}, Input{Text: "anything"})
+	if c.Type != strategy.DefaultCaseType || !c.Default {
+		t.Errorf("Classify = %+v, want default %s", c, strategy.DefaultCaseType)
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	tests := []struct {
+		typ, risk string
+		workers   string
+		source    string
+	}{
+		{"pr", "high", "security-review,review", "overrides.pr.high"},
+		{"pr", "low", "review", "overrides.pr.*"},
+		{"pr", "", "review", "overrides.pr.*"},
+		{"incident", "low", "", "overrides.incident.low"},
+		{"incident", "high", "triage", "default_route"},
+		{"docs", "", "triage", "default_route"},
+	}
+	for _, tt := range tests {
+		workers, source := Workers(testConfig(), tt.typ, tt.risk)
+		if strings.Join(workers, ",") != tt.workers || source != tt.source {
+			t.Errorf("Workers(%s, %s) = %v, %q; want %s, %q", tt.typ, tt.risk, workers, source, tt.workers, tt.source)
+		}
+	}
+
+	if workers, source := Workers(strategy.RoutingConfig{}, "pr", "high"); workers != nil || source != "" {
+		t.Errorf("Workers without routing = %v, %q; want nil, empty", workers, source)
+	}
+}
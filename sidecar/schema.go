@@ -0,0 +1,206 @@
+package sidecar
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Schema is a JSON Schema document. Validate understands the keywords
+// sidecar schemas need: type, enum, const, required, properties,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// minimum and maximum. Other keywords are ignored.
+type Schema map[string]any
+
+// ParseSchema decodes a JSON Schema document.
+func ParseSchema(data []byte) (Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	return s, nil
+}
+
+// Validate returns one message per violation, each prefixed with the JSON
+// pointer of the offending value ("/" for the document itself).
+func (s Schema) Validate(v any) []string {
+	var out []string
+	validate(s, normalize(v), "", &out)
+	return out
+}
+
+func validate(s map[string]any, v any, at string, out *[]string) {
+	fail := func(format string, args ...any) {
+		ptr := at
+		if ptr == "" {
+			ptr = "/"
+		}
+		*out = append(*out, ptr+": "+fmt.Sprintf(format, args...))
+	}
+
+	if t, ok := s["type"]; ok {
+		var types []string
+		switch tt := t.(type) {
+		case string:
+			types = []string{tt}
+		case []any:
+			for _, e := range tt {
+				if name, ok := e.(string); ok {
+					types = append(types, name)
+				}
+			}
+		}
+		if !slices.ContainsFunc(types, func(name string) bool { return hasType(v, name) }) {
+			fail("expected %s, got %s", strings.Join(types, " or "), typeName(v))
+			return
+		}
+	}
+	if enum, ok := s["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+		fail("value %v is not one of %v", display(v), enum)
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, v) {
+		fail("value %v must be %v", display(v), c)
+	}
+
+	switch t := v.(type) {
+	case map[string]any:
+		props, _ := s["properties"].(map[string]any)
+		if required, ok := s["required"].([]any); ok {
+			for _, r := range required {
+				if name, ok := r.(string); ok {
+					if _, present := t[name]; !present {
+						fail("missing required property %q", name)
+					}
+				}
+			}
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := at + "/" + k
+			if ps, ok := props[k].(map[string]any); ok {
+				validate(ps, t[k], child, out)
+				continue
+			}
+			if _, declared := props[k]; declared {
+				continue
+			}
+			switch extra := s["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					fail("unexpected property %q", k)
+				}
+			case map[string]any:
+				validate(extra, t[k], child, out)
+			}
+		}
+	case []any:
+		if n, ok := number(s["minItems"]); ok && float64(len(t)) < n {
+			fail("expected at least %v items, got %d", n, len(t))
+		}
+		if n, ok := number(s["maxItems"]); ok && float64(len(t)) > n {
+			fail("expected at most %v items, got %d", n, len(t))
+		}
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, e := range t {
+				validate(items, e, fmt.Sprintf("%s/%d", at, i), out)
+			}
+		}
+	case string:
+		length := float64(len([]rune(t)))
+		if n, ok := number(s["minLength"]); ok && length < n {
+			fail("expected at least %v characters", n)
+		}
+		if n, ok := number(s["maxLength"]); ok && length > n {
+			fail("expected at most %v characters", n)
+		}
+	case float64:
+		if n, ok := number(s["minimum"]); ok && t < n {
+			fail("%v is less than the minimum %v", t, n)
+		}
+		if n, ok := number(s["maximum"]); ok && t > n {
+			fail("%v is greater than the maximum %v", t, n)
+		}
+	}
+}
+
+// normalize converts decoded YAML into the shapes encoding/json produces, so
+// YAML and JSON sidecars validate alike.
+func normalize(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, e := range t {
+			out[k] = normalize(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			out[i] = normalize(e)
+		}
+		return out
+	case int:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case uint64:
+		return float64(t)
+	}
+	return v
+}
+
+func hasType(v any, name string) bool {
+	switch name {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "null":
+		return v == nil
+	}
+	return false
+}
+
+func typeName(v any) string {
+	for _, name := range []string{"object", "array", "string", "boolean", "integer", "number", "null"} {
+		if hasType(v, name) {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func number(v any) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+// display quotes strings so messages read unambiguously.
+func display(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,41 @@
+// Package sidecar decodes the files workers write next to case.md and checks
+// them against the JSON Schema a worker declares.
+package sidecar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Decode parses sidecar data by its file extension: .json, .jsonl (one value
+// per line, returned as a list) and .yaml or .yml. Other files are not
+// structured and return structured=false without an error.
+func Decode(path string, data []byte) (v any, structured bool, err error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &v)
+	case ".jsonl":
+		var lines []any
+		for i, line := range bytes.Split(data, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var item any
+			if err := json.Unmarshal(line, &item); err != nil {
+				return nil, true, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			lines = append(lines, item)
+		}
+		v = lines
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &v)
+	default:
+		return nil, false, nil
+	}
+	return v, true, err
+}
@@ -0,0 +1,74 @@
+package sidecar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		path       string
+		data       string
+		want       any
+		structured bool
+		wantErr    bool
+	}{
+		{"review.json", `{"pass": true}`, map[string]any{"pass": true}, true, false},
+		{"review.json", `{"pass":`, nil, true, true},
+		{"log.jsonl", "{\"n\":1}\n\n{\"n\":2}\n", []any{map[string]any{"n": 1.0}, map[string]any{"n": 2.0}}, true, false},
+		{"log.jsonl", "{\"n\":1}\nnope\n", nil, true, true},
+		{"notes.yaml", "pass: true\n", map[string]any{"pass": true}, true, false},
+		{"notes.md", "# anything", nil, false, false},
+	}
+	for _, tt := range tests {
+		v, structured, err := Decode(tt.path, []byte(tt.data))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Decode(%s, %q) error = %v, wantErr %v", tt.path, tt.data, err, tt.wantErr)
+			continue
+		}
+		if structured != tt.structured {
+			t.Errorf("Decode(%s) structured = %v, want %v", tt.path, structured, tt.structured)
+		}
+		if !tt.wantErr && !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("Decode(%s, %q) = %#v, want %#v", tt.path, tt.data, v, tt.want)
+		}
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{
+		"type": "object",
+		"required": ["verdict", "findings"],
+		"additionalProperties": false,
+		"properties": {
+			"verdict": {"enum": ["pass", "fail"]},
+			"score": {"type": "integer", "minimum": 0, "maximum": 10},
+			"findings": {"type": "array", "items": {"type": "string", "minLength": 1}}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+
+	ok := map[string]any{"verdict": "pass", "score": 3, "findings": []any{"none"}}
+	if problems := schema.Validate(ok); len(problems) != 0 {
+		t.Errorf("valid sidecar: %v", problems)
+	}
+
+	bad := map[string]any{"verdict": "maybe", "score": 2.5, "findings": []any{""}, "extra": 1}
+	got := strings.Join(schema.Validate(bad), "\n")
+	for _, want := range []string{
+		`/verdict: value "maybe" is not one of [pass fail]`,
+		"/score: expected integer, got number",
+		"/findings/0: expected at least 1 characters",
+		`/: unexpected property "extra"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if got := schema.Validate([]any{}); len(got) != 1 || got[0] != "/: expected object, got array" {
+		t.Errorf("Validate(array) = %v", got)
+	}
+}
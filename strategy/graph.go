@@ -0,0 +1,94 @@
+package strategy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Graph formats accepted by RenderGraph.
+const (
+	GraphDOT     = "dot"
+	GraphMermaid = "mermaid"
+)
+
+// RenderGraph renders the lifecycle state machine in the given format.
+// Output is deterministic: categories and transitions are written in name
+// order and statuses in declaration order.
+func RenderGraph(cfg TransitionsConfig, format string) (string, error) {
+	switch format {
+	case GraphDOT:
+		return renderDOT(cfg), nil
+	case GraphMermaid:
+		return renderMermaid(cfg), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q (want %s or %s)", format, GraphDOT, GraphMermaid)
+}
+
+// edge is one arrow in the lifecycle graph.
+type edge struct {
+	from, to, label string
+}
+
+// edges returns the transitions as arrows, one per from status. Guarded
+// transitions are marked so the diagram shows where conditions apply.
+func edges(cfg TransitionsConfig) []edge {
+	var out []edge
+	for _, name := range sortedKeys(cfg.Transitions) {
+		def := cfg.Transitions[name]
+		if def.To == "" {
+			continue
+		}
+		label := name
+		if !def.Guards.Empty() {
+			label += " (guarded)"
+		}
+		for _, from := range def.FromStates() {
+			out = append(out, edge{from: from, to: def.To, label: label})
+		}
+	}
+	return out
+}
+
+func renderDOT(cfg TransitionsConfig) string {
+	var b strings.Builder
+	b.WriteString("digraph lifecycle {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+	for _, cat := range sortedKeys(cfg.Categories) {
+		fmt.Fprintf(&b, "  subgraph %q {\n", "cluster_"+cat)
+		fmt.Fprintf(&b, "    label=%q;\n", cat)
+		for _, status := range cfg.Categories[cat] {
+			if cat == TerminalCategory {
+				fmt.Fprintf(&b, "    %q [peripheries=2];\n", status)
+			} else {
+				fmt.Fprintf(&b, "    %q;\n", status)
+			}
+		}
+		b.WriteString("  }\n")
+	}
+	if cfg.Initial != "" {
+		b.WriteString("  \"__start\" [shape=point];\n")
+		fmt.Fprintf(&b, "  \"__start\" -> %q;\n", cfg.Initial)
+	}
+	for _, e := range edges(cfg) {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", e.from, e.to, e.label)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func renderMermaid(cfg TransitionsConfig) string {
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	b.WriteString("  direction LR\n")
+	if cfg.Initial != "" {
+		fmt.Fprintf(&b, "  [*] --> %s\n", cfg.Initial)
+	}
+	for _, e := range edges(cfg) {
+		fmt.Fprintf(&b, "  %s --> %s: %s\n", e.from, e.to, e.label)
+	}
+	for _, status := range cfg.Categories[TerminalCategory] {
+		fmt.Fprintf(&b, "  %s --> [*]\n", status)
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaBaseURL is where the checked-in schemas/ directory is published.
+// Default strategy files point editors at it with a yaml-language-server
+// header.
+const SchemaBaseURL = "https://raw.githubusercontent.com/gh-xj/agentops/main/schemas/"
+
+// SchemaTypes maps each strategy YAML file to the Go type it decodes into.
+// slot.yaml is owned by the slot resource and is added by callers.
+var SchemaTypes = map[string]any{
+	ManifestFile:       Manifest{},
+	"storage.yaml":     StorageConfig{},
+	"transitions.yaml": TransitionsConfig{},
+	"risk.yaml":        RiskConfig{},
+	"routing.yaml":     RoutingConfig{},
+	"budget.yaml":      BudgetConfig{},
+	"queue.yaml":       QueueConfig{},
+	"hooks.yaml":       HooksConfig{},
+}
+
+// SchemaFileName returns the schemas/ file name for a strategy file, e.g.
+// strategy-storage.schema.json for storage.yaml and strategy.schema.json for
+// strategy.yaml itself.
+func SchemaFileName(file string) string {
+	if file == ManifestFile {
+		return "strategy.schema.json"
+	}
+	return "strategy-" + strings.TrimSuffix(file, ".yaml") + ".schema.json"
+}
+
+// SchemaHeader is the yaml-language-server comment that binds a strategy file
+// to its published schema.
+func SchemaHeader(file string) string {
+	return "# yaml-language-server: $schema=" + SchemaBaseURL + SchemaFileName(file)
+}
+
+var stringOrList = map[string]any{
+	"oneOf": []any{
+		map[string]any{"type": "string"},
+		map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+	},
+}
+
+// fieldSchemas overrides the reflected schema of fields whose Go type does
+// not say enough, keyed by "Type.Field".
+var fieldSchemas = map[string]map[string]any{
+	"StorageConfig.Backend": {"type": "string", "enum": StorageBackends},
+	"TransitionDef.From":    stringOrList,
+}
+
+// GenerateSchema returns a JSON Schema (draft-07) document for the YAML file
+// decoded into v. Objects reject unknown keys, as strict loading does.
+func GenerateSchema(file string, v any) ([]byte, error) {
+	s, err := reflectSchema(reflect.TypeOf(v))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["$id"] = SchemaBaseURL + SchemaFileName(file)
+	s["title"] = "agentops " + file
+	out, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// reflectSchema describes t by its yaml struct tags.
+func reflectSchema(t reflect.Type) (map[string]any, error) {
+	if t == reflect.TypeOf(HookDef{}) {
+		// A plain string is shorthand for a shell hook.
+		obj, err := structSchema(t)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"oneOf": []any{map[string]any{"type": "string"}, obj}}, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Interface:
+		return map[string]any{}, nil
+	case reflect.Pointer:
+		return reflectSchema(t.Elem())
+	case reflect.Slice, reflect.Array:
+		items, err := reflectSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := reflectSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+func structSchema(t reflect.Type) (map[string]any, error) {
+	props := make(map[string]any)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		if override, ok := fieldSchemas[t.Name()+"."+f.Name]; ok {
+			props[name] = override
+			continue
+		}
+		s, err := reflectSchema(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		props[name] = s
+	}
+	return map[string]any{"type": "object", "properties": props, "additionalProperties": false}, nil
+}
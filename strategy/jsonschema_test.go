@@ -0,0 +1,79 @@
+package strategy_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// TestSchemasUpToDate guards the checked-in schemas/; regenerate them with
+// `agentops strategy schema --out schemas`.
+func TestSchemasUpToDate(t *testing.T) {
+	for file, v := range strategy.SchemaTypes {
+		want, err := strategy.GenerateSchema(file, v)
+		if err != nil {
+			t.Fatalf("GenerateSchema(%s): %v", file, err)
+		}
+		got, err := os.ReadFile(filepath.Join("..", "schemas", strategy.SchemaFileName(file)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("schemas/%s is stale", strategy.SchemaFileName(file))
+		}
+	}
+}
+
+func TestGenerateSchema(t *testing.T) {
+	data, err := strategy.GenerateSchema("storage.yaml", strategy.StorageConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s struct {
+		ID         string `json:"$id"`
+		Additional bool   `json:"additionalProperties"`
+		Properties map[string]struct {
+			Type string   `json:"type"`
+			Enum []string `json:"enum"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.ID != strategy.SchemaBaseURL+"strategy-storage.schema.json" || s.Additional {
+		t.Errorf("$id = %q, additionalProperties = %v", s.ID, s.Additional)
+	}
+	backend := s.Properties["backend"]
+	if backend.Type != "string" || len(backend.Enum) != len(strategy.StorageBackends) {
+		t.Errorf("backend = %+v", backend)
+	}
+	if _, ok := s.Properties["case_repo_path"]; !ok {
+		t.Errorf("properties = %v", s.Properties)
+	}
+}
+
+func TestBootstrapWritesSchemaHeaders(t *testing.T) {
+	tmp := t.TempDir()
+	if err := strategy.Bootstrap(tmp); err != nil {
+		t.Fatal(err)
+	}
+	files := []string{"slot.yaml"}
+	for file := range strategy.SchemaTypes {
+		files = append(files, file)
+	}
+	for _, file := range files {
+		f, err := os.Open(filepath.Join(tmp, ".agentops", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		line, _ := bufio.NewReader(f).ReadString('\n')
+		f.Close()
+		if want := strategy.SchemaHeader(file) + "\n"; line != want {
+			t.Errorf("%s: first line = %q, want %q", file, line, want)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package strategy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the strategy.yaml file that declares a strategy directory's
+// bases.
+const ManifestFile = "strategy.yaml"
+
+// SlotsDir holds per-slot overrides: .agentops/slots/<slot>/ may contain any
+// of the strategy's YAML files and schema.md.
+const SlotsDir = "slots"
+
+// Manifest is the strategy.yaml schema.
+type Manifest struct {
+	// Extends lists strategy directories merged beneath this one, lowest
+	// first. Paths are relative to the directory holding strategy.yaml; a
+	// project directory stands for its .agentops/.
+	Extends []string `yaml:"extends"`
+	// SchemaVersion is the strategy layout the directory was written for;
+	// see CurrentSchemaVersion and Upgrade.
+	SchemaVersion int `yaml:"schema_version"`
+}
+
+// configFiles are the YAML files merged across layers.
+var configFiles = []string{"storage.yaml", "transitions.yaml", "risk.yaml", "routing.yaml", "budget.yaml", "queue.yaml", "hooks.yaml"}
+
+// layer is one strategy directory in the merge order.
+type layer struct {
+	dir   string
+	label string // dir relative to the project root, used for provenance
+}
+
+// resolveLayers returns the strategy directories merged for the project at
+// root, lowest first: the bases named by extends (recursively), the project's
+// .agentops/, then the slot's overrides when slot is set.
+func resolveLayers(root, slot string) ([]layer, error) {
+	agentopsDir := filepath.Join(root, ".agentops")
+	var dirs []string
+	seen := make(map[string]bool)
+	if err := collectLayers(agentopsDir, nil, seen, &dirs); err != nil {
+		return nil, err
+	}
+	if slot != "" {
+		slotDir := filepath.Join(agentopsDir, SlotsDir, slot)
+		if info, err := os.Stat(slotDir); err == nil && info.IsDir() {
+			dirs = append(dirs, slotDir)
+		}
+	}
+
+	layers := make([]layer, len(dirs))
+	for i, dir := range dirs {
+		label := dir
+		if rel, err := filepath.Rel(root, dir); err == nil {
+			label = rel
+		}
+		layers[i] = layer{dir: dir, label: filepath.ToSlash(label)}
+	}
+	return layers, nil
+}
+
+// collectLayers appends dir's bases and then dir to out. A directory reached
+// twice is merged once, at its first position; a cycle is an error.
+func collectLayers(dir string, stack []string, seen map[string]bool, out *[]string) error {
+	for _, s := range stack {
+		if s == dir {
+			return fmt.Errorf("%s: extends cycle: %s", ManifestFile, strings.Join(append(stack, dir), " -> "))
+		}
+	}
+	if seen[dir] {
+		return nil
+	}
+
+	var m Manifest
+	if err := loadYAML(filepath.Join(dir, ManifestFile), &m); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", filepath.Join(dir, ManifestFile), err)
+	}
+	for _, ext := range m.Extends {
+		base, err := baseDir(dir, ext)
+		if err != nil {
+			return err
+		}
+		if err := collectLayers(base, append(stack, dir), seen, out); err != nil {
+			return err
+		}
+	}
+	seen[dir] = true
+	*out = append(*out, dir)
+	return nil
+}
+
+// baseDir resolves an extends entry relative to the directory declaring it.
+func baseDir(dir, ext string) (string, error) {
+	path := ext
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if info, err := os.Stat(filepath.Join(path, ".agentops")); err == nil && info.IsDir() {
+		path = filepath.Join(path, ".agentops")
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s: extends %q: not a strategy directory", filepath.Join(dir, ManifestFile), ext)
+	}
+	return filepath.Clean(path), nil
+}
+
+// mergeFile merges one YAML file across layers. Each scalar of the result
+// carries the label of the layer it came from as its line comment. It returns
+// nil when no layer has the file.
+func mergeFile(layers []layer, name string) (*yaml.Node, error) {
+	var merged *yaml.Node
+	for _, l := range layers {
+		data, err := os.ReadFile(filepath.Join(l.dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", l.label, name, err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		merged = mergeNode(merged, doc.Content[0], l.label)
+	}
+	return merged, nil
+}
+
+// mergeNode merges src over dst. Mappings merge key by key. Lists of
+// mappings that all carry a name key merge by name, keeping dst's order and
+// appending new entries; an empty list, like an empty mapping, keeps dst.
+// Anything else is replaced by src.
+func mergeNode(dst, src *yaml.Node, label string) *yaml.Node {
+	if dst == nil || dst.Kind != src.Kind {
+		return stamp(src, label)
+	}
+	switch src.Kind {
+	case yaml.MappingNode:
+		out := shallowCopy(dst)
+		out.Content = append([]*yaml.Node(nil), dst.Content...)
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, val := src.Content[i], src.Content[i+1]
+			if j := mappingIndex(out, key.Value); j >= 0 {
+				out.Content[j+1] = mergeNode(out.Content[j+1], val, label)
+			} else {
+				out.Content = append(out.Content, stamp(key, ""), stamp(val, label))
+			}
+		}
+		return out
+	case yaml.SequenceNode:
+		if len(src.Content) == 0 {
+			return dst
+		}
+		if !namedList(dst) || !namedList(src) {
+			return stamp(src, label)
+		}
+		out := shallowCopy(dst)
+		out.Content = append([]*yaml.Node(nil), dst.Content...)
+		for _, item := range src.Content {
+			name := mappingValue(item, "name")
+			replaced := false
+			for j, existing := range out.Content {
+				if mappingValue(existing, "name") == name {
+					out.Content[j] = mergeNode(existing, item, label)
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				out.Content = append(out.Content, stamp(item, label))
+			}
+		}
+		return out
+	}
+	return stamp(src, label)
+}
+
+// stamp deep-copies n without its comments, labelling every scalar.
+func stamp(n *yaml.Node, label string) *yaml.Node {
+	out := shallowCopy(n)
+	out.HeadComment, out.LineComment, out.FootComment = "", "", ""
+	out.Style &^= yaml.FlowStyle // block style keeps one annotated value per line
+	if n.Kind == yaml.ScalarNode {
+		out.LineComment = label
+		return out
+	}
+	out.Content = make([]*yaml.Node, len(n.Content))
+	for i, c := range n.Content {
+		if n.Kind == yaml.MappingNode && i%2 == 0 {
+			out.Content[i] = stamp(c, "") // keys carry no provenance
+			continue
+		}
+		out.Content[i] = stamp(c, label)
+	}
+	return out
+}
+
+func shallowCopy(n *yaml.Node) *yaml.Node {
+	c := *n
+	return &c
+}
+
+// mappingIndex returns the index of key in a mapping's content, or -1.
+func mappingIndex(n *yaml.Node, key string) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mappingValue returns the scalar value of key in a mapping, or "".
+func mappingValue(n *yaml.Node, key string) string {
+	if n.Kind != yaml.MappingNode {
+		return ""
+	}
+	if i := mappingIndex(n, key); i >= 0 && n.Content[i+1].Kind == yaml.ScalarNode {
+		return n.Content[i+1].Value
+	}
+	return ""
+}
+
+// namedList reports whether every item of a sequence is a mapping with a
+// name.
+func namedList(n *yaml.Node) bool {
+	for _, item := range n.Content {
+		if mappingValue(item, "name") == "" {
+			return false
+		}
+	}
+	return len(n.Content) > 0
+}
+
+// Resolved is the effective strategy after merging every layer.
+type Resolved struct {
+	Layers  []string          `json:"layers"`            // lowest first, relative to the project root
+	Slot    string            `json:"slot,omitempty"`    // slot whose overrides were applied
+	Files   map[string]any    `json:"files"`             // merged content per file
+	Sources map[string]string `json:"sources,omitempty"` // "file:path" -> layer that set it
+}
+
+// Resolve reports the merged strategy and where each value came from.
+func (s *Strategy) Resolve() (*Resolved, error) {
+	r := &Resolved{
+		Layers:  s.Layers,
+		Slot:    s.Slot,
+		Files:   make(map[string]any),
+		Sources: make(map[string]string),
+	}
+	for _, name := range sortedKeys(s.resolved) {
+		node := s.resolved[name]
+		var v any
+		if err := node.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		r.Files[name] = v
+		collectSources(node, name+":", r.Sources)
+	}
+	if s.schemaSource != "" {
+		r.Sources["schema.md"] = s.schemaSource
+	}
+	for _, typ := range s.TemplateTypes() {
+		r.Sources[TemplatesDir+"/"+typ+".md"] = s.Templates[typ].Layer
+	}
+	return r, nil
+}
+
+// collectSources records the layer label of every scalar under n. Paths are
+// dotted, with list items indexed by name when they have one.
+func collectSources(n *yaml.Node, path string, out map[string]string) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		out[path] = n.LineComment
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			if !strings.HasSuffix(path, ":") {
+				key = "." + key
+			}
+			collectSources(n.Content[i+1], path+key, out)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			key := strconv.Itoa(i)
+			if name := mappingValue(item, "name"); name != "" {
+				key = name
+			}
+			collectSources(item, path+"["+key+"]", out)
+		}
+	}
+}
+
+// RenderResolved renders the merged strategy as YAML, one document per file.
+// Each value is annotated with the layer it came from.
+func (s *Strategy) RenderResolved() (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# layers (lowest first): %s\n", strings.Join(s.Layers, ", "))
+	if s.Slot != "" {
+		fmt.Fprintf(&b, "# slot: %s\n", s.Slot)
+	}
+	if s.schemaSource != "" {
+		fmt.Fprintf(&b, "# schema.md: %s\n", s.schemaSource)
+	}
+	for _, typ := range s.TemplateTypes() {
+		fmt.Fprintf(&b, "# %s/%s.md: %s\n", TemplatesDir, typ, s.Templates[typ].Layer)
+	}
+	for _, name := range sortedKeys(s.resolved) {
+		fmt.Fprintf(&b, "---\n# %s\n", name)
+		enc := yaml.NewEncoder(&b)
+		enc.SetIndent(2)
+		if err := enc.Encode(s.resolved[name]); err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		if err := enc.Close(); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	agentops "github.com/gh-xj/agentops"
+	"github.com/gh-xj/agentops/dal"
+	slotresource "github.com/gh-xj/agentops/resource/slot"
 	"gopkg.in/yaml.v3"
 )
 
@@ -13,40 +17,24 @@ import (
 var defaultsFS embed.FS
 
 // Discover walks up from startDir looking for .agentops/ and loads the strategy.
+// It returns a strategy_missing CLIError when there is no .agentops/, and a
+// *LoadError listing every problem when the strategy does not load.
 func Discover(startDir string) (*Strategy, error) {
 	root, err := findRoot(startDir)
 	if err != nil {
-		return nil, err
+		return nil, agentops.NewCLIError(agentops.ExitStrategyMissing, "strategy_missing", err.Error(), nil)
+	}
+	s, err := load(root)
+	if err != nil {
+		return nil, asLoadError(err)
 	}
-	return load(root)
+	return s, nil
 }
 
 // Bootstrap creates .agentops/ with default files. Idempotent: does not overwrite existing files.
 func Bootstrap(projectDir string) error {
-	agentopsDir := filepath.Join(projectDir, ".agentops")
-	if err := os.MkdirAll(agentopsDir, 0o755); err != nil {
-		return fmt.Errorf("create .agentops/: %w", err)
-	}
-
-	entries, err := defaultsFS.ReadDir("defaults")
-	if err != nil {
-		return fmt.Errorf("read embedded defaults: %w", err)
-	}
-
-	for _, entry := range entries {
-		target := filepath.Join(agentopsDir, entry.Name())
-		if _, err := os.Stat(target); err == nil {
-			continue // don't overwrite existing
-		}
-		data, err := defaultsFS.ReadFile("defaults/" + entry.Name())
-		if err != nil {
-			return fmt.Errorf("read default %s: %w", entry.Name(), err)
-		}
-		if err := os.WriteFile(target, data, 0o644); err != nil {
-			return fmt.Errorf("write %s: %w", entry.Name(), err)
-		}
-	}
-	return nil
+	_, err := BootstrapWith(projectDir, BootstrapOptions{})
+	return err
 }
 
 func findRoot(startDir string) (string, error) {
@@ -67,42 +55,68 @@ func findRoot(startDir string) (string, error) {
 }
 
 func load(root string) (*Strategy, error) {
-	agentopsDir := filepath.Join(root, ".agentops")
-	s := &Strategy{Root: root}
-
-	// Load storage.yaml
-	if err := loadYAML(filepath.Join(agentopsDir, "storage.yaml"), &s.Storage); err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("storage.yaml: %w", err)
+	s := &Strategy{Root: root, Slot: slotresource.CurrentSlot(nil, dal.NewFileSystem(), root)}
+	layers, err := resolveLayers(root, s.Slot)
+	if err != nil {
+		return nil, err
 	}
-
-	// Load transitions.yaml
-	if err := loadYAML(filepath.Join(agentopsDir, "transitions.yaml"), &s.Transitions); err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("transitions.yaml: %w", err)
+	for _, l := range layers {
+		s.Layers = append(s.Layers, l.label)
 	}
-
-	// Load risk.yaml (unstructured)
-	if err := loadYAML(filepath.Join(agentopsDir, "risk.yaml"), &s.Risk); err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("risk.yaml: %w", err)
+	if findings := checkLayers(layers); len(findings) > 0 {
+		return nil, &LoadError{Findings: findings}
 	}
-
-	// Load routing.yaml (unstructured)
-	if err := loadYAML(filepath.Join(agentopsDir, "routing.yaml"), &s.Routing); err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("routing.yaml: %w", err)
+	var m Manifest
+	if err := loadYAML(filepath.Join(root, ".agentops", ManifestFile), &m); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s: %w", ManifestFile, err)
 	}
-
-	// Load budget.yaml (unstructured)
-	if err := loadYAML(filepath.Join(agentopsDir, "budget.yaml"), &s.Budget); err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("budget.yaml: %w", err)
+	s.SchemaVersion = m.Version()
+
+	// Merge each YAML file across layers, then decode it.
+	targets := map[string]any{
+		"storage.yaml":     &s.Storage,
+		"transitions.yaml": &s.Transitions,
+		"risk.yaml":        &s.Risk,
+		"routing.yaml":     &s.Routing,
+		"budget.yaml":      &s.Budget,
+		"queue.yaml":       &s.Queue,
+		"hooks.yaml":       &s.Hooks,
 	}
-
-	// Load hooks.yaml
-	if err := loadYAML(filepath.Join(agentopsDir, "hooks.yaml"), &s.Hooks); err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("hooks.yaml: %w", err)
+	s.resolved = make(map[string]*yaml.Node)
+	for _, name := range configFiles {
+		node, err := mergeFile(layers, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if node == nil {
+			continue
+		}
+		s.resolved[name] = node
+		if err := node.Decode(targets[name]); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
 	}
 
-	// Load schema.md (raw)
-	if data, err := os.ReadFile(filepath.Join(agentopsDir, "schema.md")); err == nil {
+	// Load schema.md (raw) and its field declarations from the highest layer
+	// that has one.
+	for i := len(layers) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(filepath.Join(layers[i].dir, "schema.md"))
+		if err != nil {
+			continue
+		}
 		s.SchemaTemplate = string(data)
+		s.schemaSource = layers[i].label
+		fields, err := parseSchemaFields(s.SchemaTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("schema.md: %w", err)
+		}
+		s.Fields = fields
+		break
+	}
+
+	s.Templates, err = loadTemplates(layers)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", TemplatesDir, err)
 	}
 
 	return s, nil
@@ -115,3 +129,44 @@ func loadYAML(path string, target any) error {
 	}
 	return yaml.Unmarshal(data, target)
 }
+
+// parseSchemaFields reads the fields declaration from schema.md frontmatter.
+// A template without frontmatter or without a fields key declares nothing.
+func parseSchemaFields(content string) ([]FieldSpec, error) {
+	if !strings.HasPrefix(content, "---\n") {
+		return nil, nil
+	}
+	rest := content[4:]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated YAML frontmatter")
+	}
+	var decl struct {
+		Fields []FieldSpec `yaml:"fields"`
+	}
+	if err := yaml.Unmarshal([]byte(rest[:end]), &decl); err != nil {
+		return nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range decl.Fields {
+		f := &decl.Fields[i]
+		if f.Name == "" {
+			return nil, fmt.Errorf("fields[%d]: name is required", i)
+		}
+		if seen[f.Name] {
+			return nil, fmt.Errorf("field %q declared twice", f.Name)
+		}
+		seen[f.Name] = true
+		if f.Type == "" {
+			f.Type = FieldString
+		}
+		if !fieldTypes[f.Type] {
+			return nil, fmt.Errorf("field %q: unknown type %q", f.Name, f.Type)
+		}
+		if f.Type == FieldEnum && len(f.Values) == 0 {
+			return nil, fmt.Errorf("field %q: enum requires values", f.Name)
+		}
+	}
+	return decl.Fields, nil
+}
@@ -1,10 +1,13 @@
 package strategy_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	agentops "github.com/gh-xj/agentops"
 	"github.com/gh-xj/agentops/strategy"
 )
 
@@ -47,6 +50,9 @@ func TestDiscoverMissing(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error when .agentops/ not found")
 	}
+	if code := agentops.ResolveExitCode(err); code != agentops.ExitStrategyMissing {
+		t.Errorf("exit code = %d, want %d", code, agentops.ExitStrategyMissing)
+	}
 }
 
 func TestBootstrapCreatesDefaults(t *testing.T) {
@@ -93,3 +99,278 @@ func TestBootstrapIdempotent(t *testing.T) {
 		t.Errorf("Bootstrap overwrote existing file: got %q, want %q", string(data), string(custom))
 	}
 }
+
+func TestLoadHooksShorthandAndMapping(t *testing.T) {
+	tmp := t.TempDir()
+	if err := strategy.Bootstrap(tmp); err != nil {
+		t.Fatal(err)
+	}
+	hooksYAML := "on_case_open:\n  - echo opened\n  - agentops: slot doctor\n    blocking: true\n  - skill: notify\n"
+	if err := os.WriteFile(filepath.Join(tmp, ".agentops", "hooks.yaml"), []byte(hooksYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	got := strat.Hooks.For("on-case-open")
+	if len(got) != 3 {
+		t.Fatalf("expected 3 hooks, got %d", len(got))
+	}
+	wantKinds := []string{strategy.HookShell, strategy.HookAgentops, strategy.HookSkill}
+	for i, want := range wantKinds {
+		kind, _, err := got[i].Kind()
+		if err != nil || kind != want {
+			t.Errorf("hook %d kind = %q (%v), want %q", i, kind, err, want)
+		}
+	}
+	if !got[1].Blocking {
+		t.Error("expected agentops hook to be blocking")
+	}
+}
+
+func TestLoadSchemaFields(t *testing.T) {
+	tmp := t.TempDir()
+	if err := strategy.Bootstrap(tmp); err != nil {
+		t.Fatal(err)
+	}
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(strat.Fields) != 5 || strat.Fields[4].Name != "created" || strat.Fields[4].Type != strategy.FieldDate {
+		t.Errorf("default fields = %+v", strat.Fields)
+	}
+
+	schemaPath := filepath.Join(tmp, ".agentops", "schema.md")
+	bad := "---\nfields:\n  - {name: risk, type: enum}\n---\n# Case Title\n"
+	if err := os.WriteFile(schemaPath, []byte(bad), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strategy.Discover(tmp); err == nil || !strings.Contains(err.Error(), "enum requires values") {
+		t.Errorf("expected enum values error, got %v", err)
+	}
+}
+
+func TestLoadCaseTemplates(t *testing.T) {
+	tmp := t.TempDir()
+	if err := strategy.Bootstrap(tmp); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	templates := filepath.Join(tmp, ".agentops", strategy.TemplatesDir)
+	write(filepath.Join(templates, "pr.md"), "---\ninputs:\n  - {name: pr_url, required: true}\n---\n# {{.Title}}\n")
+	write(filepath.Join(templates, "bug.md"), "---\ninputs:\n  - {name: pr_url, description: Related PR}\n  - {name: repro}\n---\n# {{.Title}}\n")
+	write(filepath.Join(templates, "README.txt"), "not a template\n")
+	write(filepath.Join(tmp, ".agentops", "slots", "alpha", strategy.TemplatesDir, "pr.md"), "# {{.ID}} in {{.Slot}}\n")
+	write(filepath.Join(tmp, ".slot"), "alpha\n")
+
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if got := strings.Join(strat.TemplateTypes(), ","); got != "bug,pr" {
+		t.Fatalf("template types = %q", got)
+	}
+	pr := strat.Templates["pr"]
+	if pr.Layer != ".agentops/slots/alpha" || len(pr.Inputs) != 0 {
+		t.Errorf("pr template = %+v, want the slot override", pr)
+	}
+	out, err := pr.Execute(strategy.TemplateData{ID: "CASE-1", Slot: "alpha"})
+	if err != nil || out != "# CASE-1 in alpha\n" {
+		t.Errorf("Execute = %q, %v", out, err)
+	}
+	inputs := strat.TemplateInputs()
+	if len(inputs) != 2 || inputs[0].Name != "pr_url" || inputs[0].Description != "Related PR" || inputs[1].Name != "repro" {
+		t.Errorf("inputs = %+v", inputs)
+	}
+
+	write(filepath.Join(templates, "bug.md"), "---\ninputs:\n  - {name: slug}\n---\n")
+	if _, err := strategy.Discover(tmp); err == nil || !strings.Contains(err.Error(), `input "slug" is reserved`) {
+		t.Errorf("expected reserved input error, got %v", err)
+	}
+	write(filepath.Join(templates, "bug.md"), "# {{.Title\n")
+	if _, err := strategy.Discover(tmp); err == nil || !strings.Contains(err.Error(), "bug.md") {
+		t.Errorf("expected template parse error, got %v", err)
+	}
+}
+
+func TestDiscoverExtendsAndSlotOverrides(t *testing.T) {
+	tmp := t.TempDir()
+	org := filepath.Join(tmp, "org")
+	repo := filepath.Join(tmp, "repo")
+	for _, dir := range []string{org, repo} {
+		if err := strategy.Bootstrap(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(org, ".agentops", "risk.yaml"), "thresholds: {medium: 3, high: 6}\nrules:\n  - {name: auth, score: 5, match: {paths: [\"internal/auth/**\"]}}\n  - {name: sql, score: 3}\n")
+	write(filepath.Join(org, ".agentops", "storage.yaml"), "backend: in-repo\n")
+	write(filepath.Join(repo, ".agentops", "strategy.yaml"), "extends: [../../org]\n")
+	write(filepath.Join(repo, ".agentops", "risk.yaml"), "thresholds: {high: 8}\nrules:\n  - {name: sql, score: 1}\n")
+	write(filepath.Join(repo, ".agentops", "slots", "alpha", "budget.yaml"), "limits: {max_workers: 2}\n")
+	// The repo's bootstrapped storage.yaml has no opinion once removed.
+	if err := os.Remove(filepath.Join(repo, ".agentops", "storage.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	strat, err := strategy.Discover(repo)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if strings.Join(strat.Layers, ",") != "../org/.agentops,.agentops" || strat.Slot != "" {
+		t.Errorf("layers = %v, slot = %q", strat.Layers, strat.Slot)
+	}
+	if strat.Storage.Backend != "in-repo" {
+		t.Errorf("backend = %q, want in-repo from org", strat.Storage.Backend)
+	}
+	if strat.Risk.Thresholds["medium"] != 3 || strat.Risk.Thresholds["high"] != 8 {
+		t.Errorf("thresholds = %v", strat.Risk.Thresholds)
+	}
+	if len(strat.Risk.Rules) != 2 || strat.Risk.Rules[1].Name != "sql" || strat.Risk.Rules[1].Score != 1 {
+		t.Errorf("rules = %+v", strat.Risk.Rules)
+	}
+	if strat.Budget.Limits.MaxWorkers != 0 {
+		t.Errorf("slot override applied outside the slot")
+	}
+
+	write(filepath.Join(repo, ".slot"), "alpha\n")
+	strat, err = strategy.Discover(repo)
+	if err != nil {
+		t.Fatalf("Discover in slot: %v", err)
+	}
+	if strat.Slot != "alpha" || strat.Budget.Limits.MaxWorkers != 2 {
+		t.Errorf("slot = %q, max_workers = %d", strat.Slot, strat.Budget.Limits.MaxWorkers)
+	}
+
+	resolved, err := strat.Resolve()
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	wantSources := map[string]string{
+		"risk.yaml:thresholds.medium":    "../org/.agentops",
+		"risk.yaml:thresholds.high":      ".agentops",
+		"risk.yaml:rules[sql].score":     ".agentops",
+		"risk.yaml:rules[auth].score":    "../org/.agentops",
+		"budget.yaml:limits.max_workers": ".agentops/slots/alpha",
+		"schema.md":                      ".agentops",
+	}
+	for key, want := range wantSources {
+		if got := resolved.Sources[key]; got != want {
+			t.Errorf("source of %s = %q, want %q", key, got, want)
+		}
+	}
+	out, err := strat.RenderResolved()
+	if err != nil {
+		t.Fatalf("RenderResolved: %v", err)
+	}
+	if !strings.Contains(out, "high: 8 # .agentops\n") {
+		t.Errorf("rendered strategy lacks provenance:\n%s", out)
+	}
+}
+
+func TestDiscoverExtendsErrors(t *testing.T) {
+	tmp := t.TempDir()
+	a := filepath.Join(tmp, "a", ".agentops")
+	b := filepath.Join(tmp, "b", ".agentops")
+	for _, dir := range []string{a, b} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(a, "strategy.yaml"), []byte("extends: [../../b]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(b, "strategy.yaml"), []byte("extends: [../../a]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strategy.Discover(filepath.Join(tmp, "a")); err == nil || !strings.Contains(err.Error(), "extends cycle") {
+		t.Errorf("expected extends cycle error, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(b, "strategy.yaml"), []byte("extends: [../missing]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strategy.Discover(filepath.Join(tmp, "b")); err == nil || !strings.Contains(err.Error(), "not a strategy directory") {
+		t.Errorf("expected missing base error, got %v", err)
+	}
+}
+
+func TestDiscoverStrictFindings(t *testing.T) {
+	tmp := t.TempDir()
+	if err := strategy.Bootstrap(tmp); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(tmp, ".agentops")
+	files := map[string]string{
+		"storage.yaml":     "backend: separte-repo\n",
+		"transitions.yaml": "initial: open\ntransitons: {}\n",
+		"budget.yaml":      "limits:\n  max_workers: lots\n",
+		"hooks.yaml":       "on_case_open:\n  - shel: echo hi\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := strategy.Discover(tmp)
+	var loadErr *strategy.LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected *LoadError, got %v", err)
+	}
+	if code := agentops.ResolveExitCode(err); code != agentops.ExitValidationFailed {
+		t.Errorf("exit code = %d, want %d", code, agentops.ExitValidationFailed)
+	}
+	want := []agentops.DoctorFinding{
+		{Code: strategy.FindingInvalidValue, Path: ".agentops/storage.yaml:1"},
+		{Code: strategy.FindingUnknownKey, Path: ".agentops/transitions.yaml:2", Message: `unknown key "transitons"`},
+		{Code: strategy.FindingInvalidType, Path: ".agentops/budget.yaml:2"},
+		{Code: strategy.FindingUnknownKey, Path: ".agentops/hooks.yaml:2", Message: `unknown key "shel"`},
+	}
+	if len(loadErr.Findings) != len(want) {
+		t.Fatalf("findings = %+v", loadErr.Findings)
+	}
+	for i, w := range want {
+		got := loadErr.Findings[i]
+		if got.Code != w.Code || got.Path != w.Path || (w.Message != "" && got.Message != w.Message) {
+			t.Errorf("finding %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestDiscoverParseError(t *testing.T) {
+	tmp := t.TempDir()
+	if err := strategy.Bootstrap(tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, ".agentops", "risk.yaml"), []byte("rules:\n  - name: a\n   score: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := strategy.Discover(tmp)
+	var loadErr *strategy.LoadError
+	if !errors.As(err, &loadErr) || len(loadErr.Findings) != 1 || loadErr.Findings[0].Code != strategy.FindingParseError {
+		t.Fatalf("expected one parse_error finding, got %v", err)
+	}
+	if !strings.HasPrefix(loadErr.Findings[0].Path, ".agentops/risk.yaml:") {
+		t.Errorf("path = %q, want a risk.yaml line", loadErr.Findings[0].Path)
+	}
+}
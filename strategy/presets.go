@@ -0,0 +1,147 @@
+package strategy
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Preset bundles are layered over the defaults by BootstrapWith: a file in
+// presets/<name>/ replaces the default of the same name.
+//
+//go:embed presets
+var presetsFS embed.FS
+
+// DefaultPreset writes the defaults unchanged.
+const DefaultPreset = "default"
+
+// Preset is a named starting strategy.
+type Preset struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Presets lists the bundles BootstrapWith accepts.
+var Presets = []Preset{
+	{Name: DefaultPreset, Description: "general-purpose lifecycle; cases in a sibling repository"},
+	{Name: "solo-in-repo", Description: "one developer; cases committed in cases/ with the code"},
+	{Name: "team-separate-repo", Description: "shared case repository synced on close; slots alpha, beta, gamma"},
+	{Name: "pr-review", Description: "one case per pull request, reviewed before merge; risk rules for auth and migrations"},
+}
+
+// BootstrapOptions are the answers given to `agentops init`. Empty fields
+// keep the preset's value.
+type BootstrapOptions struct {
+	Preset     string   // one of Presets; defaults to DefaultPreset
+	Backend    string   // storage.yaml backend
+	BaseBranch string   // slot.yaml base_branch
+	Slots      []string // slot.yaml slots
+}
+
+// BootstrapResult lists the files BootstrapWith wrote and the existing ones
+// it left alone.
+type BootstrapResult struct {
+	Dir     string   `json:"dir"`
+	Preset  string   `json:"preset"`
+	Created []string `json:"created"`
+	Kept    []string `json:"kept"`
+}
+
+// BootstrapWith creates .agentops/ from a preset and opts. Like Bootstrap it
+// never overwrites a file; answers only shape the files it creates.
+func BootstrapWith(projectDir string, opts BootstrapOptions) (*BootstrapResult, error) {
+	if opts.Preset == "" {
+		opts.Preset = DefaultPreset
+	}
+	if !slices.ContainsFunc(Presets, func(p Preset) bool { return p.Name == opts.Preset }) {
+		return nil, fmt.Errorf("unknown preset %q (have %s)", opts.Preset, strings.Join(PresetNames(), ", "))
+	}
+	if opts.Backend != "" && !slices.Contains(StorageBackends, opts.Backend) {
+		return nil, fmt.Errorf("backend %q: must be one of %s", opts.Backend, strings.Join(StorageBackends, ", "))
+	}
+
+	files, err := presetFiles(opts.Preset)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Backend != "" {
+		files["storage.yaml"] = setTopLevelKey(files["storage.yaml"], "backend", opts.Backend)
+	}
+	if opts.BaseBranch != "" {
+		files["slot.yaml"] = setTopLevelKey(files["slot.yaml"], "base_branch", opts.BaseBranch)
+	}
+	if len(opts.Slots) > 0 {
+		files["slot.yaml"] = setTopLevelKey(files["slot.yaml"], "slots", "["+strings.Join(opts.Slots, ", ")+"]")
+	}
+
+	agentopsDir := filepath.Join(projectDir, ".agentops")
+	if err := os.MkdirAll(agentopsDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create .agentops/: %w", err)
+	}
+	result := &BootstrapResult{Dir: agentopsDir, Preset: opts.Preset}
+	for _, name := range sortedKeys(files) {
+		target := filepath.Join(agentopsDir, name)
+		if _, err := os.Stat(target); err == nil {
+			result.Kept = append(result.Kept, name)
+			continue // don't overwrite existing
+		}
+		if err := os.WriteFile(target, []byte(files[name]), 0o644); err != nil {
+			return nil, fmt.Errorf("write %s: %w", name, err)
+		}
+		result.Created = append(result.Created, name)
+	}
+	return result, nil
+}
+
+// PresetNames returns the names of Presets.
+func PresetNames() []string {
+	names := make([]string, len(Presets))
+	for i, p := range Presets {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// presetFiles returns the defaults with a preset's files layered over them.
+func presetFiles(name string) (map[string]string, error) {
+	files, err := defaultsFor(CurrentSchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+	if name == DefaultPreset {
+		return files, nil
+	}
+	root := "presets/" + name
+	entries, err := fs.ReadDir(presetsFS, root)
+	if err != nil {
+		return nil, fmt.Errorf("read preset %s: %w", name, err)
+	}
+	for _, e := range entries {
+		data, err := fs.ReadFile(presetsFS, root+"/"+e.Name())
+		if err != nil {
+			return nil, err
+		}
+		files[e.Name()] = string(data)
+	}
+	return files, nil
+}
+
+// setTopLevelKey sets a top-level scalar or flow value in YAML content,
+// keeping comments and the rest of the file as written. A missing key is
+// appended.
+func setTopLevelKey(content, key, value string) string {
+	line := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `:.*$`)
+	set := key + ": " + value
+	if line.MatchString(content) {
+		return line.ReplaceAllLiteralString(content, set)
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + set + "\n"
+}
@@ -0,0 +1,103 @@
+package strategy_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gh-xj/agentops/strategy"
+)
+
+func TestPresetsBootstrapValidStrategies(t *testing.T) {
+	for _, p := range strategy.Presets {
+		t.Run(p.Name, func(t *testing.T) {
+			tmp := t.TempDir()
+			result, err := strategy.BootstrapWith(tmp, strategy.BootstrapOptions{Preset: p.Name})
+			if err != nil {
+				t.Fatalf("BootstrapWith: %v", err)
+			}
+			if len(result.Created) == 0 || len(result.Kept) != 0 {
+				t.Errorf("created %v, kept %v", result.Created, result.Kept)
+			}
+			strat, err := strategy.Discover(tmp)
+			if err != nil {
+				t.Fatalf("Discover: %v", err)
+			}
+			if findings := strat.Validate(); len(findings) > 0 {
+				t.Errorf("findings = %+v", findings)
+			}
+			for _, name := range result.Created {
+				if !strings.HasSuffix(name, ".yaml") {
+					continue
+				}
+				data, err := os.ReadFile(filepath.Join(tmp, ".agentops", name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.HasPrefix(string(data), strategy.SchemaHeader(name)+"\n") {
+					t.Errorf("%s lacks the schema header", name)
+				}
+			}
+		})
+	}
+}
+
+func TestBootstrapWithAnswers(t *testing.T) {
+	tmp := t.TempDir()
+	dir := filepath.Join(tmp, ".agentops")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	custom := "backend: separate-repo\n"
+	if err := os.WriteFile(filepath.Join(dir, "storage.yaml"), []byte(custom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := strategy.BootstrapWith(tmp, strategy.BootstrapOptions{
+		Preset:     "team-separate-repo",
+		Backend:    "in-repo",
+		BaseBranch: "trunk",
+		Slots:      []string{"one", "two"},
+	})
+	if err != nil {
+		t.Fatalf("BootstrapWith: %v", err)
+	}
+	if len(result.Kept) != 1 || result.Kept[0] != "storage.yaml" {
+		t.Errorf("kept = %v, want [storage.yaml]", result.Kept)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "storage.yaml"))
+	if err != nil || string(data) != custom {
+		t.Errorf("existing storage.yaml changed: %q", data)
+	}
+
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(strat.Hooks.PostClose) != 1 {
+		t.Errorf("post_close hooks = %+v, want the preset's", strat.Hooks.PostClose)
+	}
+	slot, err := os.ReadFile(filepath.Join(dir, "slot.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"base_branch: trunk\n", "slots: [one, two]\n", "# One worktree slot"} {
+		if !strings.Contains(string(slot), want) {
+			t.Errorf("slot.yaml missing %q:\n%s", want, slot)
+		}
+	}
+}
+
+func TestBootstrapWithRejectsUnknownAnswers(t *testing.T) {
+	tmp := t.TempDir()
+	if _, err := strategy.BootstrapWith(tmp, strategy.BootstrapOptions{Preset: "nope"}); err == nil || !strings.Contains(err.Error(), "unknown preset") {
+		t.Errorf("expected unknown preset error, got %v", err)
+	}
+	if _, err := strategy.BootstrapWith(tmp, strategy.BootstrapOptions{Backend: "s3"}); err == nil || !strings.Contains(err.Error(), "backend") {
+		t.Errorf("expected backend error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, ".agentops")); !os.IsNotExist(err) {
+		t.Errorf("rejected answers still created .agentops/")
+	}
+}
@@ -1,21 +1,72 @@
 package strategy
 
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
 // Strategy holds the fully loaded .agentops/ configuration.
 type Strategy struct {
-	Root           string // absolute path to project root (parent of .agentops/)
+	Root           string   // absolute path to project root (parent of .agentops/)
+	Layers         []string // merged strategy directories, lowest first, relative to Root
+	Slot           string   // slot whose .agentops/slots/<slot>/ overrides were merged
+	SchemaVersion  int      // schema_version of the project's strategy.yaml
 	Storage        StorageConfig
 	Transitions    TransitionsConfig
-	Risk           map[string]any
-	Routing        map[string]any
-	Budget         map[string]any
+	Risk           RiskConfig
+	Routing        RoutingConfig
+	Budget         BudgetConfig
+	Queue          QueueConfig
 	Hooks          HooksConfig
-	SchemaTemplate string // raw content of schema.md
+	SchemaTemplate string                   // raw content of schema.md
+	Fields         []FieldSpec              // case frontmatter fields declared in schema.md
+	Templates      map[string]*CaseTemplate // templates/<type>.md by case type
+
+	resolved     map[string]*yaml.Node // merged YAML per file, scalars labelled with their layer
+	schemaSource string                // layer schema.md was read from
 }
 
 // StorageConfig controls where case records are stored.
 type StorageConfig struct {
-	Backend      string `yaml:"backend"`        // "separate-repo" or "in-repo"
-	CaseRepoPath string `yaml:"case_repo_path"` // relative path to case repo
+	Backend      string            `yaml:"backend"`        // "separate-repo" or "in-repo"
+	CaseRepoPath string            `yaml:"case_repo_path"` // relative path to case repo
+	Remote       string            `yaml:"remote"`         // git remote the case repo syncs with
+	Retention    map[string]string `yaml:"retention"`      // category -> idle age before prune archives a case, e.g. "30d"
+}
+
+// ArchiveCategory is the storage group archived cases are moved to. It is
+// reserved: no status category may use the name.
+const ArchiveCategory = "archive"
+
+// RetentionFor parses the retention of a status category. ok is false when
+// the category has none.
+func (c StorageConfig) RetentionFor(category string) (d time.Duration, ok bool, err error) {
+	v, ok := c.Retention[category]
+	if !ok {
+		return 0, false, nil
+	}
+	d, err = ParseAge(v)
+	if err != nil {
+		return 0, true, fmt.Errorf("retention of %s: %w", category, err)
+	}
+	return d, true, nil
+}
+
+// ParseAge parses a duration that may also count whole days, such as "30d".
+func ParseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
 }
 
 // TransitionsConfig defines the state machine for case lifecycle.
@@ -27,27 +78,395 @@ type TransitionsConfig struct {
 
 // TransitionDef describes one allowed state transition.
 type TransitionDef struct {
-	From any    `yaml:"from"` // string or []string
-	To   string `yaml:"to"`
+	From   any              `yaml:"from"` // string or []string
+	To     string           `yaml:"to"`
+	Guards TransitionGuards `yaml:"guards"`
+}
+
+// TransitionGuards are conditions a case must meet before a transition
+// applies. Every unmet condition is reported when the transition is denied.
+type TransitionGuards struct {
+	Fields   []string `yaml:"fields"`   // frontmatter fields that must be non-empty
+	Sidecars []string `yaml:"sidecars"` // files, relative to the case directory, that must exist
+	Workers  []string `yaml:"workers"`  // workers that must have completed: sidecar present and valid
+	Note     bool     `yaml:"note"`     // the transition must be given a --note/--reason
 }
 
-// FromStates returns the from states as a string slice.
+// Empty reports whether no guard is declared.
+func (g TransitionGuards) Empty() bool {
+	return len(g.Fields) == 0 && len(g.Sidecars) == 0 && len(g.Workers) == 0 && !g.Note
+}
+
+// FromStates returns the from states as a string slice. Entries that are not
+// strings are skipped; ValidateTransitions reports them.
 func (t TransitionDef) FromStates() []string {
 	switch v := t.From.(type) {
 	case string:
 		return []string{v}
 	case []any:
-		result := make([]string, len(v))
-		for i, s := range v {
-			result[i] = s.(string)
+		result := make([]string, 0, len(v))
+		for _, s := range v {
+			if str, ok := s.(string); ok {
+				result = append(result, str)
+			}
 		}
 		return result
+	case []string:
+		return v
 	}
 	return nil
 }
 
-// HooksConfig defines pre/post lifecycle hooks.
+// SchemaFieldsKey is the schema.md frontmatter key that declares case fields.
+// It describes the template and is never copied into case.md.
+const SchemaFieldsKey = "fields"
+
+// Field types accepted in schema.md field declarations.
+const (
+	FieldString = "string"
+	FieldInt    = "int"
+	FieldBool   = "bool"
+	FieldList   = "list"
+	FieldDate   = "date"
+	FieldEnum   = "enum"
+)
+
+var fieldTypes = map[string]bool{
+	FieldString: true, FieldInt: true, FieldBool: true,
+	FieldList: true, FieldDate: true, FieldEnum: true,
+}
+
+// FieldSpec declares one case frontmatter field.
+type FieldSpec struct {
+	Name        string   `yaml:"name"`
+	Type        string   `yaml:"type"` // string (default), int, bool, list, date or enum
+	Required    bool     `yaml:"required"`
+	Values      []string `yaml:"values"`  // allowed values for enum fields
+	Default     any      `yaml:"default"` // written into new cases when the template omits the field
+	Description string   `yaml:"description"`
+}
+
+// DefaultCaseType is the type of a case no routing cue classifies.
+const DefaultCaseType = "intake"
+
+// OverrideAnyRisk is the routing override key that applies at every risk
+// level without a specific entry.
+const OverrideAnyRisk = "*"
+
+// RoutingConfig is the routing.yaml schema: cues classify a case into a type,
+// and the type and risk level select the workers to run.
+type RoutingConfig struct {
+	DefaultRoute Route                          `yaml:"default_route"`
+	Cues         map[string]RoutingCue          `yaml:"cues"`      // keyed by case type
+	Overrides    map[string]map[string][]string `yaml:"overrides"` // type -> risk level or "*" -> workers
+	Reconcile    ReconcileConfig                `yaml:"reconcile"`
+}
+
+// Route is the type and workers used when nothing more specific applies.
+type Route struct {
+	Type    string   `yaml:"type"`    // type of unclassified cases; defaults to DefaultCaseType
+	Workers []string `yaml:"workers"` // workers for types without an override; empty runs every worker
+}
+
+// RoutingCue lists the signals that classify a case as one type. Each
+// matching entry counts once; the type with the most matches wins.
+type RoutingCue struct {
+	Keywords []string `yaml:"keywords"` // case-insensitive text in the case title or body
+	Paths    []string `yaml:"paths"`    // globs against the case's paths field
+	Labels   []string `yaml:"labels"`   // entries of the case's labels field
+	Priority int      `yaml:"priority"` // breaks ties between equally matched types; higher wins
+}
+
+// ReconcileConfig decides the transition a case takes once its workers'
+// findings and recommendations are merged. Without rules, recommendations are
+// recorded and the status is left alone.
+type ReconcileConfig struct {
+	Rules []ReconcileRule `yaml:"rules"` // tried in order; the first that matches and is allowed from the case's status applies
+}
+
+// ReconcileRule applies Transition when every criterion in When holds.
+type ReconcileRule struct {
+	Name       string         `yaml:"name"`
+	When       ReconcileMatch `yaml:"when"`
+	Transition string         `yaml:"transition"` // action from transitions.yaml
+}
+
+// ReconcileMatch lists the criteria of a reconcile rule. Each non-empty
+// criterion must hold; a rule without criteria always matches.
+type ReconcileMatch struct {
+	Severities []string `yaml:"severities"` // a finding has one of these severities
+	Codes      []string `yaml:"codes"`      // a finding code matches one of these globs; with severities, the same finding must match both
+	Recommend  []string `yaml:"recommend"`  // a worker recommends one of these statuses
+	Clean      bool     `yaml:"clean"`      // no worker reported a finding
+}
+
+// DefaultType returns the type of unclassified cases.
+func (c RoutingConfig) DefaultType() string {
+	if c.DefaultRoute.Type != "" {
+		return c.DefaultRoute.Type
+	}
+	return DefaultCaseType
+}
+
+// DefaultRiskLevels are the risk levels used when risk.yaml declares none,
+// lowest first.
+var DefaultRiskLevels = []string{"low", "medium", "high", "critical"}
+
+// RiskConfig is the risk.yaml schema. Matching rules add their score to a
+// case; the level is the highest one whose threshold the total reaches.
+type RiskConfig struct {
+	Levels     []string                  `yaml:"levels"`     // lowest first; defaults to DefaultRiskLevels
+	Thresholds map[string]int            `yaml:"thresholds"` // minimum score per level; the lowest level starts at 0
+	Rules      []RiskRule                `yaml:"rules"`
+	Escalation map[string]RiskEscalation `yaml:"escalation"` // keyed by level; applies at that level and above
+}
+
+// RiskRule scores a case when every criterion in Match holds.
+type RiskRule struct {
+	Name  string    `yaml:"name"`
+	Score int       `yaml:"score"`
+	Match RiskMatch `yaml:"match"`
+}
+
+// RiskMatch lists the criteria of a rule. Each non-empty criterion must match;
+// within a criterion any entry may match. Path and field patterns are globs
+// where * stays within a path segment and ** crosses segments.
+type RiskMatch struct {
+	Types  []string          `yaml:"types"`  // case type
+	Paths  []string          `yaml:"paths"`  // globs against the case's paths field
+	Labels []string          `yaml:"labels"` // entries of the case's labels field
+	Fields map[string]string `yaml:"fields"` // frontmatter key -> glob on its value
+}
+
+// RiskEscalation is what a risk level demands of a case.
+type RiskEscalation struct {
+	Transition string   `yaml:"transition"` // action forced on the case, when allowed from its status
+	Workers    []string `yaml:"workers"`    // workers that must run in the dispatch cycle
+}
+
+// LevelNames returns the configured risk levels, lowest first.
+func (c RiskConfig) LevelNames() []string {
+	if len(c.Levels) > 0 {
+		return c.Levels
+	}
+	return DefaultRiskLevels
+}
+
+// Configured reports whether risk.yaml declares any rules or escalations.
+func (c RiskConfig) Configured() bool {
+	return len(c.Rules) > 0 || len(c.Escalation) > 0
+}
+
+// DefaultBudgetSidecar is the case sidecar budget consumption is tracked in.
+const DefaultBudgetSidecar = "budget.json"
+
+// BudgetConfig is the budget.yaml schema.
+type BudgetConfig struct {
+	Limits   BudgetLimits   `yaml:"limits"`
+	Tracking BudgetTracking `yaml:"tracking"`
+}
+
+// BudgetLimits caps the work spent on one case. Zero means no limit.
+type BudgetLimits struct {
+	MaxWorkers    int    `yaml:"max_workers"`    // worker runs per case, across dispatch cycles
+	WorkerTimeout string `yaml:"worker_timeout"` // wall-clock per worker run, e.g. "10m"
+	MaxIterations int    `yaml:"max_iterations"` // dispatch cycles per case; iterations per loop run
+	MaxCommands   int    `yaml:"max_commands"`   // external commands (workers, hooks, loop steps)
+}
+
+// BudgetTracking configures where consumption is recorded.
+type BudgetTracking struct {
+	Sidecar string `yaml:"sidecar"` // case-relative path; defaults to DefaultBudgetSidecar
+}
+
+// Configured reports whether budget.yaml sets any limit.
+func (c BudgetConfig) Configured() bool {
+	return c.Limits != BudgetLimits{}
+}
+
+// Timeout parses WorkerTimeout. It returns 0 when no timeout is set.
+func (l BudgetLimits) Timeout() (time.Duration, error) {
+	if l.WorkerTimeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(l.WorkerTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("worker_timeout: %w", err)
+	}
+	return d, nil
+}
+
+// SidecarPath returns the case-relative path of the budget sidecar.
+func (t BudgetTracking) SidecarPath() string {
+	if t.Sidecar != "" {
+		return t.Sidecar
+	}
+	return DefaultBudgetSidecar
+}
+
+// BlockedStatus is the status of a case that cannot be worked until
+// something outside the slot changes.
+const BlockedStatus = "blocked"
+
+// DefaultQueueStatuses are the statuses (or categories) `agentops next`
+// picks from when queue.yaml names none. BlockedStatus is left out of them.
+var DefaultQueueStatuses = []string{"active"}
+
+// DefaultQueueWeights are used when queue.yaml sets no weight.
+var DefaultQueueWeights = QueueWeights{Priority: 10, Risk: 5, Age: 1}
+
+// DefaultQueuePriorities rank the priority field's named values.
+var DefaultQueuePriorities = map[string]int{"low": 0, "medium": 1, "high": 2, "critical": 3}
+
+// QueueConfig is the queue.yaml schema: how `agentops next` ranks the
+// unclaimed cases a slot may pick up.
+type QueueConfig struct {
+	Statuses   []string       `yaml:"statuses"`   // statuses or categories eligible; defaults to DefaultQueueStatuses without blocked
+	Weights    QueueWeights   `yaml:"weights"`    // all zero means DefaultQueueWeights
+	Priorities map[string]int `yaml:"priorities"` // priority field value -> rank; defaults to DefaultQueuePriorities
+}
+
+// QueueWeights multiply each ranking signal. A case's score is the sum of
+// weight times signal; the highest score is picked first.
+type QueueWeights struct {
+	Priority float64 `yaml:"priority"` // per priority rank (the priority field)
+	Risk     float64 `yaml:"risk"`     // per risk level above the lowest
+	Age      float64 `yaml:"age"`      // per day since the case was created
+}
+
+// EligibleStatuses returns the configured statuses, or the defaults.
+func (c QueueConfig) EligibleStatuses() []string {
+	if len(c.Statuses) > 0 {
+		return c.Statuses
+	}
+	return DefaultQueueStatuses
+}
+
+// ExcludedStatuses returns the statuses dropped from the expanded eligible
+// set: BlockedStatus when the defaults apply, none when statuses are
+// configured.
+func (c QueueConfig) ExcludedStatuses() []string {
+	if len(c.Statuses) > 0 {
+		return nil
+	}
+	return []string{BlockedStatus}
+}
+
+// EffectiveWeights returns the configured weights, or the defaults when none
+// is set.
+func (c QueueConfig) EffectiveWeights() QueueWeights {
+	if c.Weights == (QueueWeights{}) {
+		return DefaultQueueWeights
+	}
+	return c.Weights
+}
+
+// PriorityRanks returns the configured priority ranks, or the defaults.
+func (c QueueConfig) PriorityRanks() map[string]int {
+	if len(c.Priorities) > 0 {
+		return c.Priorities
+	}
+	return DefaultQueuePriorities
+}
+
+// HooksConfig binds lifecycle hook points (see protocol/hooks.md) to actions.
 type HooksConfig struct {
-	PreDispatch []string `yaml:"pre_dispatch"`
-	PostClose   []string `yaml:"post_close"`
+	SkillRunner      string    `yaml:"skill_runner"` // command prefix used to invoke skill hooks
+	PreDispatch      []HookDef `yaml:"pre_dispatch"`
+	OnCaseOpen       []HookDef `yaml:"on_case_open"`
+	OnCaseTransition []HookDef `yaml:"on_case_transition"`
+	OnWorkerComplete []HookDef `yaml:"on_worker_complete"`
+	OnReconcileDone  []HookDef `yaml:"on_reconcile_done"`
+	PostClose        []HookDef `yaml:"post_close"`
+}
+
+// Hook kinds.
+const (
+	HookShell    = "shell"
+	HookAgentops = "agentops"
+	HookSkill    = "skill"
+)
+
+// HookDef describes one hook action. Exactly one of Shell, Agentops or Skill
+// must be set. A plain YAML string is shorthand for a shell hook.
+type HookDef struct {
+	Shell    string `yaml:"shell"`    // shell command run via sh -c
+	Agentops string `yaml:"agentops"` // agentops subcommand, e.g. "slot doctor"
+	Skill    string `yaml:"skill"`    // skill name invoked through skill_runner
+	Blocking bool   `yaml:"blocking"` // failure aborts the triggering operation
+}
+
+// hookKeys are the keys a hook mapping may set.
+var hookKeys = []string{"shell", "agentops", "skill", "blocking"}
+
+// UnmarshalYAML accepts either a plain command string or a mapping. Unknown
+// mapping keys are reported like the decoder's own KnownFields errors, since
+// the nested decode below does not inherit that setting.
+func (h *HookDef) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		h.Shell = node.Value
+		return nil
+	}
+	if node.Kind == yaml.MappingNode {
+		var unknown []string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if key := node.Content[i]; !slices.Contains(hookKeys, key.Value) {
+				unknown = append(unknown, fmt.Sprintf("line %d: field %s not found in type strategy.HookDef", key.Line, key.Value))
+			}
+		}
+		if len(unknown) > 0 {
+			return &yaml.TypeError{Errors: unknown}
+		}
+	}
+	type plain HookDef
+	var p plain
+	if err := node.Decode(&p); err != nil {
+		return err
+	}
+	*h = HookDef(p)
+	return nil
+}
+
+// Kind returns the hook kind and its command or skill name.
+func (h HookDef) Kind() (string, string, error) {
+	var kinds []string
+	if h.Shell != "" {
+		kinds = append(kinds, HookShell)
+	}
+	if h.Agentops != "" {
+		kinds = append(kinds, HookAgentops)
+	}
+	if h.Skill != "" {
+		kinds = append(kinds, HookSkill)
+	}
+	if len(kinds) != 1 {
+		return "", "", fmt.Errorf("hook must set exactly one of shell, agentops or skill (got %v)", kinds)
+	}
+	switch kinds[0] {
+	case HookShell:
+		return HookShell, h.Shell, nil
+	case HookAgentops:
+		return HookAgentops, h.Agentops, nil
+	default:
+		return HookSkill, h.Skill, nil
+	}
+}
+
+// For returns the hooks bound to a hook point such as "on-case-open".
+func (c HooksConfig) For(point string) []HookDef {
+	switch point {
+	case "pre-dispatch":
+		return c.PreDispatch
+	case "on-case-open":
+		return c.OnCaseOpen
+	case "on-case-transition":
+		return c.OnCaseTransition
+	case "on-worker-complete":
+		return c.OnWorkerComplete
+	case "on-reconcile-done":
+		return c.OnReconcileDone
+	case "on-case-close":
+		return c.PostClose
+	}
+	return nil
 }
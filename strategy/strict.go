@@ -0,0 +1,180 @@
+package strategy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	agentops "github.com/gh-xj/agentops"
+	"gopkg.in/yaml.v3"
+)
+
+// Load finding codes.
+const (
+	FindingParseError   = "parse_error"      // the file is not valid YAML
+	FindingUnknownKey   = "unknown_key"      // a key the schema does not declare
+	FindingInvalidType  = "invalid_type"     // a value of the wrong YAML type
+	FindingInvalidValue = "invalid_value"    // a value outside its allowed set
+	FindingInvalid      = "invalid_strategy" // a load failure without a position
+)
+
+// StorageBackends are the accepted storage.yaml backend values.
+var StorageBackends = []string{"separate-repo", "in-repo"}
+
+// enumKeys lists, per file, top-level keys restricted to a set of values.
+var enumKeys = map[string]map[string][]string{
+	"storage.yaml": {"backend": StorageBackends},
+}
+
+// LoadError reports every problem found while loading a strategy, each
+// positioned as path:line where the YAML parser knows the line.
+type LoadError struct {
+	Findings []agentops.DoctorFinding
+}
+
+func (e *LoadError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid strategy:")
+	for _, f := range e.Findings {
+		fmt.Fprintf(&b, "\n  %s: %s", f.Path, f.Message)
+	}
+	return b.String()
+}
+
+// ExitCode maps an invalid strategy to ExitValidationFailed.
+func (e *LoadError) ExitCode() int { return agentops.ExitValidationFailed }
+
+// Missing returns the error a command that needs a strategy reports when none
+// is loaded: loadErr when loading failed, otherwise strategy_missing.
+func Missing(loadErr error) error {
+	if loadErr != nil {
+		return loadErr
+	}
+	return agentops.NewCLIError(agentops.ExitStrategyMissing, "strategy_missing", "no strategy loaded", nil)
+}
+
+// asLoadError wraps an unpositioned load failure as a single finding.
+func asLoadError(err error) error {
+	var le *LoadError
+	if errors.As(err, &le) {
+		return err
+	}
+	return &LoadError{Findings: []agentops.DoctorFinding{{Code: FindingInvalid, Path: ".agentops", Message: err.Error()}}}
+}
+
+// fileTargets returns a fresh decode target for each strict-checked file.
+func fileTargets() map[string]any {
+	return map[string]any{
+		ManifestFile:       &Manifest{},
+		"storage.yaml":     &StorageConfig{},
+		"transitions.yaml": &TransitionsConfig{},
+		"risk.yaml":        &RiskConfig{},
+		"routing.yaml":     &RoutingConfig{},
+		"budget.yaml":      &BudgetConfig{},
+		"queue.yaml":       &QueueConfig{},
+		"hooks.yaml":       &HooksConfig{},
+	}
+}
+
+// checkLayers decodes every layer's files strictly, before they are merged,
+// so findings point at the file and line that caused them.
+func checkLayers(layers []layer) []agentops.DoctorFinding {
+	var findings []agentops.DoctorFinding
+	for _, l := range layers {
+		targets := fileTargets()
+		for _, name := range append([]string{ManifestFile}, configFiles...) {
+			data, err := os.ReadFile(filepath.Join(l.dir, name))
+			if err != nil {
+				continue // a missing file is checked by doctor, not here
+			}
+			findings = append(findings, checkFile(l.label+"/"+name, data, targets[name])...)
+		}
+	}
+	return findings
+}
+
+var (
+	yamlLinePattern  = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownFieldText = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+)
+
+// checkFile strictly decodes data into target and checks enum values. path
+// is the file as reported in findings.
+func checkFile(path string, data []byte, target any) []agentops.DoctorFinding {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err := dec.Decode(target)
+	if err == nil || errors.Is(err, io.EOF) {
+		return checkEnums(path, data)
+	}
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		line, msg := splitLine(err.Error())
+		return []agentops.DoctorFinding{{Code: FindingParseError, Path: at(path, line), Message: msg}}
+	}
+	var findings []agentops.DoctorFinding
+	for _, e := range typeErr.Errors {
+		line, msg := splitLine(e)
+		code := FindingInvalidType
+		if m := unknownFieldText.FindStringSubmatch(msg); m != nil {
+			code, msg = FindingUnknownKey, fmt.Sprintf("unknown key %q", m[1])
+		}
+		findings = append(findings, agentops.DoctorFinding{Code: code, Path: at(path, line), Message: msg})
+	}
+	return append(findings, checkEnums(path, data)...)
+}
+
+// checkEnums reports top-level values outside their allowed set.
+func checkEnums(path string, data []byte) []agentops.DoctorFinding {
+	keys := enumKeys[filepath.Base(path)]
+	if len(keys) == 0 {
+		return nil
+	}
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	root := doc.Content[0]
+	var findings []agentops.DoctorFinding
+	for _, key := range sortedKeys(keys) {
+		i := mappingIndex(root, key)
+		if i < 0 {
+			continue
+		}
+		val := root.Content[i+1]
+		if val.Kind != yaml.ScalarNode || val.Value == "" || slices.Contains(keys[key], val.Value) {
+			continue
+		}
+		findings = append(findings, agentops.DoctorFinding{
+			Code:    FindingInvalidValue,
+			Path:    at(path, val.Line),
+			Message: fmt.Sprintf("%s %q: must be one of %s", key, val.Value, strings.Join(keys[key], ", ")),
+		})
+	}
+	return findings
+}
+
+// splitLine separates the "line N: " prefix of a yaml.v3 error message.
+func splitLine(msg string) (int, string) {
+	if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n, m[2]
+	}
+	return 0, strings.TrimPrefix(msg, "yaml: ")
+}
+
+// at formats path:line, or path when the line is unknown.
+func at(path string, line int) string {
+	if line <= 0 {
+		return path
+	}
+	return fmt.Sprintf("%s:%d", path, line)
+}
@@ -0,0 +1,192 @@
+package strategy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplatesDir holds case templates: templates/<type>.md is used instead of
+// schema.md for new cases of that type.
+const TemplatesDir = "templates"
+
+// TemplateInputsKey is the case template frontmatter key that declares the
+// values the template expects. Like schema.md's fields, it is not copied
+// into new cases.
+const TemplateInputsKey = "inputs"
+
+// reservedInputs are the names `case create` already uses for its own
+// arguments.
+var reservedInputs = map[string]bool{"slug": true, "type": true, "set": true, "classify": true}
+
+var inputName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// CaseTemplate is a parsed templates/<type>.md.
+type CaseTemplate struct {
+	Type   string
+	Layer  string // layer the template was read from
+	Source string // path relative to the project root, for messages
+	Inputs []TemplateInput
+
+	tmpl *template.Template
+}
+
+// TemplateInput declares a value a case template reads from .Values.
+type TemplateInput struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+	Default     string `yaml:"default"`
+}
+
+// TemplateData is what a case template is executed with.
+type TemplateData struct {
+	ID       string            // case ID, e.g. CASE-20260102-login-bug
+	Slug     string            // slug the case was created with
+	Title    string            // slug as words
+	Date     string            // creation date, YYYY-MM-DD
+	Type     string            // case type
+	Status   string            // initial status
+	Slot     string            // slot the case is filed under; "unassigned" outside a slot
+	Values   map[string]string // template inputs and --set values
+	Strategy TemplateStrategy
+}
+
+// TemplateStrategy is the part of the strategy case templates can read.
+type TemplateStrategy struct {
+	Root        string   // project root
+	Initial     string   // initial status
+	DefaultType string   // type of unclassified cases
+	RiskLevels  []string // lowest first
+}
+
+// TemplateValues returns the strategy values passed to case templates.
+func (s *Strategy) TemplateValues() TemplateStrategy {
+	return TemplateStrategy{
+		Root:        s.Root,
+		Initial:     s.Transitions.Initial,
+		DefaultType: s.Routing.DefaultType(),
+		RiskLevels:  s.Risk.LevelNames(),
+	}
+}
+
+// TemplateTypes returns the case types with a template, sorted.
+func (s *Strategy) TemplateTypes() []string {
+	return sortedKeys(s.Templates)
+}
+
+// Execute renders the template. Values the data does not set render empty.
+func (t *CaseTemplate) Execute(data TemplateData) (string, error) {
+	var out strings.Builder
+	if err := t.tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%s: %w", t.Source, err)
+	}
+	return out.String(), nil
+}
+
+// loadTemplates reads templates/*.md from every layer. A higher layer's
+// template replaces a lower layer's of the same type.
+func loadTemplates(layers []layer) (map[string]*CaseTemplate, error) {
+	templates := make(map[string]*CaseTemplate)
+	for _, l := range layers {
+		dir := filepath.Join(l.dir, TemplatesDir)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			typ, ok := strings.CutSuffix(e.Name(), ".md")
+			if e.IsDir() || !ok || typ == "" {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			t, err := parseTemplate(typ, filepath.Join(l.label, TemplatesDir, e.Name()), string(data))
+			if err != nil {
+				return nil, err
+			}
+			t.Layer = l.label
+			templates[typ] = t
+		}
+	}
+	return templates, nil
+}
+
+// parseTemplate parses a case template and reads its inputs declaration
+// from the frontmatter it renders to with empty data.
+func parseTemplate(typ, source, content string) (*CaseTemplate, error) {
+	tmpl, err := template.New(source).Option("missingkey=zero").Parse(content)
+	if err != nil {
+		return nil, err // text/template errors name the source
+	}
+	t := &CaseTemplate{Type: typ, Source: source, tmpl: tmpl}
+	rendered, err := t.Execute(TemplateData{})
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(rendered, "---\n") {
+		return t, nil
+	}
+	rest := rendered[4:]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return nil, fmt.Errorf("%s: unterminated YAML frontmatter", source)
+	}
+	var decl struct {
+		Inputs []TemplateInput `yaml:"inputs"`
+	}
+	if err := yaml.Unmarshal([]byte(rest[:end]), &decl); err != nil {
+		return nil, fmt.Errorf("%s: parse frontmatter: %w", source, err)
+	}
+	seen := make(map[string]bool)
+	for i, in := range decl.Inputs {
+		switch {
+		case !inputName.MatchString(in.Name):
+			return nil, fmt.Errorf("%s: inputs[%d]: name %q must be lower_snake_case", source, i, in.Name)
+		case reservedInputs[in.Name]:
+			return nil, fmt.Errorf("%s: input %q is reserved by case create", source, in.Name)
+		case seen[in.Name]:
+			return nil, fmt.Errorf("%s: input %q declared twice", source, in.Name)
+		}
+		seen[in.Name] = true
+	}
+	t.Inputs = decl.Inputs
+	return t, nil
+}
+
+// TemplateInputs returns every input declared by any template, each name
+// once, with the types declaring it. The first declaration's description and
+// default are kept.
+func (s *Strategy) TemplateInputs() []TemplateInputUse {
+	byName := make(map[string]*TemplateInputUse)
+	for _, typ := range s.TemplateTypes() {
+		for _, in := range s.Templates[typ].Inputs {
+			use, ok := byName[in.Name]
+			if !ok {
+				use = &TemplateInputUse{TemplateInput: in}
+				byName[in.Name] = use
+			}
+			use.Types = append(use.Types, typ)
+		}
+	}
+	out := make([]TemplateInputUse, 0, len(byName))
+	for _, use := range byName {
+		out = append(out, *use)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// TemplateInputUse is an input and the template types that declare it.
+type TemplateInputUse struct {
+	TemplateInput
+	Types []string
+}
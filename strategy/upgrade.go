@@ -0,0 +1,274 @@
+package strategy
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentSchemaVersion is the strategy layout written by Bootstrap. Version 1
+// is the layout from before strategy.yaml recorded a version.
+const CurrentSchemaVersion = 2
+
+// Embedded defaults of earlier schema versions, as history/v<N>/, used as the
+// base of the three-way comparison in Upgrade.
+//
+//go:embed history
+var historyFS embed.FS
+
+// Upgrade actions reported per file.
+const (
+	UpgradeUnchanged  = "unchanged"  // matches the current default
+	UpgradeAdded      = "added"      // new default file
+	UpgradeUpdated    = "updated"    // untouched old default replaced by the new one
+	UpgradeCustomized = "customized" // edited locally; the default did not change
+	UpgradeConflict   = "conflict"   // edited locally and the default changed; new default written to <file>.new
+	UpgradeMigrated   = "migrated"   // rewritten by a migration
+)
+
+// Migration rewrites strategy files from one schema version to the next.
+type Migration struct {
+	From        int // schema version upgraded from; the result is From+1
+	Description string
+	// Apply edits files (name -> content) in place and returns the names it
+	// changed.
+	Apply func(files map[string]string) ([]string, error)
+}
+
+// migrations run in order from a directory's version to CurrentSchemaVersion.
+var migrations = []Migration{
+	{From: 1, Description: "declare case fields in schema.md frontmatter", Apply: declareSchemaFields},
+}
+
+// UpgradeReport describes what Upgrade changed, or would change.
+type UpgradeReport struct {
+	Dir        string        `json:"dir"`
+	From       int           `json:"from"`
+	To         int           `json:"to"`
+	DryRun     bool          `json:"dry_run"`
+	Migrations []string      `json:"migrations,omitempty"`
+	Files      []FileUpgrade `json:"files"`
+}
+
+// FileUpgrade is the outcome for one strategy file. Yours and Defaults are
+// unified diffs from the old default: the local edits and the default's
+// changes.
+type FileUpgrade struct {
+	Name     string `json:"name"`
+	Action   string `json:"action"`
+	Yours    string `json:"yours,omitempty"`
+	Defaults string `json:"defaults,omitempty"`
+}
+
+// Upgrade brings the strategy in projectDir/.agentops/ to
+// CurrentSchemaVersion. Each file is compared three ways: the default it was
+// created from, the local copy, and the current default. Untouched defaults
+// are replaced, local edits are kept, and where both changed the new default
+// is written alongside as <file>.new. Migrations then run in order and
+// schema_version is recorded in strategy.yaml. With dryRun nothing is
+// written.
+func Upgrade(projectDir string, dryRun bool) (*UpgradeReport, error) {
+	dir := filepath.Join(projectDir, ".agentops")
+	var m Manifest
+	if err := loadYAML(filepath.Join(dir, ManifestFile), &m); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s: %w", ManifestFile, err)
+	}
+	report := &UpgradeReport{Dir: dir, From: m.Version(), To: CurrentSchemaVersion, DryRun: dryRun}
+	if report.From > CurrentSchemaVersion {
+		return nil, fmt.Errorf("schema_version %d is newer than this agentops supports (%d)", report.From, CurrentSchemaVersion)
+	}
+
+	base, err := defaultsFor(report.From)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := defaultsFor(CurrentSchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)  // resulting content of each file
+	writes := make(map[string]string) // files to write, including <file>.new
+	for _, name := range unionKeys(base, theirs) {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		exists := err == nil
+		ours := string(data)
+		b, inBase := base[name]
+		t, inTheirs := theirs[name]
+
+		f := FileUpgrade{Name: name}
+		switch {
+		case !exists && !inBase && inTheirs:
+			f.Action = UpgradeAdded
+			f.Defaults = unifiedDiff("/dev/null", name+" (default)", "", t)
+			files[name], writes[name] = t, t
+		case !exists:
+			continue // removed locally or from the defaults; nothing to do
+		case ours == t:
+			f.Action = UpgradeUnchanged
+			files[name] = ours
+		case inBase && ours == b:
+			f.Action = UpgradeUpdated
+			f.Defaults = unifiedDiff(name+" (v"+fmt.Sprint(report.From)+" default)", name+" (default)", b, t)
+			files[name], writes[name] = t, t
+		case !inBase || !inTheirs || b == t:
+			f.Action = UpgradeCustomized
+			files[name] = ours
+		default:
+			f.Action = UpgradeConflict
+			from := name + " (v" + fmt.Sprint(report.From) + " default)"
+			f.Yours = unifiedDiff(from, name+" (yours)", b, ours)
+			f.Defaults = unifiedDiff(from, name+" (default)", b, t)
+			files[name] = ours
+			writes[name+".new"] = t
+		}
+		report.Files = append(report.Files, f)
+	}
+
+	for _, mig := range migrations {
+		if mig.From < report.From || mig.From >= CurrentSchemaVersion {
+			continue
+		}
+		changed, err := mig.Apply(files)
+		if err != nil {
+			return nil, fmt.Errorf("migration v%d: %w", mig.From, err)
+		}
+		report.Migrations = append(report.Migrations, fmt.Sprintf("v%d -> v%d: %s", mig.From, mig.From+1, mig.Description))
+		for _, name := range changed {
+			writes[name] = files[name]
+			report.migrated(name)
+		}
+	}
+
+	if manifest := setSchemaVersion(files[ManifestFile], CurrentSchemaVersion); manifest != files[ManifestFile] {
+		files[ManifestFile], writes[ManifestFile] = manifest, manifest
+		report.migrated(ManifestFile)
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	for _, name := range sortedKeys(writes) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(writes[name]), 0o644); err != nil {
+			return nil, fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	return report, nil
+}
+
+// migrated marks a file rewritten after the three-way step. Added files and
+// conflicts keep their action.
+func (r *UpgradeReport) migrated(name string) {
+	for i := range r.Files {
+		if r.Files[i].Name == name {
+			if a := r.Files[i].Action; a != UpgradeAdded && a != UpgradeConflict {
+				r.Files[i].Action = UpgradeMigrated
+			}
+			return
+		}
+	}
+	r.Files = append(r.Files, FileUpgrade{Name: name, Action: UpgradeAdded})
+}
+
+// Version returns the declared schema version; strategies from before
+// versioning are version 1.
+func (m Manifest) Version() int {
+	if m.SchemaVersion > 0 {
+		return m.SchemaVersion
+	}
+	return 1
+}
+
+// defaultsFor returns the embedded default files of a schema version.
+func defaultsFor(version int) (map[string]string, error) {
+	fsys, root := fs.FS(defaultsFS), "defaults"
+	if version != CurrentSchemaVersion {
+		fsys, root = historyFS, fmt.Sprintf("history/v%d", version)
+	}
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, fmt.Errorf("no embedded defaults for schema_version %d", version)
+	}
+	files := make(map[string]string, len(entries))
+	for _, e := range entries {
+		data, err := fs.ReadFile(fsys, root+"/"+e.Name())
+		if err != nil {
+			return nil, err
+		}
+		files[e.Name()] = string(data)
+	}
+	return files, nil
+}
+
+// unionKeys returns the keys of a and b, sorted.
+func unionKeys(a, b map[string]string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var schemaVersionLine = regexp.MustCompile(`(?m)^schema_version:.*$`)
+
+// setSchemaVersion records version in strategy.yaml content, keeping the
+// rest of the file as written.
+func setSchemaVersion(content string, version int) string {
+	line := fmt.Sprintf("schema_version: %d", version)
+	if schemaVersionLine.MatchString(content) {
+		return schemaVersionLine.ReplaceAllString(content, line)
+	}
+	return line + "\n" + content
+}
+
+// v1FieldsBlock is the fields declaration added to schema.md in version 2.
+const v1FieldsBlock = `# fields declares the case frontmatter schema used by ` + "`agentops case`" + `
+# validate and list. It is not copied into new cases. Types: string, int,
+# bool, list, date, enum (with values). Undeclared keys are kept as written.
+fields:
+  - {name: type, type: string, required: true}
+  - {name: status, type: string, required: true}
+  - {name: claimed_by, type: string}
+  - {name: claimed_at, type: string}
+  - {name: created, type: date, required: true}
+`
+
+// declareSchemaFields adds the version 2 fields declaration to a schema.md
+// whose frontmatter has none.
+func declareSchemaFields(files map[string]string) ([]string, error) {
+	content, ok := files["schema.md"]
+	if !ok || !strings.HasPrefix(content, "---\n") {
+		return nil, nil
+	}
+	end := strings.Index(content[4:], "\n---")
+	if end < 0 {
+		return nil, fmt.Errorf("schema.md: unterminated YAML frontmatter")
+	}
+	var fm map[string]any
+	if err := yaml.Unmarshal([]byte(content[4:4+end]), &fm); err != nil {
+		return nil, fmt.Errorf("schema.md: %w", err)
+	}
+	if _, ok := fm[SchemaFieldsKey]; ok {
+		return nil, nil
+	}
+	files["schema.md"] = "---\n" + v1FieldsBlock + content[4:]
+	return []string{"schema.md"}, nil
+}
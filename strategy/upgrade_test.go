@@ -0,0 +1,145 @@
+package strategy_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gh-xj/agentops/strategy"
+)
+
+// setupV1Project writes the version 1 defaults, as an unversioned project
+// created before strategy.yaml existed.
+func setupV1Project(t *testing.T) string {
+	t.Helper()
+	tmp := t.TempDir()
+	dir := filepath.Join(tmp, ".agentops")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Join("history", "v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join("history", "v1", e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, e.Name()), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tmp
+}
+
+func TestUpgradeFromV1(t *testing.T) {
+	tmp := setupV1Project(t)
+	dir := filepath.Join(tmp, ".agentops")
+	schemaPath := filepath.Join(dir, "schema.md")
+	data, err := os.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom := strings.Replace(string(data), "## Findings", "## Evidence", 1)
+	if err := os.WriteFile(schemaPath, []byte(custom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if strat.SchemaVersion != 1 {
+		t.Errorf("SchemaVersion = %d, want 1", strat.SchemaVersion)
+	}
+	if f := strat.Validate(); len(f) == 0 || f[0].Code != "outdated_schema" {
+		t.Errorf("findings = %+v, want outdated_schema", f)
+	}
+
+	dry, err := strategy.Upgrade(tmp, true)
+	if err != nil {
+		t.Fatalf("Upgrade dry run: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, strategy.ManifestFile)); !os.IsNotExist(err) {
+		t.Fatalf("dry run wrote strategy.yaml")
+	}
+
+	report, err := strategy.Upgrade(tmp, false)
+	if err != nil {
+		t.Fatalf("Upgrade: %v", err)
+	}
+	if report.From != 1 || report.To != strategy.CurrentSchemaVersion || len(report.Migrations) != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	actions := make(map[string]string)
+	for _, f := range report.Files {
+		actions[f.Name] = f.Action
+	}
+	want := map[string]string{
+		"hooks.yaml":    strategy.UpgradeUpdated,
+		"slot.md":       strategy.UpgradeUnchanged,
+		"strategy.yaml": strategy.UpgradeAdded,
+		"schema.md":     strategy.UpgradeConflict,
+	}
+	for name, action := range want {
+		if actions[name] != action {
+			t.Errorf("%s: action = %q, want %q", name, actions[name], action)
+		}
+	}
+	if len(dry.Files) != len(report.Files) {
+		t.Errorf("dry run reported %d files, upgrade %d", len(dry.Files), len(report.Files))
+	}
+	for _, f := range report.Files {
+		if f.Name == "schema.md" && (!strings.Contains(f.Yours, "-## Findings\n+## Evidence\n") || !strings.Contains(f.Defaults, "+fields:\n")) {
+			t.Errorf("schema.md diffs:\n%s\n%s", f.Yours, f.Defaults)
+		}
+	}
+
+	// The customized template keeps its edit and gains the fields declaration.
+	data, err = os.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "## Evidence") || !strings.Contains(string(data), "fields:\n") {
+		t.Errorf("schema.md = %s", data)
+	}
+	if _, err := os.Stat(schemaPath + ".new"); err != nil {
+		t.Errorf("conflict did not write schema.md.new: %v", err)
+	}
+
+	strat, err = strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("Discover after upgrade: %v", err)
+	}
+	if strat.SchemaVersion != strategy.CurrentSchemaVersion || len(strat.Fields) != 5 {
+		t.Errorf("SchemaVersion = %d, fields = %d", strat.SchemaVersion, len(strat.Fields))
+	}
+	if f := strat.Validate(); len(f) != 0 {
+		t.Errorf("findings after upgrade = %+v", f)
+	}
+
+	again, err := strategy.Upgrade(tmp, false)
+	if err != nil {
+		t.Fatalf("second Upgrade: %v", err)
+	}
+	for _, f := range again.Files {
+		if f.Action != strategy.UpgradeUnchanged && f.Action != strategy.UpgradeCustomized {
+			t.Errorf("second upgrade: %s %s", f.Action, f.Name)
+		}
+	}
+}
+
+func TestUpgradeRejectsNewerSchema(t *testing.T) {
+	tmp := t.TempDir()
+	if err := strategy.Bootstrap(tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, ".agentops", strategy.ManifestFile), []byte("schema_version: 99\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strategy.Upgrade(tmp, true); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("expected newer schema error, got %v", err)
+	}
+}
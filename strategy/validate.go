@@ -0,0 +1,329 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+
+	agentops "github.com/gh-xj/agentops"
+)
+
+// TerminalCategory is the status category whose statuses end a case's
+// lifecycle and need no outgoing transition.
+const TerminalCategory = "completed"
+
+// Validate checks the loaded strategy for structural problems and returns
+// them as doctor findings. A nil result means the strategy is sound.
+func (s *Strategy) Validate() []agentops.DoctorFinding {
+	var findings []agentops.DoctorFinding
+	if s.SchemaVersion < CurrentSchemaVersion {
+		findings = append(findings, agentops.DoctorFinding{
+			Code:    "outdated_schema",
+			Path:    ManifestFile,
+			Message: fmt.Sprintf("schema_version %d is older than %d; run `agentops strategy upgrade`", s.SchemaVersion, CurrentSchemaVersion),
+		})
+	}
+	findings = append(findings, ValidateStorage(s.Storage, s.Transitions)...)
+	findings = append(findings, ValidateTransitions(s.Transitions)...)
+	findings = append(findings, ValidateRisk(s.Risk, s.Transitions)...)
+	findings = append(findings, ValidateRouting(s.Routing, s.Risk, s.Transitions)...)
+	findings = append(findings, ValidateBudget(s.Budget)...)
+	return append(findings, ValidateQueue(s.Queue, s.Transitions)...)
+}
+
+// ValidateStorage checks storage.yaml: retention must be keyed by status
+// categories and hold non-negative ages.
+func ValidateStorage(cfg StorageConfig, transitions TransitionsConfig) []agentops.DoctorFinding {
+	const path = "storage.yaml"
+	var findings []agentops.DoctorFinding
+	add := func(code, format string, args ...any) {
+		findings = append(findings, agentops.DoctorFinding{Code: code, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, cat := range sortedKeys(cfg.Retention) {
+		if _, ok := transitions.Categories[cat]; !ok {
+			add("unknown_category", "retention for undeclared category %q", cat)
+		}
+		if d, _, err := cfg.RetentionFor(cat); err != nil {
+			add("invalid_retention", "%v", err)
+		} else if d < 0 {
+			add("invalid_retention", "retention of %s is negative (%s)", cat, cfg.Retention[cat])
+		}
+	}
+	return findings
+}
+
+// ValidateQueue checks queue.yaml: weights must not be negative and statuses
+// must name a status or category of the state machine.
+func ValidateQueue(cfg QueueConfig, transitions TransitionsConfig) []agentops.DoctorFinding {
+	const path = "queue.yaml"
+	var findings []agentops.DoctorFinding
+	add := func(code, format string, args ...any) {
+		findings = append(findings, agentops.DoctorFinding{Code: code, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	weights := []struct {
+		name  string
+		value float64
+	}{
+		{"priority", cfg.Weights.Priority},
+		{"risk", cfg.Weights.Risk},
+		{"age", cfg.Weights.Age},
+	}
+	for _, w := range weights {
+		if w.value < 0 {
+			add("invalid_weight", "weights.%s is negative (%g)", w.name, w.value)
+		}
+	}
+
+	known := make(map[string]bool)
+	for category, statuses := range transitions.Categories {
+		known[category] = true
+		for _, s := range statuses {
+			known[s] = true
+		}
+	}
+	for _, s := range cfg.Statuses {
+		if !known[s] {
+			add("unknown_status", "statuses: %q is neither a status nor a category", s)
+		}
+	}
+	return findings
+}
+
+// ValidateBudget checks budget.yaml: limits must not be negative and
+// worker_timeout must be a positive duration.
+func ValidateBudget(cfg BudgetConfig) []agentops.DoctorFinding {
+	const path = "budget.yaml"
+	var findings []agentops.DoctorFinding
+	add := func(code, format string, args ...any) {
+		findings = append(findings, agentops.DoctorFinding{Code: code, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"max_workers", cfg.Limits.MaxWorkers},
+		{"max_iterations", cfg.Limits.MaxIterations},
+		{"max_commands", cfg.Limits.MaxCommands},
+	}
+	for _, l := range limits {
+		if l.value < 0 {
+			add("invalid_limit", "%s is negative (%d)", l.name, l.value)
+		}
+	}
+	if d, err := cfg.Limits.Timeout(); err != nil {
+		add("invalid_limit", "%v", err)
+	} else if d < 0 {
+		add("invalid_limit", "worker_timeout is negative (%s)", cfg.Limits.WorkerTimeout)
+	}
+	return findings
+}
+
+// ValidateRouting checks routing.yaml: every cue must declare at least one
+// signal, overrides must be keyed by known risk levels or "*", and reconcile
+// rules must apply transitions that exist in transitions.yaml.
+func ValidateRouting(cfg RoutingConfig, risk RiskConfig, transitions TransitionsConfig) []agentops.DoctorFinding {
+	const path = "routing.yaml"
+	var findings []agentops.DoctorFinding
+	add := func(code, format string, args ...any) {
+		findings = append(findings, agentops.DoctorFinding{Code: code, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	levels := make(map[string]bool)
+	for _, level := range risk.LevelNames() {
+		levels[level] = true
+	}
+	for _, typ := range sortedKeys(cfg.Cues) {
+		cue := cfg.Cues[typ]
+		if len(cue.Keywords)+len(cue.Paths)+len(cue.Labels) == 0 {
+			add("empty_cue", "cue for type %q has no keywords, paths or labels", typ)
+		}
+	}
+	for _, typ := range sortedKeys(cfg.Overrides) {
+		for _, level := range sortedKeys(cfg.Overrides[typ]) {
+			if level != OverrideAnyRisk && !levels[level] {
+				add("unknown_level", "override for %q uses undeclared risk level %q", typ, level)
+			}
+		}
+	}
+	for i, rule := range cfg.Reconcile.Rules {
+		name := rule.Name
+		if name == "" {
+			add("missing_name", "reconcile rule %d has no name", i+1)
+			name = fmt.Sprint(i + 1)
+		}
+		switch _, ok := transitions.Transitions[rule.Transition]; {
+		case rule.Transition == "":
+			add("missing_transition", "reconcile rule %q names no transition", name)
+		case !ok:
+			add("unknown_action", "reconcile rule %q applies unknown transition %q", name, rule.Transition)
+		}
+	}
+	return findings
+}
+
+// ValidateRisk checks risk.yaml: thresholds and escalations must name known
+// levels, thresholds must rise with the level, and escalation transitions
+// must exist in transitions.yaml.
+func ValidateRisk(cfg RiskConfig, transitions TransitionsConfig) []agentops.DoctorFinding {
+	const path = "risk.yaml"
+	var findings []agentops.DoctorFinding
+	add := func(code, format string, args ...any) {
+		findings = append(findings, agentops.DoctorFinding{Code: code, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	rank := make(map[string]int)
+	for i, level := range cfg.LevelNames() {
+		rank[level] = i + 1
+	}
+	prev, prevLevel := 0, ""
+	for _, level := range cfg.LevelNames() {
+		min, ok := cfg.Thresholds[level]
+		if !ok {
+			continue
+		}
+		if prevLevel != "" && min < prev {
+			add("invalid_threshold", "threshold of %q (%d) is below that of %q (%d)", level, min, prevLevel, prev)
+		}
+		prev, prevLevel = min, level
+	}
+	for _, level := range sortedKeys(cfg.Thresholds) {
+		if rank[level] == 0 {
+			add("unknown_level", "threshold for undeclared level %q", level)
+		}
+	}
+	for _, level := range sortedKeys(cfg.Escalation) {
+		if rank[level] == 0 {
+			add("unknown_level", "escalation for undeclared level %q", level)
+		}
+		if action := cfg.Escalation[level].Transition; action != "" {
+			if _, ok := transitions.Transitions[action]; !ok {
+				add("unknown_action", "escalation for %q forces unknown transition %q", level, action)
+			}
+		}
+	}
+	for i, rule := range cfg.Rules {
+		if rule.Name == "" {
+			add("missing_name", "rule %d has no name", i+1)
+		}
+	}
+	return findings
+}
+
+// ValidateTransitions checks the state machine in transitions.yaml: initial
+// and transition statuses must be declared in a category, from lists must be
+// strings, every status must be reachable from initial, every status outside
+// the terminal category must have a transition out, and no category may take
+// the archive's name.
+func ValidateTransitions(cfg TransitionsConfig) []agentops.DoctorFinding {
+	const path = "transitions.yaml"
+	var findings []agentops.DoctorFinding
+	add := func(code, format string, args ...any) {
+		findings = append(findings, agentops.DoctorFinding{Code: code, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	known := make(map[string]string) // status -> category
+	for _, cat := range sortedKeys(cfg.Categories) {
+		if cat == ArchiveCategory {
+			add("reserved_category", "category %q is reserved for archived cases", cat)
+		}
+		for _, status := range cfg.Categories[cat] {
+			if other, dup := known[status]; dup {
+				add("duplicate_status", "status %q is listed in categories %q and %q", status, other, cat)
+				continue
+			}
+			known[status] = cat
+		}
+	}
+	if len(known) == 0 {
+		add("no_statuses", "no statuses declared under categories")
+	}
+
+	switch {
+	case cfg.Initial == "":
+		add("missing_initial", "initial status is not set")
+	case known[cfg.Initial] == "":
+		add("unknown_status", "initial status %q is not declared in any category", cfg.Initial)
+	}
+
+	next := make(map[string][]string) // status -> statuses reachable in one step
+	for _, name := range sortedKeys(cfg.Transitions) {
+		def := cfg.Transitions[name]
+		if def.To == "" {
+			add("missing_target", "transition %q has no to status", name)
+		} else if known[def.To] == "" {
+			add("unknown_status", "transition %q targets undeclared status %q", name, def.To)
+		}
+		if def.From == nil {
+			add("missing_source", "transition %q has no from status", name)
+		}
+		if bad := def.invalidFrom(); len(bad) > 0 {
+			add("invalid_source", "transition %q has non-string from entries: %v", name, bad)
+		}
+		for _, from := range def.FromStates() {
+			if known[from] == "" {
+				add("unknown_status", "transition %q starts from undeclared status %q", name, from)
+			}
+			if def.To != "" {
+				next[from] = append(next[from], def.To)
+			}
+		}
+	}
+
+	// Reachability and exits are only meaningful once initial is valid.
+	if known[cfg.Initial] == "" {
+		return findings
+	}
+	reached := map[string]bool{cfg.Initial: true}
+	queue := []string{cfg.Initial}
+	for len(queue) > 0 {
+		status := queue[0]
+		queue = queue[1:]
+		for _, to := range next[status] {
+			if !reached[to] {
+				reached[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	for _, cat := range sortedKeys(cfg.Categories) {
+		for _, status := range cfg.Categories[cat] {
+			if !reached[status] {
+				add("unreachable_status", "status %q cannot be reached from initial status %q", status, cfg.Initial)
+			}
+			if cat != TerminalCategory && len(next[status]) == 0 {
+				add("dead_end_status", "status %q is not %s but has no transition out", status, TerminalCategory)
+			}
+		}
+	}
+	return findings
+}
+
+// invalidFrom returns the from entries that are not strings.
+func (t TransitionDef) invalidFrom() []any {
+	var bad []any
+	switch v := t.From.(type) {
+	case string:
+	case []any:
+		for _, s := range v {
+			if _, ok := s.(string); !ok {
+				bad = append(bad, s)
+			}
+		}
+	case nil:
+	default:
+		bad = append(bad, v)
+	}
+	return bad
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,227 @@
+package strategy_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gh-xj/agentops/strategy"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateDefaultStrategy(t *testing.T) {
+	tmp := t.TempDir()
+	if err := strategy.Bootstrap(tmp); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	strat, err := strategy.Discover(tmp)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if findings := strat.Validate(); len(findings) != 0 {
+		t.Errorf("default strategy has findings: %+v", findings)
+	}
+}
+
+func TestValidateTransitions(t *testing.T) {
+	var cfg strategy.TransitionsConfig
+	src := `categories:
+  active: [open, in_progress, stuck, orphan]
+  completed: [done, in_progress]
+initial: new
+transitions:
+  start: {from: open, to: in_progress}
+  park: {from: [in_progress, 3], to: stuck}
+  finish: {from: ghost, to: finished}
+  nowhere: {from: open}
+`
+	if err := yaml.Unmarshal([]byte(src), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	var codes []string
+	for _, f := range strategy.ValidateTransitions(cfg) {
+		codes = append(codes, f.Code)
+	}
+	sort.Strings(codes)
+	want := []string{
+		"duplicate_status", // in_progress in two categories
+		"invalid_source",   // park from 3
+		"missing_target",   // nowhere
+		"unknown_status",   // initial new
+		"unknown_status",   // finish from ghost
+		"unknown_status",   // finish to finished
+	}
+	if strings.Join(codes, ",") != strings.Join(want, ",") {
+		t.Errorf("codes = %v, want %v", codes, want)
+	}
+
+	// With a valid initial, reachability and exits are checked too.
+	cfg.Initial = "open"
+	codes = nil
+	for _, f := range strategy.ValidateTransitions(cfg) {
+		if f.Code == "unreachable_status" || f.Code == "dead_end_status" {
+			codes = append(codes, f.Code+":"+strings.Split(f.Message, `"`)[1])
+		}
+	}
+	sort.Strings(codes)
+	want = []string{
+		"dead_end_status:orphan",
+		"dead_end_status:stuck",
+		"unreachable_status:done",
+		"unreachable_status:orphan",
+	}
+	if strings.Join(codes, ",") != strings.Join(want, ",") {
+		t.Errorf("codes = %v, want %v", codes, want)
+	}
+}
+
+func TestFromStatesSkipsNonStrings(t *testing.T) {
+	def := strategy.TransitionDef{From: []any{"open", 3, nil, "blocked"}, To: "done"}
+	got := def.FromStates()
+	if strings.Join(got, ",") != "open,blocked" {
+		t.Errorf("FromStates() = %v, want [open blocked]", got)
+	}
+}
+
+func TestRenderGraph(t *testing.T) {
+	cfg := strategy.TransitionsConfig{
+		Categories: map[string][]string{
+			"active":    {"open", "in_progress"},
+			"completed": {"resolved"},
+		},
+		Initial: "open",
+		Transitions: map[string]strategy.TransitionDef{
+			"start":   {From: "open", To: "in_progress"},
+			"resolve": {From: "in_progress", To: "resolved", Guards: strategy.TransitionGuards{Note: true}},
+		},
+	}
+
+	mermaid, err := strategy.RenderGraph(cfg, strategy.GraphMermaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantMermaid := `stateDiagram-v2
+  direction LR
+  [*] --> open
+  in_progress --> resolved: resolve (guarded)
+  open --> in_progress: start
+  resolved --> [*]
+`
+	if mermaid != wantMermaid {
+		t.Errorf("mermaid:\n%s\nwant:\n%s", mermaid, wantMermaid)
+	}
+
+	dot, err := strategy.RenderGraph(cfg, strategy.GraphDOT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`subgraph "cluster_completed"`,
+		`"resolved" [peripheries=2];`,
+		`"__start" -> "open";`,
+		`"open" -> "in_progress" [label="start"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("dot output missing %q:\n%s", want, dot)
+		}
+	}
+
+	if _, err := strategy.RenderGraph(cfg, "svg"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestValidateRisk(t *testing.T) {
+	cfg := strategy.RiskConfig{
+		Thresholds: map[string]int{"medium": 5, "high": 3, "extreme": 9},
+		Rules:      []strategy.RiskRule{{Score: 1}},
+		Escalation: map[string]strategy.RiskEscalation{
+			"high":  {Transition: "explode"},
+			"other": {Workers: []string{"review"}},
+		},
+	}
+	transitions := strategy.TransitionsConfig{
+		Transitions: map[string]strategy.TransitionDef{"block": {From: "open", To: "blocked"}},
+	}
+
+	var codes []string
+	for _, f := range strategy.ValidateRisk(cfg, transitions) {
+		codes = append(codes, f.Code)
+	}
+	sort.Strings(codes)
+	want := []string{"invalid_threshold", "missing_name", "unknown_action", "unknown_level", "unknown_level"}
+	if strings.Join(codes, ",") != strings.Join(want, ",") {
+		t.Errorf("codes = %v, want %v", codes, want)
+	}
+}
+
+func TestValidateRouting(t *testing.T) {
+	cfg := strategy.RoutingConfig{
+		Cues: map[string]strategy.RoutingCue{
+			"pr":    {Keywords: []string{"review"}},
+			"empty": {Priority: 1},
+		},
+		Overrides: map[string]map[string][]string{
+			"pr": {"high": {"review"}, strategy.OverrideAnyRisk: nil, "severe": {"review"}},
+		},
+		Reconcile: strategy.ReconcileConfig{Rules: []strategy.ReconcileRule{
+			{Name: "clean", When: strategy.ReconcileMatch{Clean: true}, Transition: "resolve"},
+			{Name: "errors", When: strategy.ReconcileMatch{Severities: []string{"error"}}, Transition: "escalate"},
+			{When: strategy.ReconcileMatch{Recommend: []string{"blocked"}}},
+		}},
+	}
+	transitions := strategy.TransitionsConfig{Transitions: map[string]strategy.TransitionDef{
+		"resolve": {From: []any{"in_progress"}, To: "resolved"},
+	}}
+
+	var codes []string
+	for _, f := range strategy.ValidateRouting(cfg, strategy.RiskConfig{}, transitions) {
+		codes = append(codes, f.Code)
+	}
+	want := []string{"empty_cue", "unknown_level", "unknown_action", "missing_name", "missing_transition"}
+	if strings.Join(codes, ",") != strings.Join(want, ",") {
+		t.Errorf("codes = %v, want %v", codes, want)
+	}
+}
+
+func TestValidateStorage(t *testing.T) {
+	cfg := strategy.StorageConfig{Retention: map[string]string{
+		"completed": "30d",
+		"active":    "a week",
+		"stale":     "-2h",
+	}}
+	transitions := strategy.TransitionsConfig{Categories: map[string][]string{
+		"active":    {"open"},
+		"completed": {"resolved"},
+	}}
+
+	var codes []string
+	for _, f := range strategy.ValidateStorage(cfg, transitions) {
+		codes = append(codes, f.Code)
+	}
+	want := []string{"invalid_retention", "unknown_category", "invalid_retention"}
+	if strings.Join(codes, ",") != strings.Join(want, ",") {
+		t.Errorf("codes = %v, want %v", codes, want)
+	}
+	if d, ok, err := cfg.RetentionFor("completed"); !ok || err != nil || d != 30*24*time.Hour {
+		t.Errorf("RetentionFor(completed) = %v, %v, %v", d, ok, err)
+	}
+}
+
+func TestValidateBudget(t *testing.T) {
+	cfg := strategy.BudgetConfig{Limits: strategy.BudgetLimits{MaxWorkers: -1, WorkerTimeout: "ten minutes"}}
+	findings := strategy.ValidateBudget(cfg)
+	if len(findings) != 2 {
+		t.Fatalf("findings = %+v", findings)
+	}
+	for _, f := range findings {
+		if f.Code != "invalid_limit" || f.Path != "budget.yaml" {
+			t.Errorf("finding = %+v", f)
+		}
+	}
+	if f := strategy.ValidateBudget(strategy.BudgetConfig{Limits: strategy.BudgetLimits{WorkerTimeout: "90s"}}); len(f) != 0 {
+		t.Errorf("valid budget findings = %+v", f)
+	}
+}
@@ -22,7 +22,6 @@ var LoopProfiles = map[string]LoopProfile{
 		RoleConfig:       "configs/skill-quality.roles.json",
 		MaxIterations:    1,
 		Threshold:        9.0,
-		Budget:           1,
 		VerboseArtifacts: true,
 	},
 }
@@ -32,7 +31,6 @@ type LoopProfile struct {
 	RoleConfig       string
 	MaxIterations    int
 	Threshold        float64
-	Budget           int
 	VerboseArtifacts bool
 }
 
@@ -41,7 +39,6 @@ type loopProfileJSON struct {
 	RoleConfig       string  `json:"role_config"`
 	MaxIterations    int     `json:"max_iterations"`
 	Threshold        float64 `json:"threshold"`
-	Budget           int     `json:"budget"`
 	VerboseArtifacts bool    `json:"verbose_artifacts"`
 }
 
@@ -91,7 +88,6 @@ type LoopLabFlags struct {
 	Mode               string
 	RoleConfig         string
 	Seed               int64
-	Budget             int
 	RunA               string
 	RunB               string
 	RunID              string
@@ -152,8 +148,8 @@ func FormatLoopProfile(name string, profile LoopProfile) string {
 	if roleConfig == "" {
 		roleConfig = "(not set)"
 	}
-	return fmt.Sprintf("%s: mode=%s threshold=%.1f max_iterations=%d budget=%d role_config=%s verbose_artifacts=%t",
-		name, mode, profile.Threshold, profile.MaxIterations, profile.Budget, roleConfig, profile.VerboseArtifacts)
+	return fmt.Sprintf("%s: mode=%s threshold=%.1f max_iterations=%d role_config=%s verbose_artifacts=%t",
+		name, mode, profile.Threshold, profile.MaxIterations, roleConfig, profile.VerboseArtifacts)
 }
 
 func ParseLoopProfilesRepoRoot(args []string) (string, error) {
@@ -340,7 +336,6 @@ func ParseLoopLabFlags(args []string) (LoopLabFlags, error) {
 	opts := LoopLabFlags{
 		LoopFlags: base,
 		Mode:      "committee",
-		Budget:    1,
 		Format:    "json",
 	}
 	for i := 0; i < len(remaining); i++ {
@@ -365,14 +360,6 @@ func ParseLoopLabFlags(args []string) (LoopLabFlags, error) {
 				return LoopLabFlags{}, fmt.Errorf("invalid --seed value")
 			}
 			i++
-		case "--budget":
-			if i+1 >= len(remaining) {
-				return LoopLabFlags{}, fmt.Errorf("--budget requires a value")
-			}
-			if _, err := fmt.Sscanf(remaining[i+1], "%d", &opts.Budget); err != nil {
-				return LoopLabFlags{}, fmt.Errorf("invalid --budget value")
-			}
-			i++
 		case "--run-a":
 			if i+1 >= len(remaining) {
 				return LoopLabFlags{}, fmt.Errorf("--run-a requires a value")
@@ -631,7 +618,6 @@ func (h *LoopHandler) runLoopClassicCommand(action string, args []string, ctx ha
 		Branch:           opts.Branch,
 		Mode:             "committee",
 		RoleConfigPath:   "",
-		Budget:           1,
 		VerboseArtifacts: false,
 	}
 	if action == "autofix" {
@@ -691,7 +677,6 @@ func (h *LoopHandler) runLoopProfileCommand(name string, args []string, ctx harn
 		Branch:           opts.Branch,
 		Mode:             profile.Mode,
 		RoleConfigPath:   roleConfigPath,
-		Budget:           profile.Budget,
 		VerboseArtifacts: verboseArtifacts,
 	}
 	if ctx.DryRun {
@@ -755,7 +740,6 @@ func (h *LoopHandler) runLoopRegressionCommand(args []string, ctx harness.Contex
 		Branch:           opts.Branch,
 		Mode:             profile.Mode,
 		RoleConfigPath:   roleConfigPath,
-		Budget:           profile.Budget,
 		VerboseArtifacts: verboseArtifacts,
 	}
 	if ctx.DryRun {
@@ -917,7 +901,6 @@ func (h *LoopHandler) runLoopLabCommand(args []string, ctx harness.Context) (har
 			Mode:             opts.Mode,
 			RoleConfigPath:   roleConfigPath,
 			Seed:             opts.Seed,
-			Budget:           opts.Budget,
 			VerboseArtifacts: verboseArtifacts,
 		}
 		if action == "autofix" {